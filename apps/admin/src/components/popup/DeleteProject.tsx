@@ -28,7 +28,7 @@ export default function DeleteProject({ isOpen, onClose, onConfirm, isDeleting,
           </div>
           
           <p className="text-sm text-gray-600 mb-6 ml-14">
-            This action cannot be undone. The project and all its associated images will be permanently removed from your database and storage.
+            The project will be moved to the trash and hidden from the website. It can be restored until the trash is emptied, after which the project and all its images are permanently removed.
           </p>
 
           <div className="flex justify-end gap-3 bg-gray-50 -mx-6 -mb-6 p-4 border-t border-gray-100">
@@ -1,8 +1,7 @@
 import { useState, useMemo } from 'react';
 import { LayoutPanelTopIcon, ChevronDown, Plus, Trash2, Loader2, Image as ImageIcon } from 'lucide-react';
 import type { WebsiteSettings, ContentCard, Project } from '@garden/shared';
-import { resizeImage } from '../../../utils/imageResize';
-import { uploadImage } from '../../../services/storage';
+import { uploadWebsiteImage } from '../../../services/storage';
 
 interface BenefitsSettingsProps {
   settings: WebsiteSettings;
@@ -32,21 +31,15 @@ export function BenefitsSettings({ settings, expanded, onToggle, onChange, proje
       setUploadingIndex(index);
       try {
         const file = e.target.files[0];
-        const resizedBlob = await resizeImage(file, 512);
-        
-        const imageId = `benefit-${Date.now()}`;
-        const fileName = `${imageId}.webp`;
-        
-        const { url, path } = await uploadImage(resizedBlob, 'website/images/benefits', fileName);
+        const uploaded = await uploadWebsiteImage('benefits', file);
 
         const newCards = [...cards];
         newCards[index] = {
           ...newCards[index],
           image: {
-            ...newCards[index].image,
-            id: imageId,
-            url,
-            storagePath: path,
+            ...uploaded,
+            caption: newCards[index].image?.caption,
+            alt: newCards[index].image?.alt,
           }
         };
         onChange('benefits', 'cards', newCards);
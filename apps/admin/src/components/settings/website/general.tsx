@@ -1,8 +1,7 @@
 import { useState } from 'react';
 import { Globe, ChevronDown, Loader2, Image as ImageIcon } from 'lucide-react';
 import type { WebsiteSettings, WebsiteImage } from '@garden/shared';
-import { resizeImage } from '../../../utils/imageResize';
-import { uploadImage } from '../../../services/storage';
+import { uploadWebsiteImage } from '../../../services/storage';
 
 interface GeneralSettingsProps {
     settings: WebsiteSettings;
@@ -19,17 +18,12 @@ export function GeneralSettings({ settings, expanded, onToggle, onChange }: Gene
             setIsUploading(true);
             try {
                 const file = e.target.files[0];
-                const resizedBlob = await resizeImage(file, 512);
-                const { url, path } = await uploadImage(resizedBlob, 'website/images', 'WebsiteLogo.webp');
+                const uploaded = await uploadWebsiteImage('logo', file);
 
                 const newLogo: WebsiteImage = {
-                    id: 'website-logo',
-                    url,
-                    storagePath: path,
+                    ...uploaded,
                     caption: settings.logo?.caption || '',
                     alt: settings.logo?.alt || settings.title || 'Website Logo',
-                    width: 512,
-                    height: 512
                 };
 
                 onChange('logo', newLogo);
@@ -24,6 +24,8 @@ export function SeoSettings({ settings, expanded, onToggle, onChange }: SeoSetti
     onChange('seo', current.filter((_, i) => i !== index));
   };
 
+  const robots = settings.robots || { noIndex: false, disallow: [] };
+
   return (
     <div className="bg-white rounded-lg shadow-sm border border-gray-200 overflow-hidden">
       <button 
@@ -84,6 +86,27 @@ export function SeoSettings({ settings, expanded, onToggle, onChange }: SeoSetti
               )}
             </div>
           </div>
+          <div>
+            <label className="block text-sm font-medium text-gray-700 mb-1">Search engines</label>
+            <label className="flex items-center gap-2 text-sm text-gray-700 mb-3">
+              <input
+                type="checkbox"
+                checked={robots.noIndex}
+                onChange={(e) => onChange('robots', { ...robots, noIndex: e.target.checked })}
+                className="h-4 w-4 text-teal-600 border-gray-300 rounded focus:ring-teal-500"
+              />
+              Hide the website from search engines
+            </label>
+            <textarea
+              rows={3}
+              value={(robots.disallow || []).join('\n')}
+              disabled={robots.noIndex}
+              onChange={(e) => onChange('robots', { ...robots, disallow: e.target.value.split('\n') })}
+              className="w-full px-3 py-2 border border-gray-300 rounded-md focus:ring-teal-500 focus:border-teal-500 disabled:bg-gray-50"
+              placeholder="Paths search engines should skip, one per line, e.g. /projects"
+            />
+            <p className="text-xs text-gray-500 mt-1">Written to robots.txt with the sitemap when the website is published.</p>
+          </div>
         </div>
       </div>
     </div>
@@ -1,7 +1,7 @@
 import { AlertCircle, CheckCircle, ArrowRight, Globe } from 'lucide-react';
 import { useQuery } from '@tanstack/react-query';
 import { api } from '../services/api';
-import type { WebsiteSettings } from '@garden/shared';
+import type { PublishStatus, WebsiteSettings } from '@garden/shared';
 import { Link } from 'react-router-dom';
 import { AnalyticsOverview } from '../components/dashboard/AnalyticsOverview';
 import { RecentComments } from '../components/dashboard/RecentComments';
@@ -16,25 +16,18 @@ export default function DashboardPage() {
     },
   });
 
-  const getDisplayDate = (val: unknown): Date | null => {
-    if (!val) return null;
-    if (typeof val === 'string') return new Date(val);
-    if (typeof val === 'object' && val !== null && 'seconds' in val) {
-      return new Date((val as { seconds: number }).seconds * 1000);
-    }
-    return null;
-  };
-
-  const publishedAt = getDisplayDate(settings?.publishedAt);
-  const projectUpdatedAt = getDisplayDate(settings?.projectUpdatedAt);
-  const settingsUpdatedAt = getDisplayDate(settings?.updatedAt);
+  // Compares the live release with what a publish would write now
+  const { data: publishStatus } = useQuery({
+    queryKey: ['settings', 'website', 'status'],
+    queryFn: async () => {
+      const data = await api.get('/admin/settings/website/status');
+      return data as PublishStatus;
+    },
+  });
 
-  const needsPublish = 
-    (!publishedAt && (!!projectUpdatedAt || !!settingsUpdatedAt)) ||
-    (!!publishedAt && (
-      (projectUpdatedAt ? projectUpdatedAt > publishedAt : false) || 
-      (settingsUpdatedAt ? settingsUpdatedAt > publishedAt : false)
-    ));
+  const publishedAt = publishStatus?.publishedAt ? new Date(publishStatus.publishedAt) : null;
+  const needsPublish = !!publishStatus?.stale;
+  const changeCount = publishStatus?.changes ?? 0;
 
   return (
     <div className="max-w-6xl mx-auto">
@@ -65,8 +58,8 @@ export default function DashboardPage() {
                 </h3>
                 <p className="text-sm text-gray-600 mt-1">
                   {needsPublish 
-                    ? 'Changes have been made to the website settings or active projects since the last publish.' 
-                    : `Last published on ${publishedAt?.toLocaleDateString()}`
+                    ? `${changeCount} unpublished ${changeCount === 1 ? 'change' : 'changes'} to the website settings or active projects since the last publish.` 
+                    : publishedAt ? `Last published on ${publishedAt.toLocaleDateString()}` : 'Not published yet'
                   }
                 </p>
               </div>
@@ -4,10 +4,9 @@ import { Upload, X, Loader2, Plus, ArrowLeft, Save, Star } from 'lucide-react';
 import { doc, collection } from "firebase/firestore";
 import { db } from '../../services/firebase';
 import { api } from '../../services/api';
-import { resizeImage } from '../../utils/imageResize';
-import { uploadImage } from '../../services/storage';
+import { uploadProjectImage } from '../../services/storage';
 import { useQueryClient, useQuery } from '@tanstack/react-query';
-import type { ProjectCategory, ProjectImage, ProjectSettings } from '@garden/shared';
+import type { ProjectCategory, ProjectImage, ProjectSettings, ProjectStatus } from '@garden/shared';
 import AddCategory from '../../components/popup/AddCategory';
 import AddTag from '../../components/popup/AddTag';
 
@@ -22,7 +21,7 @@ export default function ProjectCreate() {
   const [description, setDescription] = useState('');
   const [category, setCategory] = useState<ProjectCategory>('');
   const [location, setLocation] = useState('');
-  const [status, setStatus] = useState<'active' | 'inactive'>('inactive');
+  const [status, setStatus] = useState<ProjectStatus>('draft');
   const [tags, setTags] = useState<string[]>([]);
   const [selectedImages, setSelectedImages] = useState<File[]>([]);
   const [previews, setPreviews] = useState<string[]>([]);
@@ -80,7 +79,6 @@ export default function ProjectCreate() {
       const newProjectId = newDocRef.id;
 
       const projectImages: ProjectImage[] = [];
-      const uploadPath = `project-images/${newProjectId}`;
       
       let finalCoverImage = '';
 
@@ -88,20 +86,14 @@ export default function ProjectCreate() {
         const file = selectedImages[i];
         const previewUrl = previews[i];
 
-        const resizedBlob = await resizeImage(file, 1200);
-        const { url, path } = await uploadImage(resizedBlob, uploadPath);
+        // The API resizes, strips metadata and generates the renditions
+        const uploaded = await uploadProjectImage(newProjectId, file);
         
         if (coverImage === previewUrl) {
-          finalCoverImage = url;
+          finalCoverImage = uploaded.url;
         }
 
-        projectImages.push({
-          id: `${Date.now()}-${Math.random().toString(36).substr(2, 9)}`,
-          url: url,
-          storagePath: path, // Save the path
-          caption: '',
-          alt: '',
-        });
+        projectImages.push(uploaded);
       }
 
       // Fallback: If no cover image is explicitly set (or was deleted), use the first one
@@ -189,7 +181,7 @@ export default function ProjectCreate() {
             </label>
             <button
               type="button"
-              onClick={() => setStatus(status === 'inactive' ? 'active' : 'inactive')}
+              onClick={() => setStatus(status === 'active' ? 'draft' : 'active')}
               className={`${status === 'active' ? 'bg-teal-600' : 'bg-gray-200'} relative inline-flex h-6 w-11 flex-shrink-0 cursor-pointer rounded-full border-2 border-transparent transition-colors duration-200 ease-in-out focus:outline-none focus:ring-2 focus:ring-teal-500 focus:ring-offset-2`}
               role="switch"
               aria-checked={status === 'active'}>
@@ -2,11 +2,10 @@ import { useState, useEffect, useRef, useMemo } from 'react';
 import { useNavigate, useParams } from 'react-router-dom';
 import { Loader2, Save, ArrowLeft, Trash2, Eye, Plus, ChevronDown } from 'lucide-react';
 import { api } from '../../services/api';
-import { resizeImage } from '../../utils/imageResize'; 
-import { uploadImage } from '../../services/storage';
+import { uploadProjectImage } from '../../services/storage';
 import { useQuery, useQueryClient } from '@tanstack/react-query'; // Removed LocalImageGroup import
 import ProjectImages from './ProjectImage'; // Corrected component name
-import type { Project, ProjectCategory, ProjectImage, ProjectSettings, ImageGroup } from '@garden/shared'; // Added ImageGroup
+import type { Project, ProjectCategory, ProjectImage, ProjectSettings, ImageGroup, ProjectStatus } from '@garden/shared'; // Added ImageGroup
 import DeleteProject from '../../components/popup/DeleteProject';
 import AddCategory from '../../components/popup/AddCategory';
 import AddTag from '../../components/popup/AddTag';
@@ -18,6 +17,7 @@ export default function ProjectEdit() {
   const navigate = useNavigate();
   
   const dataLoaded = useRef(false);
+  const version = useRef<number | undefined>(undefined); // Sent as If-Match so concurrent edits are detected
   const [isSaving, setIsSaving] = useState(false);
   const [error, setError] = useState('');
   const [initialData, setInitialData] = useState<Project | null>(null);
@@ -43,7 +43,7 @@ export default function ProjectEdit() {
   const [description, setDescription] = useState('');
   const [category, setCategory] = useState<ProjectCategory>('');
   const [location, setLocation] = useState('');
-  const [status, setStatus] = useState<'active' | 'inactive'>('inactive');
+  const [status, setStatus] = useState<ProjectStatus>('draft');
   const [coverImage, setCoverImage] = useState(''); // New state for cover image
   
   const [hasTestimonial, setHasTestimonial] = useState(false); // State for testimonial
@@ -62,8 +62,8 @@ export default function ProjectEdit() {
   const { data: projects, isLoading: isFetching } = useQuery({
     queryKey: ['projects'],
     queryFn: async () => {
-      const data = await api.get('/projects');
-      return (data || []) as Project[];
+      const data = await api.get('/admin/projects?limit=200');
+      return (data?.items || []) as Project[];
     },
   });
 
@@ -89,9 +89,10 @@ export default function ProjectEdit() {
       setCategory(project.category);
       setLocation(project.location);
       setExistingImages(project.images || []);
-      setStatus(project.status || 'inactive');
+      setStatus(project.status || 'draft');
       setCoverImage(project.coverImage || '');
       setTags(project.tags || []);
+      version.current = project.version;
       setHasTestimonial(project.hasTestimonial || false);
       // Ensure image group orders are correctly initialized, especially for non-featured groups
       setImageGroups(project.imageGroups ? project.imageGroups.map(group => {
@@ -118,7 +119,7 @@ export default function ProjectEdit() {
     if (description !== initialData.description) return true;
     if (category !== initialData.category) return true;
     if (location !== initialData.location) return true;
-    if (status !== (initialData.status || 'inactive')) return true;
+    if (status !== (initialData.status || 'draft')) return true;
     if (coverImage !== (initialData.coverImage || '')) return true;
     if (hasTestimonial !== (initialData.hasTestimonial || false)) return true;
     if (testimonialName !== (initialData.testimonial?.name || '')) return true;
@@ -264,7 +265,6 @@ export default function ProjectEdit() {
 
     try {
       const newUploadedImages: ProjectImage[] = [];
-      const uploadPath = `project-images/${id}`;
       
       let finalCoverImage = coverImage;
 
@@ -273,21 +273,15 @@ export default function ProjectEdit() {
         const file = newFiles[i];
         const previewUrl = newPreviews[i]; // This is the blob URL currently in state if selected
 
-        const resizedBlob = await resizeImage(file, 1200);
-        const { url, path } = await uploadImage(resizedBlob, uploadPath);
+        // The API resizes, strips metadata and generates the renditions
+        const uploaded = await uploadProjectImage(id!, file);
         
         // Check if this specific new file was selected as the cover image
         if (coverImage === previewUrl) {
-          finalCoverImage = url; // Update to the real remote URL
+          finalCoverImage = uploaded.url; // Update to the real remote URL
         }
 
-        newUploadedImages.push({
-          id: `${Date.now()}-${Math.random().toString(36).substr(2, 9)}`,
-          url: url,
-          storagePath: path,
-          caption: '',
-          alt: '',
-        });
+        newUploadedImages.push(uploaded);
       }
 
       // If no cover image is selected, default to the first available image
@@ -330,7 +324,7 @@ export default function ProjectEdit() {
         imageGroups: imageGroupsForApi, // Use the mapped groups for the API
         hasTestimonial,
         testimonial: testimonialData,
-      });
+      }, version.current !== undefined ? { 'If-Match': `"${version.current}"` } : undefined);
 
       queryClient.invalidateQueries({ queryKey: ['projects'] });
       navigate('/projects');
@@ -460,7 +454,7 @@ export default function ProjectEdit() {
                   </label>
                   <button
                     type="button"
-                    onClick={() => setStatus(status === 'inactive' ? 'active' : 'inactive')}
+                    onClick={() => setStatus(status === 'active' ? 'draft' : 'active')}
                     className={`${status === 'active' ? 'bg-teal-600' : 'bg-gray-200'} relative inline-flex h-6 w-11 flex-shrink-0 cursor-pointer rounded-full border-2 border-transparent transition-colors duration-200 ease-in-out focus:outline-none focus:ring-2 focus:ring-teal-500 focus:ring-offset-2`}
                     role="switch"
                     aria-checked={status === 'active'}>
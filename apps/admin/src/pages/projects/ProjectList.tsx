@@ -9,8 +9,8 @@ export default function ProjectList() {
   const { data: projects = [], isLoading, error } = useQuery({
     queryKey: ['projects'],
     queryFn: async () => {
-      const data = await api.get('/projects');
-      return (data || []) as Project[];
+      const data = await api.get('/admin/projects?limit=200');
+      return (data?.items || []) as Project[];
     },
   });
 
@@ -82,7 +82,7 @@ export default function ProjectList() {
                   project.status === 'active' ? 'bg-teal-500' : 'bg-gray-500'
                 }`}
                 >
-                  {project.status === 'active' ? 'Active' : 'Inactive'}
+                  {project.status.charAt(0).toUpperCase() + project.status.slice(1)}
                 </span>
               </div>
 
@@ -22,8 +22,8 @@ const ProjectPreview: React.FC = () => {
   const { data: projects, isLoading: isFetching, error } = useQuery({
     queryKey: ['projects'],
     queryFn: async () => {
-      const data = await api.get('/projects');
-      return (data || []) as Project[];
+      const data = await api.get('/admin/projects?limit=200');
+      return (data?.items || []) as Project[];
     },
   });
 
@@ -59,8 +59,8 @@ function WebsiteConfigForm({ initialData, onDirtyChange }: { initialData: Websit
   const { data: projects } = useQuery({
     queryKey: ['projects'],
     queryFn: async () => {
-      const data = await api.get('/projects');
-      return (data || []) as Project[];
+      const data = await api.get('/admin/projects?limit=200');
+      return (data?.items || []) as Project[];
     },
   });
 
@@ -6,6 +6,11 @@ interface FetchOptions extends RequestInit {
   token?: string;
 }
 
+// Last ETag seen per resource. Admin writes go to '/admin/<resource>' while reads
+// may use the public '/<resource>', so both map to the same key.
+const etags = new Map<string, string>();
+const etagKey = (endpoint: string) => endpoint.split('?')[0].replace(/^\/admin(?=\/)/, '');
+
 export const api = {
   // Helper to make authenticated requests
   request: async (endpoint: string, options: FetchOptions = {}) => {
@@ -26,13 +31,28 @@ export const api = {
       headers.set('Content-Type', 'application/json');
     }
 
+    // The API rejects saves without If-Match, send back the version we last read
+    const key = etagKey(endpoint);
+    if ((options.method === 'PUT' || options.method === 'PATCH') && !headers.has('If-Match') && etags.has(key)) {
+      headers.set('If-Match', etags.get(key)!);
+    }
+
     const response = await fetch(`${API_URL}${endpoint}`, {
       ...options,
       headers,
     });
 
+    const etag = response.headers.get('ETag');
+    if (etag) {
+      etags.set(key, etag);
+    }
+
     if (!response.ok) {
       const errorData = await response.json().catch(() => ({}));
+      // Validation failures list every invalid field instead of a single error
+      if (Array.isArray(errorData.errors)) {
+        throw new Error(errorData.errors.map((e: { message: string }) => e.message).join('\n'));
+      }
       throw new Error(errorData.error || `Request failed with status ${response.status}`);
     }
 
@@ -45,8 +65,20 @@ export const api = {
   post: (endpoint: string, data: unknown) => 
     api.request(endpoint, { method: 'POST', body: JSON.stringify(data) }),
 
-  put: (endpoint: string, data: unknown) => 
-    api.request(endpoint, { method: 'PUT', body: JSON.stringify(data) }),
+  put: (endpoint: string, data: unknown, headers?: HeadersInit) => 
+    api.request(endpoint, { method: 'PUT', body: JSON.stringify(data), headers }),
+
+  // Sends an RFC 7396 merge patch, only the fields present in data change
+  patch: (endpoint: string, data: unknown, headers?: HeadersInit) =>
+    api.request(endpoint, {
+      method: 'PATCH',
+      body: JSON.stringify(data),
+      headers: { 'Content-Type': 'application/merge-patch+json', ...headers },
+    }),
+
+  // Sends a multipart form, the browser sets the boundary in Content-Type
+  upload: (endpoint: string, data: FormData) =>
+    api.request(endpoint, { method: 'POST', body: data }),
     
   delete: (endpoint: string) => api.request(endpoint, { method: 'DELETE' }),
 };
\ No newline at end of file
@@ -0,0 +1,39 @@
+import { api } from './api';
+import type { ListResponse, MediaAsset, MediaDetailsInput, MediaUsage } from '@garden/shared';
+
+export interface MediaQuery {
+  q?: string;
+  tag?: string;
+  cursor?: string;
+  limit?: number;
+}
+
+// Lists the media library, newest first
+export const listMedia = async (query: MediaQuery = {}): Promise<ListResponse<MediaAsset>> => {
+  const params = new URLSearchParams();
+  Object.entries(query).forEach(([key, value]) => {
+    if (value !== undefined && value !== '') params.set(key, String(value));
+  });
+  const search = params.toString();
+  return api.get(`/admin/media${search ? `?${search}` : ''}`);
+};
+
+// Adds an image to the library without using it anywhere yet
+export const uploadMedia = async (file: File, details: Partial<MediaDetailsInput> = {}): Promise<MediaAsset> => {
+  const form = new FormData();
+  form.append('file', file);
+  if (details.caption) form.append('caption', details.caption);
+  if (details.alt) form.append('alt', details.alt);
+  if (details.tags?.length) form.append('tags', details.tags.join(','));
+  return api.upload('/admin/media', form);
+};
+
+export const getMedia = async (hash: string): Promise<MediaAsset> => api.get(`/admin/media/${hash}`);
+
+export const updateMedia = async (hash: string, details: MediaDetailsInput): Promise<MediaAsset> =>
+  api.put(`/admin/media/${hash}`, details);
+
+// Fails with 409 while a project or website section still uses the image
+export const deleteMedia = async (hash: string): Promise<void> => api.delete(`/admin/media/${hash}`);
+
+export const getMediaUsage = async (hash: string): Promise<MediaUsage> => api.get(`/admin/media/${hash}/usage`);
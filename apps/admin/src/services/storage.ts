@@ -1,17 +1,17 @@
-import { ref, uploadBytes, getDownloadURL } from 'firebase/storage';
-import { storage } from './firebase';
+import { api } from './api';
+import type { ProjectImage, WebsiteImage } from '@garden/shared';
 
-// Update return type to include path
-export const uploadImage = async (file: Blob, folder: string = 'uploads', customName?: string): Promise<{ url: string; path: string }> => {
-  const filename = customName || `${Date.now()}-${Math.random().toString(36).substring(2, 9)}.webp`;
-  // Construct the full path (e.g. project-images/123/abc.webp)
-  const fullPath = `${folder}/${filename}`;
-  
-  const storageRef = ref(storage, fullPath);
+// Uploads a project image through the API, which returns it fully processed
+export const uploadProjectImage = async (projectId: string, file: File): Promise<ProjectImage> => {
+  const form = new FormData();
+  form.append('file', file);
+  return api.upload(`/admin/projects/${projectId}/images`, form);
+};
 
-  await uploadBytes(storageRef, file);
-  const url = await getDownloadURL(storageRef);
-  
-  // Return both
-  return { url, path: fullPath };
-};
\ No newline at end of file
+// Uploads a website content image (logo, benefits, services, ...) through the API
+export const uploadWebsiteImage = async (section: string, file: File): Promise<WebsiteImage> => {
+  const form = new FormData();
+  form.append('file', file);
+  form.append('section', section);
+  return api.upload('/admin/settings/website/images', form);
+};
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/backfill"
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/config"
+	"github.com/networkcaretaker/garden_app/backend/internal/db"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Computes the blurhash, LQIP and dominant colour of images uploaded before
+// the upload pipeline did, using the same configuration as the server:
+//
+//	go run ./cmd/backfill-placeholders -dry-run
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without saving")
+	flag.Parse()
+
+	// 1. Load Configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.DataBackend == "memory" {
+		log.Fatal("The in-memory data store starts empty, there is nothing to backfill")
+	}
+
+	// 2. Initialize Database
+	ctx := context.Background()
+	services, err := db.NewClient(ctx, cfg.FirebaseCredentialsFile, cfg.FirebaseProjectID)
+	if err != nil {
+		log.Fatalf("Failed to connect to Firebase: %v", err)
+	}
+	defer services.Close()
+
+	// 3. Initialize Blob Storage
+	var blobStore blob.Store
+	if cfg.StorageBackend == "local" {
+		blobStore, err = blob.NewLocalStore(cfg.LocalStorageDir, cfg.PublicBaseURL)
+		if err != nil {
+			log.Fatalf("Failed to initialize local storage: %v", err)
+		}
+	} else {
+		bucket, err := services.Storage.Bucket(cfg.FirebaseStorageBucket)
+		if err != nil {
+			log.Fatalf("Failed to get storage bucket: %v", err)
+		}
+		blobStore = blob.NewGCSStore(bucket, cfg.FirebaseStorageBucket)
+	}
+
+	// 4. Run the backfill and print the report
+	b := backfill.NewPlaceholders(
+		repository.NewFirestoreProjectRepository(services.Firestore),
+		repository.NewFirestoreSettingsRepository(services.Firestore),
+		blobStore,
+	)
+	report, err := b.Run(ctx, *dryRun)
+	if report != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	}
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+}
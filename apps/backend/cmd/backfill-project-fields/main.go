@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/config"
+	"github.com/networkcaretaker/garden_app/backend/internal/db"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Adds the fields project listings filter and sort on (title, featured,
+// completedDate, createdAt, updatedAt) to projects saved without them, which
+// Firestore would otherwise leave out of listings, and rewrites legacy
+// statuses such as "Active" or "inactive" as workflow statuses:
+//
+//	go run ./cmd/backfill-project-fields -dry-run
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without saving")
+	flag.Parse()
+
+	// 1. Load Configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.DataBackend == "memory" {
+		log.Fatal("The in-memory data store starts empty, there is nothing to backfill")
+	}
+
+	// 2. Initialize Database
+	ctx := context.Background()
+	services, err := db.NewClient(ctx, cfg.FirebaseCredentialsFile, cfg.FirebaseProjectID)
+	if err != nil {
+		log.Fatalf("Failed to connect to Firebase: %v", err)
+	}
+	defer services.Close()
+
+	// 3. Run the backfill
+	updated, err := repository.NewFirestoreProjectRepository(services.Firestore).BackfillListFields(ctx, *dryRun)
+	if err != nil {
+		log.Fatalf("Backfill failed after %d projects: %v", updated, err)
+	}
+	if *dryRun {
+		log.Printf("%d projects are missing list fields or have a legacy status", updated)
+	} else {
+		log.Printf("Backfilled list fields and statuses of %d projects", updated)
+	}
+}
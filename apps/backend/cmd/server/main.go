@@ -4,14 +4,23 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
 	"github.com/networkcaretaker/garden_app/backend/internal/config"
 	"github.com/networkcaretaker/garden_app/backend/internal/db"
+	"github.com/networkcaretaker/garden_app/backend/internal/gc"
 	"github.com/networkcaretaker/garden_app/backend/internal/handlers"
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/media"
 	customMiddleware "github.com/networkcaretaker/garden_app/backend/internal/middleware"
+	"github.com/networkcaretaker/garden_app/backend/internal/publish"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+	"github.com/networkcaretaker/garden_app/backend/internal/search"
+	"github.com/networkcaretaker/garden_app/backend/internal/workflow"
 )
 
 func main() {
@@ -22,26 +31,145 @@ func main() {
 	}
 
 	// 2. Initialize Database & Auth
-	ctx := context.Background()
-	services, err := db.NewClient(ctx, cfg.FirebaseCredentialsFile, cfg.FirebaseProjectID)
-	if err != nil {
-		log.Fatalf("Failed to connect to Firebase: %v", err)
+	var services *db.Client
+	var projectRepo repository.ProjectRepository
+	var revisionRepo repository.RevisionRepository
+	var jobRepo repository.JobRepository
+	var mediaRepo repository.MediaRepository
+	var releaseRepo repository.ReleaseRepository
+	var settingsRepo repository.SettingsRepository
+	var tokenVerifier customMiddleware.TokenVerifier
+
+	if cfg.DataBackend == "memory" {
+		projectRepo = repository.NewMemoryProjectRepository()
+		revisionRepo = repository.NewMemoryRevisionRepository()
+		jobRepo = repository.NewMemoryJobRepository()
+		mediaRepo = repository.NewMemoryMediaRepository()
+		releaseRepo = repository.NewMemoryReleaseRepository()
+		settingsRepo = repository.NewMemorySettingsRepository()
+		log.Println("⚠️  Using in-memory data store, data is lost on restart")
+	} else {
+		ctx := context.Background()
+		var err error
+		services, err = db.NewClient(ctx, cfg.FirebaseCredentialsFile, cfg.FirebaseProjectID)
+		if err != nil {
+			log.Fatalf("Failed to connect to Firebase: %v", err)
+		}
+		defer services.Close()
+		log.Println("✅ Connected to Firestore & Auth successfully")
+
+		projectRepo = repository.NewFirestoreProjectRepository(services.Firestore)
+		revisionRepo = repository.NewFirestoreRevisionRepository(services.Firestore)
+		jobRepo = repository.NewFirestoreJobRepository(services.Firestore)
+		mediaRepo = repository.NewFirestoreMediaRepository(services.Firestore)
+		releaseRepo = repository.NewFirestoreReleaseRepository(services.Firestore)
+		settingsRepo = repository.NewFirestoreSettingsRepository(services.Firestore)
+		tokenVerifier = services.Auth
+	}
+
+	// DEV_AUTH (development only) accepts any bearer token; otherwise the
+	// in-memory backend still verifies sign-ins with Firebase Auth
+	if cfg.DevAuth {
+		tokenVerifier = customMiddleware.DevTokenVerifier{}
+		log.Println("⚠️  DEV_AUTH is set, any bearer token is accepted")
+	} else if services == nil {
+		authClient, err := db.NewAuthClient(context.Background(), cfg.FirebaseCredentialsFile, cfg.FirebaseProjectID)
+		if err != nil {
+			log.Fatalf("Failed to connect to Firebase Auth: %v", err)
+		}
+		tokenVerifier = authClient
+	}
+
+	// 3. Initialize Blob Storage
+	var blobStore blob.Store
+	var localStore *blob.LocalStore
+	if cfg.StorageBackend == "local" {
+		var err error
+		localStore, err = blob.NewLocalStore(cfg.LocalStorageDir, cfg.PublicBaseURL)
+		if err != nil {
+			log.Fatalf("Failed to initialize local storage: %v", err)
+		}
+		blobStore = localStore
+		log.Printf("Using local blob storage in %s", cfg.LocalStorageDir)
+	} else {
+		bucket, err := services.Storage.Bucket(cfg.FirebaseStorageBucket)
+		if err != nil {
+			log.Fatalf("Failed to get storage bucket: %v", err)
+		}
+		blobStore = blob.NewGCSStore(bucket, cfg.FirebaseStorageBucket)
+	}
+
+	// 4. Start the background job queue, storage cleanup and scheduled
+	// publishing go through it
+	jobQueue := jobs.NewQueue(jobRepo)
+	jobQueue.Register(jobs.TypeDeleteBlob, jobs.DeleteBlob(blobStore))
+	publisher := publish.NewPublisher(projectRepo, revisionRepo, settingsRepo, releaseRepo, blobStore)
+	scheduler := publish.NewScheduler(publisher, jobQueue, cfg.AutoPublishDelay)
+	go jobQueue.Start(context.Background(), cfg.JobPollInterval)
+
+	// 5. Initialize Handlers
+	searchIndex := search.NewIndex()
+	mediaLibrary := media.NewLibrary(mediaRepo, blobStore, jobQueue)
+	projectHandler := handlers.NewProjectHandler(projectRepo, revisionRepo, settingsRepo, blobStore, searchIndex, mediaLibrary, scheduler, cfg)
+	jobHandler := handlers.NewJobHandler(jobQueue)
+	collector := gc.NewCollector(projectRepo, settingsRepo, mediaRepo, blobStore, jobQueue, cfg.StorageGCGracePeriod)
+	storageHandler := handlers.NewStorageHandler(collector)
+	mediaHandler := handlers.NewMediaHandler(mediaLibrary, projectRepo, settingsRepo)
+	settingsHandler := handlers.NewSettingsHandler(projectRepo, settingsRepo, blobStore, mediaLibrary, publisher, scheduler, cfg)
+	uploadHandler := handlers.NewUploadHandler(blobStore, mediaLibrary, cfg)
+
+	// Build the search index now and refresh it so writes made by other instances show up
+	if err := projectHandler.RebuildSearchIndex(context.Background()); err != nil {
+		log.Fatalf("Failed to build search index: %v", err)
+	}
+	if cfg.SearchReindexInterval > 0 {
+		go func() {
+			for range time.Tick(cfg.SearchReindexInterval) {
+				if err := projectHandler.RebuildSearchIndex(context.Background()); err != nil {
+					log.Printf("Failed to rebuild search index: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Permanently remove projects that have been in the trash past the retention period
+	if cfg.TrashPurgeInterval > 0 {
+		go func() {
+			for range time.Tick(cfg.TrashPurgeInterval) {
+				purged, err := projectHandler.PurgeTrash(context.Background())
+				if err != nil {
+					log.Printf("Failed to purge trash: %v", err)
+				} else if purged > 0 {
+					log.Printf("Purged %d projects from the trash", purged)
+				}
+			}
+		}()
 	}
-	defer services.Close()
-	log.Println("✅ Connected to Firestore & Auth successfully")
 
-	// 3. Initialize Handlers
-	projectHandler := handlers.NewProjectHandler(services, cfg)
-	settingsHandler := handlers.NewSettingsHandler(services, cfg)
-	// UploadHandler removed - logic moved to client-side PWA
+	// Delete images nothing references any more
+	if cfg.StorageGCInterval > 0 {
+		go func() {
+			for range time.Tick(cfg.StorageGCInterval) {
+				report, err := collector.Run(context.Background(), false)
+				if err != nil {
+					log.Printf("Storage garbage collection failed: %v", err)
+				} else if report.Queued > 0 {
+					log.Printf("Queued %d orphaned images (%d bytes) for deletion", report.Queued, report.OrphanBytes)
+				}
+			}
+		}()
+	}
 
-	// 4. Initialize Echo
+	// 6. Initialize Echo
 	e := echo.New()
 
 	// Global Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	// Expose ETag so the admin PWA can send it back in If-Match when saving
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		ExposeHeaders: []string{"ETag"},
+	}))
 
 	// --- Public Routes ---
 	e.GET("/", func(c echo.Context) error {
@@ -52,31 +180,80 @@ func main() {
 		return c.JSON(http.StatusOK, map[string]string{"status": "healthy"})
 	})
 
+	// Serve locally stored images and published files
+	if localStore != nil {
+		e.GET(localStore.Route(), handlers.NewBlobHandler(localStore).ServeBlob)
+	}
+
 	// Public Project Routes (Read-only)
 	e.GET("/projects", projectHandler.GetProjects)
+	e.GET("/projects/search", projectHandler.SearchProjects)
+	e.GET("/projects/:id", projectHandler.GetProject)
+	e.GET("/projects/by-slug/:slug", projectHandler.GetProjectBySlug)
 	// Public Settings Routes (Read-only)
 	e.GET("/settings/website", settingsHandler.GetWebsiteSettings)
 	e.GET("/settings/projects", settingsHandler.GetProjectSettings)
 
 	// --- Protected Routes (Admin Only) ---
 	adminGroup := e.Group("/admin")
-	adminGroup.Use(customMiddleware.AuthMiddleware(services.Auth))
+	adminGroup.Use(customMiddleware.AuthMiddleware(tokenVerifier))
 
-	// Admin Project Routes (Write)
+	// Admin Project Routes
+	adminGroup.GET("/projects", projectHandler.GetAdminProjects)
+	adminGroup.GET("/projects/search", projectHandler.SearchAdminProjects)
+	adminGroup.GET("/projects/trash", projectHandler.GetTrashedProjects)
+	adminGroup.GET("/projects/:id", projectHandler.GetAdminProject)
+	adminGroup.GET("/projects/by-slug/:slug", projectHandler.GetAdminProjectBySlug)
 	adminGroup.POST("/projects", projectHandler.CreateProject)
 	adminGroup.PUT("/projects/:id", projectHandler.UpdateProject)
+	adminGroup.PATCH("/projects/:id", projectHandler.PatchProject)
+	adminGroup.POST("/projects/:id/images", uploadHandler.UploadProjectImage)
+	adminGroup.GET("/projects/:id/images/download", projectHandler.DownloadProjectImages)
+	adminGroup.POST("/projects/:id/restore", projectHandler.RestoreProject)
+	adminGroup.GET("/projects/:id/revisions", projectHandler.ListRevisions)
+	adminGroup.GET("/projects/:id/revisions/:rev", projectHandler.GetRevision)
+	adminGroup.GET("/projects/:id/revisions/:rev/diff", projectHandler.DiffRevision)
+	adminGroup.POST("/projects/:id/revisions/:rev/restore", projectHandler.RestoreRevision)
+	for _, t := range workflow.Transitions {
+		adminGroup.POST("/projects/:id/"+t.Action, projectHandler.TransitionProject(t.Action))
+	}
 	adminGroup.DELETE("/projects/:id", projectHandler.DeleteProject)
-	
+
+	// Admin Job Queue Routes
+	adminGroup.GET("/jobs", jobHandler.GetJobs)
+	adminGroup.POST("/jobs/:id/retry", jobHandler.RetryJob)
+
+	// Admin Media Library Routes
+	adminGroup.GET("/media", mediaHandler.ListMedia)
+	adminGroup.POST("/media", uploadHandler.UploadMedia)
+	adminGroup.GET("/media/:hash", mediaHandler.GetMedia)
+	adminGroup.PUT("/media/:hash", mediaHandler.UpdateMedia)
+	adminGroup.DELETE("/media/:hash", mediaHandler.DeleteMedia)
+	adminGroup.GET("/media/:hash/usage", mediaHandler.GetMediaUsage)
+
+	// Admin Storage Maintenance Routes
+	adminGroup.GET("/storage/orphans", storageHandler.GetOrphanReport)
+	adminGroup.POST("/storage/orphans/collect", storageHandler.CollectOrphans)
+
 	// Admin Settings Routes (Write)
 	adminGroup.PUT("/settings/website", settingsHandler.UpdateWebsiteSettings)
 	adminGroup.POST("/settings/website/publish", settingsHandler.PublishWebsiteData)
+	adminGroup.GET("/settings/website/status", settingsHandler.GetPublishStatus)
+	adminGroup.GET("/settings/website/releases", settingsHandler.ListReleases)
+	adminGroup.GET("/settings/website/schedule", settingsHandler.ListScheduledPublishes)
+	adminGroup.POST("/settings/website/schedule", settingsHandler.SchedulePublish)
+	adminGroup.DELETE("/settings/website/schedule/:id", settingsHandler.CancelScheduledPublish)
+	adminGroup.POST("/settings/website/rollback/:releaseId", settingsHandler.RollbackWebsite)
+	adminGroup.POST("/settings/website/images", uploadHandler.UploadWebsiteImage)
 	adminGroup.PUT("/settings/projects", settingsHandler.UpdateProjectSettings)
 
 	adminGroup.GET("/me", func(c echo.Context) error {
 		uid := c.Get("uid").(string)
+		role := c.Get("role").(string)
 		return c.JSON(http.StatusOK, map[string]string{
 			"message": "You are authenticated!",
 			"uid":     uid,
+			"role":    role,
 		})
 	})
 
@@ -0,0 +1,223 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/imaging"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// maxConflictRetries bounds how often a project is re-read when an editor
+// saves it while its placeholders are being computed
+const maxConflictRetries = 3
+
+// Report summarises a backfill run
+type Report struct {
+	DryRun          bool     `json:"dryRun"`
+	ProjectsUpdated int      `json:"projectsUpdated"`
+	ImagesUpdated   int      `json:"imagesUpdated"` // Project images and website images
+	Skipped         int      `json:"skipped"`       // Images that already had placeholders
+	Failed          []string `json:"failed"`        // Images that could not be read, with the reason
+}
+
+// Placeholders computes the blurhash, LQIP and dominant colour of images
+// uploaded before the upload pipeline computed them
+type Placeholders struct {
+	Projects repository.ProjectRepository
+	Settings repository.SettingsRepository
+	Blobs    blob.Store
+}
+
+// NewPlaceholders creates a placeholder backfill
+func NewPlaceholders(projects repository.ProjectRepository, settings repository.SettingsRepository, blobs blob.Store) *Placeholders {
+	return &Placeholders{Projects: projects, Settings: settings, Blobs: blobs}
+}
+
+// Run fills in the placeholders of every project image (trashed projects
+// included) and every image in the website settings. Unless dryRun is set
+// the results are saved. Images that cannot be read are reported and skipped.
+func (b *Placeholders) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	report := &Report{DryRun: dryRun, Failed: []string{}}
+
+	// 1. Project images
+	for _, trashed := range []bool{false, true} {
+		projects, _, err := b.Projects.List(ctx, repository.ListOptions{Trashed: trashed})
+		if err != nil {
+			return report, err
+		}
+		for _, p := range projects {
+			if err := b.project(ctx, p, report); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	// 2. Website content images
+	if err := b.website(ctx, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// project computes the missing placeholders of one project and saves them,
+// re-reading the project if someone saved it in the meantime
+func (b *Placeholders) project(ctx context.Context, p models.Project, report *Report) error {
+	computed := make(map[string]imaging.Placeholder)
+	for _, img := range p.Images {
+		if img.BlurHash != "" {
+			report.Skipped++
+			continue
+		}
+		placeholder, err := b.analyze(ctx, imageSource(img.Renditions, img.StoragePath, img.URL))
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("project %s image %s: %v", p.ID, img.ID, err))
+			continue
+		}
+		computed[img.ID] = placeholder
+	}
+	if len(computed) == 0 {
+		return nil
+	}
+	report.ImagesUpdated += len(computed)
+	report.ProjectsUpdated++
+	if report.DryRun {
+		return nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		for i, img := range p.Images {
+			if placeholder, ok := computed[img.ID]; ok && img.BlurHash == "" {
+				p.Images[i].BlurHash = placeholder.BlurHash
+				p.Images[i].LQIP = placeholder.LQIP
+				p.Images[i].DominantColor = placeholder.DominantColor
+			}
+		}
+		err := b.Projects.Update(ctx, &p)
+		if !errors.Is(err, repository.ErrConflict) || attempt == maxConflictRetries {
+			return err
+		}
+		current, err := b.Projects.Get(ctx, p.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil // Purged meanwhile
+		}
+		if err != nil {
+			return err
+		}
+		p = *current
+	}
+}
+
+// website computes the missing placeholders of every image stored in the
+// website settings and saves the logo and content back
+func (b *Placeholders) website(ctx context.Context, report *Report) error {
+	settings, err := b.Settings.Get(ctx, repository.WebsiteDocument)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	updated := 0
+	walkImages(settings, func(img map[string]interface{}) {
+		if hash, _ := img["blurHash"].(string); hash != "" {
+			report.Skipped++
+			return
+		}
+		url, _ := img["url"].(string)
+		storagePath, _ := img["storagePath"].(string)
+		placeholder, err := b.analyze(ctx, imageSource(renditionsOf(img), storagePath, url))
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("website image %s: %v", url, err))
+			return
+		}
+		img["blurHash"] = placeholder.BlurHash
+		img["lqip"] = placeholder.LQIP
+		img["dominantColor"] = placeholder.DominantColor
+		updated++
+	})
+	report.ImagesUpdated += updated
+	if updated == 0 || report.DryRun {
+		return nil
+	}
+
+	data := map[string]interface{}{}
+	for _, field := range []string{"logo", "content"} {
+		if value, ok := settings[field]; ok {
+			data[field] = value
+		}
+	}
+	_, err = b.Settings.MergeVersioned(ctx, repository.WebsiteDocument, data, repository.SettingsVersion(settings))
+	if errors.Is(err, repository.ErrConflict) {
+		log.Printf("Website settings changed during the backfill, run it again to fill in the website images")
+		return nil
+	}
+	return err
+}
+
+// analyze downloads an image and computes its placeholders
+func (b *Placeholders) analyze(ctx context.Context, objectPath string) (imaging.Placeholder, error) {
+	if objectPath == "" {
+		return imaging.Placeholder{}, errors.New("no storage path")
+	}
+	r, err := b.Blobs.Get(ctx, objectPath)
+	if err != nil {
+		return imaging.Placeholder{}, err
+	}
+	defer r.Close()
+	return imaging.Analyze(r)
+}
+
+// imageSource picks the smallest stored copy of an image, the placeholders
+// only need a few pixels
+func imageSource(renditions []models.ImageRendition, storagePath, url string) string {
+	for _, r := range renditions {
+		if r.StoragePath != "" {
+			return r.StoragePath
+		}
+	}
+	if storagePath != "" {
+		return storagePath
+	}
+	return blob.PathFromURL(url)
+}
+
+// renditionsOf reads the renditions of an image stored as a generic map
+func renditionsOf(img map[string]interface{}) []models.ImageRendition {
+	list, _ := img["renditions"].([]interface{})
+	var renditions []models.ImageRendition
+	for _, item := range list {
+		r, _ := item.(map[string]interface{})
+		storagePath, _ := r["storagePath"].(string)
+		renditions = append(renditions, models.ImageRendition{StoragePath: storagePath})
+	}
+	return renditions
+}
+
+// walkImages calls fn for every image nested in the settings tree, any
+// map with a "url" next to a "storagePath"
+func walkImages(value interface{}, fn func(map[string]interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		_, hasURL := v["url"].(string)
+		_, hasPath := v["storagePath"].(string)
+		if hasURL && hasPath {
+			if url, _ := v["url"].(string); url != "" {
+				fn(v)
+			}
+			return
+		}
+		for _, item := range v {
+			walkImages(item, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkImages(item, fn)
+		}
+	}
+}
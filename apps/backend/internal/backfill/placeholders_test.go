@@ -0,0 +1,129 @@
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// newTestBackfill stores a few legacy images: two projects (one trashed)
+// with images lacking placeholders, one image that already has them, one
+// whose file is gone and a website hero image
+func newTestBackfill(t *testing.T) *Placeholders {
+	t.Helper()
+	ctx := context.Background()
+	store, err := blob.NewLocalStore(t.TempDir(), "http://localhost/storage")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 40, 140, 60, 255
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	for _, path := range []string{"project-images/p1/a_320.png", "project-images/p2/d.png", "website/images/hero.png"} {
+		if err := store.Put(ctx, path, bytes.NewReader(buf.Bytes()), "image/png"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	projects := repository.NewMemoryProjectRepository()
+	trashedAt := time.Now()
+	for _, p := range []models.Project{
+		{ID: "p1", Images: []models.ProjectImage{
+			{ID: "a", StoragePath: "project-images/p1/a.png", Renditions: []models.ImageRendition{{Width: 320, StoragePath: "project-images/p1/a_320.png"}}},
+			{ID: "b", StoragePath: "project-images/p1/b.png", BlurHash: "LKO2?U%2Tw=w]~RBVZRi};RPxuwH"},
+			{ID: "c", StoragePath: "project-images/p1/c.png"},
+		}},
+		{ID: "p2", DeletedAt: &trashedAt, Images: []models.ProjectImage{{ID: "d", URL: store.PublicURL("project-images/p2/d.png")}}},
+	} {
+		p := p
+		if err := projects.Create(ctx, &p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	settings := repository.NewMemorySettingsRepository()
+	if err := settings.Merge(ctx, repository.WebsiteDocument, map[string]interface{}{
+		"content": map[string]interface{}{
+			"hero": map[string]interface{}{"url": store.PublicURL("website/images/hero.png"), "storagePath": "website/images/hero.png"},
+		},
+	}); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	return NewPlaceholders(projects, settings, store)
+}
+
+func TestPlaceholdersRun(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		ctx := context.Background()
+		b := newTestBackfill(t)
+
+		report, err := b.Run(ctx, dryRun)
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if report.ProjectsUpdated != 2 || report.ImagesUpdated != 3 || report.Skipped != 1 || len(report.Failed) != 1 {
+			t.Errorf("dry run %v: %d projects and %d images updated, %d skipped, failed %v",
+				dryRun, report.ProjectsUpdated, report.ImagesUpdated, report.Skipped, report.Failed)
+		}
+
+		saved := map[string]string{}
+		for _, id := range []string{"p1", "p2"} {
+			p, err := b.Projects.Get(ctx, id)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			for _, img := range p.Images {
+				saved[img.ID] = img.DominantColor
+			}
+		}
+		settings, err := b.Settings.Get(ctx, repository.WebsiteDocument)
+		if err != nil {
+			t.Fatalf("Get settings: %v", err)
+		}
+		hero := settings["content"].(map[string]interface{})["hero"].(map[string]interface{})
+		saved["hero"], _ = hero["dominantColor"].(string)
+
+		want := map[string]string{"a": "#288c3c", "b": "", "c": "", "d": "#288c3c", "hero": "#288c3c"}
+		if dryRun {
+			want = map[string]string{"a": "", "b": "", "c": "", "d": "", "hero": ""}
+		}
+		for id, color := range want {
+			if saved[id] != color {
+				t.Errorf("dry run %v: image %s has dominant colour %q, want %q", dryRun, id, saved[id], color)
+			}
+		}
+	}
+}
+
+func TestImageSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		renditions  []models.ImageRendition
+		storagePath string
+		url         string
+		want        string
+	}{
+		{"smallest rendition", []models.ImageRendition{{StoragePath: "a_320.webp"}, {StoragePath: "a_640.webp"}}, "a.jpg", "", "a_320.webp"},
+		{"main file", nil, "a.jpg", "", "a.jpg"},
+		{"legacy URL", nil, "", "http://localhost/storage/blobs/project-images/a.jpg", "project-images/a.jpg"},
+		{"nothing stored", nil, "", "https://example.com/a.jpg", ""},
+	}
+	for _, tt := range tests {
+		if got := imageSource(tt.renditions, tt.storagePath, tt.url); got != tt.want {
+			t.Errorf("%s: imageSource = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist in the store
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Path        string    `json:"path"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"contentType"`
+	Updated     time.Time `json:"updated"`
+}
+
+// Store abstracts where uploaded images and published website files live
+type Store interface {
+	// Put writes (or overwrites) the object at path
+	Put(ctx context.Context, path string, r io.Reader, contentType string) error
+	// Get opens the object at path for reading, or returns ErrNotFound
+	Get(ctx context.Context, path string) (io.ReadCloser, error)
+	// Delete removes the object at path, or returns ErrNotFound
+	Delete(ctx context.Context, path string) error
+	// List returns every object whose path starts with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// Stat returns the size, content type and modification time of an object
+	Stat(ctx context.Context, path string) (*ObjectInfo, error)
+	// PublicURL returns the URL the object can be downloaded from
+	PublicURL(path string) string
+}
+
+// PathFromURL recovers the object path from a public URL produced by either
+// Firebase Storage (".../o/<escaped path>?alt=media") or the local store
+// ("<base>/blobs/<path>"). It returns "" when the URL is not recognised.
+func PathFromURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	if parts := strings.SplitN(parsedURL.EscapedPath(), "/o/", 2); len(parts) == 2 {
+		decodedPath, err := url.PathUnescape(parts[1])
+		if err != nil {
+			return ""
+		}
+		return decodedPath
+	}
+	if parts := strings.SplitN(parsedURL.Path, localRoute+"/", 2); len(parts) == 2 {
+		return parts[1]
+	}
+	return ""
+}
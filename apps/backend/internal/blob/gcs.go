@@ -0,0 +1,92 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GCSStore stores objects in a Google Cloud Storage (Firebase Storage) bucket
+type GCSStore struct {
+	bucket     *storage.BucketHandle
+	bucketName string
+}
+
+// NewGCSStore wraps an existing bucket handle
+func NewGCSStore(bucket *storage.BucketHandle, bucketName string) *GCSStore {
+	return &GCSStore{bucket: bucket, bucketName: bucketName}
+}
+
+func (s *GCSStore) Put(ctx context.Context, path string, r io.Reader, contentType string) error {
+	wc := s.bucket.Object(path).NewWriter(ctx)
+	wc.ContentType = contentType
+	if _, err := io.Copy(wc, r); err != nil {
+		wc.Close()
+		return err
+	}
+	return wc.Close()
+}
+
+func (s *GCSStore) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	rc, err := s.bucket.Object(path).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrNotFound
+	}
+	return rc, err
+}
+
+func (s *GCSStore) Delete(ctx context.Context, path string) error {
+	err := s.bucket.Object(path).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *GCSStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	iter := s.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+
+	var objects []ObjectInfo
+	for {
+		attrs, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, attrsToInfo(attrs))
+	}
+	return objects, nil
+}
+
+func (s *GCSStore) Stat(ctx context.Context, path string) (*ObjectInfo, error) {
+	attrs, err := s.bucket.Object(path).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	info := attrsToInfo(attrs)
+	return &info, nil
+}
+
+// PublicURL returns a Firebase Storage download URL, matching the URLs the admin PWA stores
+func (s *GCSStore) PublicURL(path string) string {
+	return fmt.Sprintf("https://firebasestorage.googleapis.com/v0/b/%s/o/%s?alt=media", s.bucketName, url.PathEscape(path))
+}
+
+func attrsToInfo(attrs *storage.ObjectAttrs) ObjectInfo {
+	return ObjectInfo{
+		Path:        attrs.Name,
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		Updated:     attrs.Updated,
+	}
+}
@@ -0,0 +1,189 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// localRoute is the URL prefix the server exposes local objects under
+const localRoute = "/blobs"
+
+// metaDir holds one sidecar file per object with its content type
+const metaDir = ".meta"
+
+// LocalStore keeps objects in a directory on disk, for laptops and CI
+type LocalStore struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStore creates the root directory if needed.
+// baseURL is the externally reachable address of this server.
+func NewLocalStore(root, baseURL string) (*LocalStore, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path: %v", err)
+	}
+	if err := os.MkdirAll(absRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %v", err)
+	}
+	return &LocalStore{root: absRoot, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Route returns the Echo route pattern local objects should be served from
+func (s *LocalStore) Route() string {
+	return localRoute + "/*"
+}
+
+func (s *LocalStore) Put(ctx context.Context, objectPath string, r io.Reader, contentType string) error {
+	filePath, err := s.resolve(objectPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return err
+	}
+
+	metaPath := s.metaPath(objectPath)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, []byte(contentType), 0o644)
+}
+
+func (s *LocalStore) Get(ctx context.Context, objectPath string) (io.ReadCloser, error) {
+	filePath, err := s.resolve(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, objectPath string) error {
+	filePath, err := s.resolve(objectPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+		return err
+	}
+	os.Remove(s.metaPath(objectPath))
+	return nil
+}
+
+func (s *LocalStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == metaDir && filepath.Dir(filePath) == s.root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+		objectPath := filepath.ToSlash(rel)
+		if !strings.HasPrefix(objectPath, prefix) {
+			return nil
+		}
+
+		info, err := s.Stat(ctx, objectPath)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *info)
+		return nil
+	})
+	return objects, err
+}
+
+func (s *LocalStore) Stat(ctx context.Context, objectPath string) (*ObjectInfo, error) {
+	filePath, err := s.resolve(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(objectPath))
+	if meta, err := os.ReadFile(s.metaPath(objectPath)); err == nil && len(meta) > 0 {
+		contentType = string(meta)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &ObjectInfo{
+		Path:        objectPath,
+		Size:        fi.Size(),
+		ContentType: contentType,
+		Updated:     fi.ModTime(),
+	}, nil
+}
+
+func (s *LocalStore) PublicURL(objectPath string) string {
+	return s.baseURL + localRoute + "/" + objectPath
+}
+
+// resolve maps an object path to a file inside root, rejecting traversal
+func (s *LocalStore) resolve(objectPath string) (string, error) {
+	cleaned := path.Clean("/" + objectPath)
+	if cleaned == "/" || strings.HasPrefix(cleaned, "/"+metaDir+"/") {
+		return "", fmt.Errorf("invalid object path %q", objectPath)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *LocalStore) metaPath(objectPath string) string {
+	cleaned := path.Clean("/" + objectPath)
+	return filepath.Join(s.root, metaDir, filepath.FromSlash(cleaned))
+}
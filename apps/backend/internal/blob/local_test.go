@@ -0,0 +1,174 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) (*LocalStore, string) {
+	t.Helper()
+	root := t.TempDir()
+	store, err := NewLocalStore(root, "http://localhost:8080/")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	return store, root
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	if err := store.Put(ctx, "projects/p1/photo.jpg", strings.NewReader("first"), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(ctx, "projects/p1/photo.jpg", strings.NewReader("second"), "image/webp"); err != nil {
+		t.Fatalf("Put over an existing object: %v", err)
+	}
+
+	rc, err := store.Get(ctx, "projects/p1/photo.jpg")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "second" {
+		t.Errorf("Get = %q, want the latest content", data)
+	}
+
+	info, err := store.Stat(ctx, "projects/p1/photo.jpg")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size != int64(len("second")) || info.ContentType != "image/webp" {
+		t.Errorf("Stat = %d bytes of %s, want %d bytes of image/webp", info.Size, info.ContentType, len("second"))
+	}
+
+	if err := store.Delete(ctx, "projects/p1/photo.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for name, err := range map[string]error{
+		"Get":    func() error { _, err := store.Get(ctx, "projects/p1/photo.jpg"); return err }(),
+		"Stat":   func() error { _, err := store.Stat(ctx, "projects/p1/photo.jpg"); return err }(),
+		"Delete": store.Delete(ctx, "projects/p1/photo.jpg"),
+	} {
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s after Delete = %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func TestLocalStoreStatContentType(t *testing.T) {
+	ctx := context.Background()
+	store, root := newTestStore(t)
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		want        string
+	}{
+		{"stored type", "a/file.bin", "image/png", "image/png"},
+		{"type from the extension", "a/file.json", "", "application/json"},
+		{"unknown", "a/file", "", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.Put(ctx, tt.path, strings.NewReader("x"), tt.contentType); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			if tt.contentType == "" {
+				os.Remove(filepath.Join(root, metaDir, filepath.FromSlash(tt.path)))
+			}
+			info, err := store.Stat(ctx, tt.path)
+			if err != nil {
+				t.Fatalf("Stat: %v", err)
+			}
+			if info.ContentType != tt.want {
+				t.Errorf("content type = %q, want %q", info.ContentType, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalStoreList(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+	for _, p := range []string{"projects/p1/a.jpg", "projects/p1/b.jpg", "projects/p2/a.jpg", "website/logo.png"} {
+		if err := store.Put(ctx, p, strings.NewReader(p), "image/jpeg"); err != nil {
+			t.Fatalf("Put(%s): %v", p, err)
+		}
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"projects/p1/a.jpg", "projects/p1/b.jpg", "projects/p2/a.jpg", "website/logo.png"}},
+		{"projects/", []string{"projects/p1/a.jpg", "projects/p1/b.jpg", "projects/p2/a.jpg"}},
+		{"projects/p1/", []string{"projects/p1/a.jpg", "projects/p1/b.jpg"}},
+		{"missing/", nil},
+	}
+	for _, tt := range tests {
+		objects, err := store.List(ctx, tt.prefix)
+		if err != nil {
+			t.Fatalf("List(%q): %v", tt.prefix, err)
+		}
+		var got []string
+		for _, o := range objects {
+			got = append(got, o.Path)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("List(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStoreRejectsEscapes(t *testing.T) {
+	ctx := context.Background()
+	store, root := newTestStore(t)
+
+	for _, p := range []string{"", "/", ".meta/a.jpg", "../.meta/a.jpg"} {
+		if err := store.Put(ctx, p, strings.NewReader("x"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) succeeded, want an invalid path error", p)
+		}
+	}
+
+	// Traversal is cleaned back inside the root
+	if err := store.Put(ctx, "../../outside.txt", strings.NewReader("x"), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "outside.txt")); err != nil {
+		t.Errorf("object was not kept inside the root: %v", err)
+	}
+}
+
+func TestPathFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://firebasestorage.googleapis.com/v0/b/bucket/o/projects%2Fp1%2Fa.jpg?alt=media&token=x", "projects/p1/a.jpg"},
+		{"http://localhost:8080/blobs/projects/p1/a.jpg", "projects/p1/a.jpg"},
+		{"https://example.com/images/a.jpg", ""},
+		{"://not a url", ""},
+	}
+	for _, tt := range tests {
+		if got := PathFromURL(tt.url); got != tt.want {
+			t.Errorf("PathFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	store, _ := newTestStore(t)
+	if got := PathFromURL(store.PublicURL("website/logo.png")); got != "website/logo.png" {
+		t.Errorf("PathFromURL(PublicURL) = %q, want the object path back", got)
+	}
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +16,18 @@ type Config struct {
 	FirebaseCredentialsFile string
 	FirebaseProjectID       string
 	FirebaseStorageBucket   string
+	DataBackend             string // "firestore" (default) or "memory"
+	DevAuth                 bool   // Accept any bearer token, for local demos only
+	StorageBackend          string // "gcs" or "local"
+	LocalStorageDir         string
+	PublicBaseURL           string
+	SearchReindexInterval   time.Duration
+	TrashRetention          time.Duration // How long deleted projects stay restorable
+	TrashPurgeInterval      time.Duration
+	JobPollInterval         time.Duration // How often the job queue looks for due retries
+	StorageGCGracePeriod    time.Duration // Unreferenced images younger than this are kept
+	StorageGCInterval       time.Duration // 0 disables scheduled garbage collection
+	AutoPublishDelay        time.Duration // Publish this long after the last change to an active project, 0 disables
 }
 
 // Load reads the .env file and populates the Config struct
@@ -30,6 +44,87 @@ func Load() (*Config, error) {
 		FirebaseCredentialsFile: getEnv("FIREBASE_CREDENTIALS_FILE", ""),
 		FirebaseProjectID:       getEnv("FIREBASE_PROJECT_ID", ""),
 		FirebaseStorageBucket:   getEnv("FIREBASE_STORAGE_BUCKET", ""),
+		DataBackend:             getEnv("DATA_BACKEND", "firestore"),
+		StorageBackend:          getEnv("STORAGE_BACKEND", ""),
+		LocalStorageDir:         getEnv("LOCAL_STORAGE_DIR", "./data/blobs"),
+		PublicBaseURL:           getEnv("PUBLIC_BASE_URL", ""),
+	}
+
+	var err error
+	cfg.DevAuth, err = getBool("DEV_AUTH", false)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DevAuth && cfg.Env != "development" {
+		return nil, fmt.Errorf("DEV_AUTH is only allowed when ENV=development")
+	}
+	cfg.SearchReindexInterval, err = getDuration("SEARCH_REINDEX_INTERVAL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.TrashRetention, err = getDuration("TRASH_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.TrashPurgeInterval, err = getDuration("TRASH_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.JobPollInterval, err = getDuration("JOB_POLL_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.JobPollInterval <= 0 {
+		return nil, fmt.Errorf("JOB_POLL_INTERVAL must be positive")
+	}
+	cfg.StorageGCGracePeriod, err = getDuration("STORAGE_GC_GRACE_PERIOD", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.StorageGCInterval, err = getDuration("STORAGE_GC_INTERVAL", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AutoPublishDelay, err = getDuration("AUTO_PUBLISH_DELAY", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.PublicBaseURL == "" {
+		cfg.PublicBaseURL = "http://localhost:" + cfg.Port
+	}
+
+	// Default to GCS alongside Firestore and to a local directory otherwise
+	if cfg.StorageBackend == "" {
+		cfg.StorageBackend = "gcs"
+		if cfg.DataBackend == "memory" {
+			cfg.StorageBackend = "local"
+		}
+	}
+	switch cfg.StorageBackend {
+	case "local":
+	case "gcs":
+		if cfg.DataBackend == "memory" {
+			return nil, fmt.Errorf("STORAGE_BACKEND=gcs requires DATA_BACKEND=firestore")
+		}
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", cfg.StorageBackend)
+	}
+
+	// The in-memory backend still verifies sign-ins with Firebase Auth,
+	// unless DEV_AUTH lets it run without any cloud credentials
+	switch cfg.DataBackend {
+	case "memory":
+		if cfg.Env == "production" {
+			return nil, fmt.Errorf("DATA_BACKEND=memory is not allowed in production")
+		}
+		if !cfg.DevAuth && cfg.FirebaseProjectID == "" {
+			return nil, fmt.Errorf("FIREBASE_PROJECT_ID is required to verify sign-ins, or set DEV_AUTH=1 in development")
+		}
+		return cfg, nil
+	case "firestore":
+	default:
+		return nil, fmt.Errorf("unknown DATA_BACKEND %q", cfg.DataBackend)
 	}
 
 	// Validate required variables
@@ -37,16 +132,42 @@ func Load() (*Config, error) {
 	if cfg.FirebaseProjectID == "" {
 		return nil, fmt.Errorf("FIREBASE_PROJECT_ID is required")
 	}
-	if cfg.FirebaseStorageBucket == "" {
+	if cfg.StorageBackend == "gcs" && cfg.FirebaseStorageBucket == "" {
 		return nil, fmt.Errorf("FIREBASE_STORAGE_BUCKET is required")
 	}
 
 	return cfg, nil
 }
 
+// getDuration parses a duration such as "10m" or "720h" from the environment
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration like 10m: %v", key, err)
+	}
+	return d, nil
+}
+
+// getBool parses a flag such as "1" or "true" from the environment
+func getBool(key string, fallback bool) (bool, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be 1 or 0: %v", key, err)
+	}
+	return b, nil
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
 	return fallback
-}
\ No newline at end of file
+}
@@ -0,0 +1,72 @@
+package config
+
+import "testing"
+
+func TestLoadDevAuth(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantErr     bool
+		wantDevAuth bool
+	}{
+		{
+			name:        "memory backend with dev auth",
+			env:         map[string]string{"DATA_BACKEND": "memory", "DEV_AUTH": "1"},
+			wantDevAuth: true,
+		},
+		{
+			name: "memory backend verifies sign-ins",
+			env:  map[string]string{"DATA_BACKEND": "memory", "FIREBASE_PROJECT_ID": "garden"},
+		},
+		{
+			name:    "memory backend without dev auth needs a Firebase project",
+			env:     map[string]string{"DATA_BACKEND": "memory"},
+			wantErr: true,
+		},
+		{
+			name:    "dev auth outside development",
+			env:     map[string]string{"ENV": "staging", "DATA_BACKEND": "memory", "DEV_AUTH": "1"},
+			wantErr: true,
+		},
+		{
+			name:    "dev auth in production",
+			env:     map[string]string{"ENV": "production", "DEV_AUTH": "true", "FIREBASE_PROJECT_ID": "garden", "FIREBASE_STORAGE_BUCKET": "garden.appspot.com"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid dev auth flag",
+			env:     map[string]string{"DATA_BACKEND": "memory", "DEV_AUTH": "yes please"},
+			wantErr: true,
+		},
+		{
+			name:        "dev auth with Firestore in development",
+			env:         map[string]string{"DEV_AUTH": "1", "FIREBASE_PROJECT_ID": "garden", "FIREBASE_STORAGE_BUCKET": "garden.appspot.com"},
+			wantDevAuth: true,
+		},
+		{
+			name: "Firestore verifies sign-ins by default",
+			env:  map[string]string{"FIREBASE_PROJECT_ID": "garden", "FIREBASE_STORAGE_BUCKET": "garden.appspot.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"ENV", "DATA_BACKEND", "DEV_AUTH", "STORAGE_BACKEND", "FIREBASE_PROJECT_ID", "FIREBASE_STORAGE_BUCKET"} {
+				t.Setenv(key, "")
+			}
+			t.Setenv("ENV", "development")
+			t.Setenv("DATA_BACKEND", "firestore")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.DevAuth != tt.wantDevAuth {
+				t.Errorf("DevAuth = %v, want %v", cfg.DevAuth, tt.wantDevAuth)
+			}
+		})
+	}
+}
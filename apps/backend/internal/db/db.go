@@ -21,6 +21,51 @@ type Client struct {
 
 // NewClient initializes a new Firebase app with Firestore, Auth, and Storage
 func NewClient(ctx context.Context, credentialsFile string, projectID string) (*Client, error) {
+	app, err := newApp(ctx, credentialsFile, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	firestoreClient, err := app.Firestore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing firestore client: %v", err)
+	}
+
+	authClient, err := app.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing auth client: %v", err)
+	}
+
+	storageClient, err := app.Storage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing storage client: %v", err)
+	}
+
+	return &Client{
+		Firestore: firestoreClient,
+		Auth:      authClient,
+		Storage:   storageClient,
+	}, nil
+}
+
+// NewAuthClient initializes only Firebase Auth, for the in-memory data
+// backend that still verifies sign-ins
+func NewAuthClient(ctx context.Context, credentialsFile string, projectID string) (*auth.Client, error) {
+	app, err := newApp(ctx, credentialsFile, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	authClient, err := app.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing auth client: %v", err)
+	}
+	return authClient, nil
+}
+
+// newApp initializes a Firebase app from a key file, or from Application
+// Default Credentials when none is given
+func newApp(ctx context.Context, credentialsFile string, projectID string) (*firebase.App, error) {
 	conf := &firebase.Config{
 		ProjectID: projectID,
 	}
@@ -45,29 +90,9 @@ func NewClient(ctx context.Context, credentialsFile string, projectID string) (*
 	if err != nil {
 		return nil, fmt.Errorf("error initializing firebase app: %v", err)
 	}
-
-	firestoreClient, err := app.Firestore(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error initializing firestore client: %v", err)
-	}
-
-	authClient, err := app.Auth(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error initializing auth client: %v", err)
-	}
-
-	storageClient, err := app.Storage(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error initializing storage client: %v", err)
-	}
-
-	return &Client{
-		Firestore: firestoreClient,
-		Auth:      authClient,
-		Storage:   storageClient,
-	}, nil
+	return app, nil
 }
 
 func (c *Client) Close() error {
 	return c.Firestore.Close()
-}
\ No newline at end of file
+}
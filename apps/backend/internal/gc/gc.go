@@ -0,0 +1,204 @@
+package gc
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Prefixes are the storage folders holding uploaded images
+var Prefixes = []string{"project-images/", "website/images/", "media/"}
+
+// Orphan is a stored image nothing references
+type Orphan struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Updated time.Time `json:"updated"`
+}
+
+// Report summarises a collection run
+type Report struct {
+	DryRun      bool      `json:"dryRun"`
+	Scanned     int       `json:"scanned"`    // Objects found under Prefixes
+	Referenced  int       `json:"referenced"` // Objects a project, the website or the media library uses
+	TooRecent   int       `json:"tooRecent"`  // Unreferenced objects still inside the grace period
+	Orphans     []Orphan  `json:"orphans"`    // Unreferenced objects past the grace period
+	OrphanBytes int64     `json:"orphanBytes"`
+	Queued      int       `json:"queued"` // Orphans queued for deletion
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// Collector finds stored images that no project, website setting or media
+// library entry references. Objects younger than GracePeriod are left alone, since the
+// admin PWA uploads images before the project referencing them is saved.
+type Collector struct {
+	Projects    repository.ProjectRepository
+	Settings    repository.SettingsRepository
+	Media       repository.MediaRepository
+	Blobs       blob.Store
+	Jobs        *jobs.Queue
+	GracePeriod time.Duration
+}
+
+// NewCollector creates a collector
+func NewCollector(projects repository.ProjectRepository, settings repository.SettingsRepository, mediaRepo repository.MediaRepository, blobs blob.Store, queue *jobs.Queue, gracePeriod time.Duration) *Collector {
+	return &Collector{Projects: projects, Settings: settings, Media: mediaRepo, Blobs: blobs, Jobs: queue, GracePeriod: gracePeriod}
+}
+
+// Run walks the image prefixes and reports unreferenced objects. Unless
+// dryRun is set, every orphan is queued for deletion.
+func (c *Collector) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	// 1. Collect references first, so anything saved while we list storage is
+	// at worst reported as too recent rather than deleted
+	referenced, unused, err := c.references(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: dryRun, Orphans: []Orphan{}, GeneratedAt: time.Now()}
+	cutoff := report.GeneratedAt.Add(-c.GracePeriod)
+
+	// 2. Walk storage
+	for _, prefix := range Prefixes {
+		objects, err := c.Blobs.List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			report.Scanned++
+			switch {
+			case referenced[obj.Path]:
+				report.Referenced++
+			case obj.Updated.After(cutoff):
+				report.TooRecent++
+			default:
+				report.Orphans = append(report.Orphans, Orphan{Path: obj.Path, Size: obj.Size, Updated: obj.Updated})
+				report.OrphanBytes += obj.Size
+			}
+		}
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Path < report.Orphans[j].Path })
+
+	// 3. Queue the deletions, and forget abandoned uploads whose files go
+	if !dryRun {
+		orphaned := make(map[string]bool, len(report.Orphans))
+		for _, orphan := range report.Orphans {
+			if err := c.Jobs.EnqueueDeleteBlob(ctx, orphan.Path); err != nil {
+				return report, err
+			}
+			orphaned[orphan.Path] = true
+			report.Queued++
+		}
+		for _, asset := range unused {
+			if orphaned[asset.Image.StoragePath] {
+				if err := c.Media.Delete(ctx, asset.Hash); err != nil && !errors.Is(err, repository.ErrNotFound) {
+					return report, err
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// references returns every object path used by a project (trashed ones
+// included, they can still be restored), by the website settings or by the
+// media library, plus the indexed uploads nothing references
+func (c *Collector) references(ctx context.Context) (map[string]bool, []models.MediaAsset, error) {
+	referenced := make(map[string]bool)
+	add := func(value string) {
+		if path := objectPath(value); path != "" {
+			referenced[path] = true
+		}
+	}
+
+	for _, trashed := range []bool{false, true} {
+		projects, _, err := c.Projects.List(ctx, repository.ListOptions{Trashed: trashed})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range projects {
+			add(p.CoverImage)
+			for _, img := range p.Images {
+				for _, path := range img.StoragePaths() {
+					add(path)
+				}
+				add(img.URL)
+				add(img.Thumbnail)
+			}
+		}
+	}
+
+	// The website content tree stores images as URLs anywhere in the document
+	for _, doc := range []string{repository.WebsiteDocument, repository.ProjectsDocument} {
+		settings, err := c.Settings.Get(ctx, doc)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, err
+		}
+		walkStrings(settings, add)
+	}
+
+	// Library images are kept even when no project or website section uses them
+	assets, err := c.Media.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var unused []models.MediaAsset
+	for _, asset := range assets {
+		if len(asset.References) == 0 {
+			unused = append(unused, asset)
+			continue
+		}
+		for _, path := range asset.Image.StoragePaths() {
+			add(path)
+		}
+	}
+	return referenced, unused, nil
+}
+
+// objectPath turns a stored reference, either a URL or a bare storage path,
+// into an object path
+func objectPath(value string) string {
+	if value == "" {
+		return ""
+	}
+	if path := blob.PathFromURL(value); path != "" {
+		return path
+	}
+	for _, prefix := range Prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return value
+		}
+	}
+	return ""
+}
+
+// walkStrings calls fn for every string nested anywhere in value
+func walkStrings(value interface{}, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	case []string:
+		for _, item := range v {
+			fn(item)
+		}
+	case map[string]string:
+		for _, item := range v {
+			fn(item)
+		}
+	}
+}
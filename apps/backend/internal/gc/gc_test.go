@@ -0,0 +1,178 @@
+package gc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// testCollector stores objects referenced in every supported way, plus two
+// orphans and a fresh upload, aging all but the fresh upload past the grace
+// period
+func testCollector(t *testing.T) (*Collector, *repository.MemoryJobRepository) {
+	t.Helper()
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := blob.NewLocalStore(root, "http://localhost/storage")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, path := range []string{
+		"project-images/p1/a.jpg", "project-images/p1/a_thumb.jpg", "project-images/p2/b.jpg",
+		"website/images/logo.png", "media/lib.jpg", "media/unused.jpg",
+		"project-images/orphan.jpg", "project-images/new-upload.jpg", "other/notes.txt",
+	} {
+		if err := store.Put(ctx, path, strings.NewReader(path), "image/jpeg"); err != nil {
+			t.Fatalf("Put(%s): %v", path, err)
+		}
+		if path != "project-images/new-upload.jpg" {
+			if err := os.Chtimes(filepath.Join(root, filepath.FromSlash(path)), old, old); err != nil {
+				t.Fatalf("Chtimes: %v", err)
+			}
+		}
+	}
+
+	projects := repository.NewMemoryProjectRepository()
+	trashedAt := time.Now()
+	for _, p := range []models.Project{
+		{ID: "p1", Images: []models.ProjectImage{{ID: "a", StoragePath: "project-images/p1/a.jpg", Thumbnail: store.PublicURL("project-images/p1/a_thumb.jpg")}}},
+		{ID: "p2", DeletedAt: &trashedAt, Images: []models.ProjectImage{{ID: "b", URL: store.PublicURL("project-images/p2/b.jpg")}}},
+	} {
+		p := p
+		if err := projects.Create(ctx, &p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	settings := repository.NewMemorySettingsRepository()
+	if err := settings.Merge(ctx, repository.WebsiteDocument, map[string]interface{}{
+		"hero": map[string]interface{}{"images": []interface{}{store.PublicURL("website/images/logo.png")}},
+	}); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	mediaRepo := repository.NewMemoryMediaRepository()
+	for _, asset := range []models.MediaAsset{
+		{Hash: "lib", Image: models.ProjectImage{StoragePath: "media/lib.jpg"}, References: []string{"website"}},
+		{Hash: "unused", Image: models.ProjectImage{StoragePath: "media/unused.jpg"}, References: []string{}},
+	} {
+		asset := asset
+		if err := mediaRepo.Create(ctx, &asset); err != nil {
+			t.Fatalf("Create asset: %v", err)
+		}
+	}
+
+	jobRepo := repository.NewMemoryJobRepository()
+	return NewCollector(projects, settings, mediaRepo, store, jobs.NewQueue(jobRepo), 24*time.Hour), jobRepo
+}
+
+func TestCollectorRun(t *testing.T) {
+	wantOrphans := []string{"media/unused.jpg", "project-images/orphan.jpg"}
+
+	tests := []struct {
+		name       string
+		dryRun     bool
+		wantQueued []string
+	}{
+		{"dry run", true, []string{}},
+		{"collect", false, wantOrphans},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, jobRepo := testCollector(t)
+
+			report, err := c.Run(ctx, tt.dryRun)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if report.Scanned != 8 || report.Referenced != 5 || report.TooRecent != 1 || report.Queued != len(tt.wantQueued) {
+				t.Errorf("scanned %d, referenced %d, too recent %d, queued %d", report.Scanned, report.Referenced, report.TooRecent, report.Queued)
+			}
+			var orphans []string
+			var size int64
+			for _, o := range report.Orphans {
+				orphans = append(orphans, o.Path)
+				size += o.Size
+			}
+			if !reflect.DeepEqual(orphans, wantOrphans) || report.OrphanBytes != size {
+				t.Errorf("orphans = %v of %d bytes, want %v", orphans, report.OrphanBytes, wantOrphans)
+			}
+
+			queued, err := jobRepo.List(ctx, repository.JobFilter{Type: jobs.TypeDeleteBlob}, 0)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			paths := []string{}
+			for _, job := range queued {
+				paths = append(paths, job.Payload["path"])
+			}
+			sort.Strings(paths)
+			if !reflect.DeepEqual(paths, tt.wantQueued) {
+				t.Errorf("queued deletions = %v, want %v", paths, tt.wantQueued)
+			}
+
+			// The abandoned library upload is forgotten along with its file
+			_, err = c.Media.Get(ctx, "unused")
+			if forgotten := errors.Is(err, repository.ErrNotFound); forgotten == tt.dryRun {
+				t.Errorf("unused asset forgotten = %v on a dry run = %v", forgotten, tt.dryRun)
+			}
+			if _, err := c.Media.Get(ctx, "lib"); err != nil {
+				t.Errorf("referenced asset: %v", err)
+			}
+		})
+	}
+}
+
+func TestCollectorGracePeriod(t *testing.T) {
+	tests := []struct {
+		grace         time.Duration
+		wantTooRecent int
+		wantOrphans   int
+	}{
+		{0, 0, 3},
+		{24 * time.Hour, 1, 2},
+		{72 * time.Hour, 3, 0},
+	}
+	for _, tt := range tests {
+		c, _ := testCollector(t)
+		c.GracePeriod = tt.grace
+		report, err := c.Run(context.Background(), true)
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if report.TooRecent != tt.wantTooRecent || len(report.Orphans) != tt.wantOrphans {
+			t.Errorf("grace %v: %d too recent and %d orphans, want %d and %d",
+				tt.grace, report.TooRecent, len(report.Orphans), tt.wantTooRecent, tt.wantOrphans)
+		}
+	}
+}
+
+func TestObjectPath(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"project-images/p1/a.jpg", "project-images/p1/a.jpg"},
+		{"http://localhost/storage/blobs/media/a.jpg", "media/a.jpg"},
+		{"https://example.com/a.jpg", ""},
+		{"Sunny patio", ""},
+	}
+	for _, tt := range tests {
+		if got := objectPath(tt.value); got != tt.want {
+			t.Errorf("objectPath(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+)
+
+// BlobHandler serves objects from a blob store over HTTP
+type BlobHandler struct {
+	Blobs blob.Store
+}
+
+// NewBlobHandler creates a new handler instance
+func NewBlobHandler(blobs blob.Store) *BlobHandler {
+	return &BlobHandler{Blobs: blobs}
+}
+
+// ServeBlob handles GET /blobs/*
+func (h *BlobHandler) ServeBlob(c echo.Context) error {
+	ctx := context.Background()
+	objectPath := c.Param("*")
+
+	info, err := h.Blobs.Stat(ctx, objectPath)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Object not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid object path"})
+	}
+
+	rc, err := h.Blobs.Get(ctx, objectPath)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to read object"})
+	}
+	defer rc.Close()
+
+	return c.Stream(http.StatusOK, info.ContentType, rc)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// anyVersion is returned by ifMatchVersion for "If-Match: *"
+const anyVersion = -1
+
+var errMissingIfMatch = errors.New("If-Match header is required, send the ETag from your last read")
+
+// etag formats a document version as a strong entity tag
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// setETag exposes a document version in the ETag response header
+func setETag(c echo.Context, version int64) {
+	c.Response().Header().Set("ETag", etag(version))
+}
+
+// ifMatchVersion returns the version named in the If-Match request header.
+// Editors must echo back the ETag they read so concurrent edits are detected.
+func ifMatchVersion(c echo.Context) (int64, error) {
+	header := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if header == "" {
+		return 0, errMissingIfMatch
+	}
+	if header == "*" {
+		return anyVersion, nil
+	}
+
+	// Only a single tag makes sense for a versioned document
+	tag := strings.TrimPrefix(header, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		unquoted = tag
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 0 {
+		return 0, errors.New("If-Match must be a single ETag returned by the API")
+	}
+	return version, nil
+}
+
+// ifMatchError maps a failure from ifMatchVersion to a response
+func ifMatchError(c echo.Context, err error) error {
+	if errors.Is(err, errMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/config"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int64
+		wantErr bool
+	}{
+		{`"3"`, 3, false},
+		{`W/"3"`, 3, false},
+		{"3", 3, false},
+		{"*", anyVersion, false},
+		{"", 0, true},
+		{`"three"`, 0, true},
+		{`"-1"`, 0, true},
+		{`"1", "2"`, 0, true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("If-Match", tt.header)
+		}
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+		got, err := ifMatchVersion(c)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("If-Match %s = %d, %v, want %d (error %v)", tt.header, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestUpdateProjectIfMatch(t *testing.T) {
+	body := `{"id":"patio","title":"Renamed","category":"hardscape","status":"draft"}`
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		wantStatus  int
+		wantTitle   string
+		wantVersion int64
+	}{
+		{"current ETag", `"2"`, http.StatusOK, "Renamed", 3},
+		{"weak ETag", `W/"2"`, http.StatusOK, "Renamed", 3},
+		{"any version", "*", http.StatusOK, "Renamed", 3},
+		{"missing", "", http.StatusPreconditionRequired, "Patio", 2},
+		{"stale", `"1"`, http.StatusPreconditionFailed, "Patio", 2},
+		{"malformed", `"two"`, http.StatusBadRequest, "Patio", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newProjectServer(t)
+			p := s.seed(t, models.Project{ID: "patio", Title: "Patio", Category: "hardscape", Status: models.StatusDraft})
+			p.Version = 1
+			if err := s.projects.Update(context.Background(), &p); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			rec := s.do(http.MethodGet, "/admin/projects/patio", "", nil)
+			if etag := rec.Header().Get("ETag"); etag != `"2"` {
+				t.Fatalf("GET ETag = %s, want \"2\"", etag)
+			}
+
+			header := map[string]string{}
+			if tt.ifMatch != "" {
+				header["If-Match"] = tt.ifMatch
+			}
+			rec = s.do(http.MethodPut, "/admin/projects/patio", body, header)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK || rec.Code == http.StatusPreconditionFailed {
+				if etag := rec.Header().Get("ETag"); etag != fmt.Sprintf(`"%d"`, tt.wantVersion) {
+					t.Errorf("ETag = %s, want %s", etag, fmt.Sprintf(`"%d"`, tt.wantVersion))
+				}
+			}
+			stored := s.stored(t, "patio")
+			if stored.Title != tt.wantTitle || stored.Version != tt.wantVersion {
+				t.Errorf("stored %q at version %d, want %q at version %d", stored.Title, stored.Version, tt.wantTitle, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestUpdateProjectSettingsIfMatch(t *testing.T) {
+	h := NewSettingsHandler(nil, repository.NewMemorySettingsRepository(), nil, nil, nil, nil, &config.Config{})
+	e := echo.New()
+	e.GET("/settings/projects", h.GetProjectSettings)
+	e.PUT("/settings/projects", h.UpdateProjectSettings)
+	send := func(method, ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/settings/projects", strings.NewReader(`{"categories":["planting"],"tags":[]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Each step runs against the state the previous steps left behind
+	steps := []struct {
+		name       string
+		method     string
+		ifMatch    string
+		wantStatus int
+		wantETag   string
+	}{
+		{"unsaved settings read as version 0", http.MethodGet, "", http.StatusOK, `"0"`},
+		{"save without If-Match", http.MethodPut, "", http.StatusPreconditionRequired, ""},
+		{"first save", http.MethodPut, `"0"`, http.StatusOK, `"1"`},
+		{"second editor with the old ETag", http.MethodPut, `"0"`, http.StatusPreconditionFailed, `"1"`},
+		{"reload and save", http.MethodPut, `"1"`, http.StatusOK, `"2"`},
+		{"read", http.MethodGet, "", http.StatusOK, `"2"`},
+	}
+	for _, step := range steps {
+		rec := send(step.method, step.ifMatch)
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, rec.Code, step.wantStatus, rec.Body.String())
+		}
+		if etag := rec.Header().Get("ETag"); etag != step.wantETag {
+			t.Errorf("%s: ETag = %s, want %s", step.name, etag, step.wantETag)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// JobHandler exposes the background job queue to admins
+type JobHandler struct {
+	Jobs *jobs.Queue
+}
+
+// NewJobHandler creates a new handler instance
+func NewJobHandler(queue *jobs.Queue) *JobHandler {
+	return &JobHandler{Jobs: queue}
+}
+
+// GetJobs handles GET /admin/jobs
+// Defaults to the dead-letter list; ?status=pending|running|dead or ?status=all.
+// ?type= limits it to one job type, e.g. "blob.delete".
+func (h *JobHandler) GetJobs(c echo.Context) error {
+	status := c.QueryParam("status")
+	switch status {
+	case "":
+		status = models.JobDead
+	case "all":
+		status = ""
+	case models.JobPending, models.JobRunning, models.JobDead:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "status must be pending, running, dead or all"})
+	}
+
+	limit := defaultPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPageSize {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxPageSize)})
+		}
+		limit = n
+	}
+
+	filter := repository.JobFilter{Type: c.QueryParam("type"), Status: status}
+	list, err := h.Jobs.List(context.Background(), filter, limit)
+	if err != nil {
+		c.Logger().Errorf("Failed to list jobs: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list jobs"})
+	}
+	return c.JSON(http.StatusOK, ListResponse[models.Job]{Items: list})
+}
+
+// RetryJob handles POST /admin/jobs/:id/retry
+func (h *JobHandler) RetryJob(c echo.Context) error {
+	job, err := h.Jobs.Retry(context.Background(), c.Param("id"))
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Job not found"})
+	case errors.Is(err, jobs.ErrNotDead):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Only dead jobs can be retried"})
+	case err != nil:
+		c.Logger().Errorf("Failed to retry job: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retry job"})
+	}
+	return c.JSON(http.StatusOK, job)
+}
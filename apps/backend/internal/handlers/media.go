@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/media"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Limits on media library details
+const (
+	maxMediaCaptionLength = 500
+	maxMediaTags          = 20
+)
+
+// MediaHandler exposes the media library: every uploaded image, wherever it is used
+type MediaHandler struct {
+	Media    *media.Library
+	Projects repository.ProjectRepository
+	Settings repository.SettingsRepository
+}
+
+// NewMediaHandler creates a new handler instance
+func NewMediaHandler(library *media.Library, projects repository.ProjectRepository, settings repository.SettingsRepository) *MediaHandler {
+	return &MediaHandler{Media: library, Projects: projects, Settings: settings}
+}
+
+// ProjectMediaUsage is one project image using a media asset
+type ProjectMediaUsage struct {
+	ProjectID string `json:"projectId"`
+	Title     string `json:"title"`
+	Slug      string `json:"slug"`
+	Status    string `json:"status"`
+	Trashed   bool   `json:"trashed,omitempty"`
+	ImageID   string `json:"imageId"`
+}
+
+// MediaUsage lists everything referencing a media asset
+type MediaUsage struct {
+	Hash     string              `json:"hash"`
+	Library  bool                `json:"library"`  // Kept in the library even when unused
+	Projects []ProjectMediaUsage `json:"projects"` // Trashed projects included, they still hold their images
+	Website  []string            `json:"website"`  // Where in the website settings, e.g. "content.benefits.cards[0].image"
+}
+
+// ListMedia handles GET /admin/media
+// Lists images in use or kept in the library, newest first. ?q= matches
+// the caption, alt text and tags, ?tag= filters on an exact tag. Pass the
+// nextCursor back as ?cursor= for the next page.
+func (h *MediaHandler) ListMedia(c echo.Context) error {
+	limit := defaultPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPageSize {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxPageSize)})
+		}
+		limit = n
+	}
+	query := strings.ToLower(strings.TrimSpace(c.QueryParam("q")))
+	tag := strings.TrimSpace(c.QueryParam("tag"))
+
+	assets, err := h.Media.Media.List(context.Background())
+	if err != nil {
+		c.Logger().Errorf("Failed to list media: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list media"})
+	}
+
+	// Uploads nothing uses yet are left out, abandoned ones are garbage collected
+	var matches []models.MediaAsset
+	for _, asset := range assets {
+		if len(asset.References) > 0 && mediaMatches(asset, query, tag) {
+			matches = append(matches, asset)
+		}
+	}
+
+	// The cursor is the hash of the last asset on the previous page
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		start := -1
+		for i, asset := range matches {
+			if asset.Hash == cursor {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		}
+		matches = matches[start:]
+	}
+
+	nextCursor := ""
+	if len(matches) > limit {
+		matches = matches[:limit]
+		nextCursor = matches[len(matches)-1].Hash
+	}
+	if matches == nil {
+		matches = []models.MediaAsset{}
+	}
+	return c.JSON(http.StatusOK, ListResponse[models.MediaAsset]{Items: matches, NextCursor: nextCursor})
+}
+
+// GetMedia handles GET /admin/media/:hash
+func (h *MediaHandler) GetMedia(c echo.Context) error {
+	asset, err := h.Media.Media.Get(context.Background(), c.Param("hash"))
+	if err != nil {
+		return mediaLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, asset)
+}
+
+// UpdateMedia handles PUT /admin/media/:hash
+// Replaces the library caption, alt text and tags. Projects keep their own
+// caption and alt text per image.
+func (h *MediaHandler) UpdateMedia(c echo.Context) error {
+	var req struct {
+		Caption string   `json:"caption"`
+		Alt     string   `json:"alt"`
+		Tags    []string `json:"tags"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	tags := normalizeTags(req.Tags)
+	switch {
+	case len(req.Caption) > maxMediaCaptionLength || len(req.Alt) > maxMediaCaptionLength:
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": fmt.Sprintf("caption and alt must be at most %d characters", maxMediaCaptionLength)})
+	case len(tags) > maxMediaTags:
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": fmt.Sprintf("at most %d tags are allowed", maxMediaTags)})
+	}
+
+	asset, err := h.Media.Media.UpdateDetails(context.Background(), c.Param("hash"), req.Caption, req.Alt, tags)
+	if err != nil {
+		return mediaLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, asset)
+}
+
+// DeleteMedia handles DELETE /admin/media/:hash
+// Removes an image from the library and deletes its files. Images still
+// used by a project or the website are refused with 409 and their usage.
+func (h *MediaHandler) DeleteMedia(c echo.Context) error {
+	ctx := context.Background()
+	asset, err := h.Media.Media.Get(ctx, c.Param("hash"))
+	if err != nil {
+		return mediaLookupError(c, err)
+	}
+
+	for _, ref := range asset.References {
+		if ref != models.LibraryMediaRef {
+			usage, err := h.usage(ctx, asset)
+			if err != nil {
+				c.Logger().Errorf("Failed to resolve usage of media %s: %v", asset.Hash, err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to resolve media usage"})
+			}
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error": "Image is still in use, remove it from these projects and website sections first",
+				"usage": usage,
+			})
+		}
+	}
+
+	if err := h.Media.Release(ctx, asset.Image, models.LibraryMediaRef); err != nil {
+		c.Logger().Errorf("Failed to delete media %s: %v", asset.Hash, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete media"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted", "hash": asset.Hash})
+}
+
+// GetMediaUsage handles GET /admin/media/:hash/usage
+// Lists the projects and website sections using an image.
+func (h *MediaHandler) GetMediaUsage(c echo.Context) error {
+	ctx := context.Background()
+	asset, err := h.Media.Media.Get(ctx, c.Param("hash"))
+	if err != nil {
+		return mediaLookupError(c, err)
+	}
+	usage, err := h.usage(ctx, asset)
+	if err != nil {
+		c.Logger().Errorf("Failed to resolve usage of media %s: %v", asset.Hash, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to resolve media usage"})
+	}
+	return c.JSON(http.StatusOK, usage)
+}
+
+// usage resolves the references of an asset into projects and settings paths
+func (h *MediaHandler) usage(ctx context.Context, asset *models.MediaAsset) (*MediaUsage, error) {
+	usage := &MediaUsage{
+		Hash:     asset.Hash,
+		Projects: []ProjectMediaUsage{},
+		Website:  []string{},
+	}
+
+	projects := make(map[string]*models.Project)
+	for _, ref := range asset.References {
+		switch ref {
+		case models.LibraryMediaRef:
+			usage.Library = true
+			continue
+		case models.WebsiteMediaRef:
+			continue // Resolved from the settings below
+		}
+
+		projectID, imageID, ok := models.ParseProjectMediaRef(ref)
+		if !ok {
+			continue
+		}
+		p, found := projects[projectID]
+		if !found {
+			var err error
+			p, err = h.Projects.Get(ctx, projectID)
+			if errors.Is(err, repository.ErrNotFound) {
+				p = nil // Purged, its reference is released with it
+			} else if err != nil {
+				return nil, err
+			}
+			projects[projectID] = p
+		}
+		if p == nil {
+			continue
+		}
+		usage.Projects = append(usage.Projects, ProjectMediaUsage{
+			ProjectID: p.ID,
+			Title:     p.Title,
+			Slug:      p.Slug,
+			Status:    p.Status,
+			Trashed:   p.DeletedAt != nil,
+			ImageID:   imageID,
+		})
+	}
+
+	settings, err := h.Settings.Get(ctx, repository.WebsiteDocument)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	findHash(settings, asset.Hash, "", func(path string) {
+		usage.Website = append(usage.Website, path)
+	})
+	sort.Strings(usage.Website)
+	return usage, nil
+}
+
+// findHash calls fn with the path of every map in value whose "hash" is hash
+func findHash(value interface{}, hash, path string, fn func(string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v["hash"] == hash {
+			fn(path)
+			return
+		}
+		for key, item := range v {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			findHash(item, hash, child, fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			findHash(item, hash, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	}
+}
+
+// mediaMatches reports whether an asset matches a lowercased search query
+// and an exact tag; empty filters match everything
+func mediaMatches(asset models.MediaAsset, query, tag string) bool {
+	if tag != "" {
+		found := false
+		for _, t := range asset.Tags {
+			if strings.EqualFold(t, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if query == "" {
+		return true
+	}
+	fields := append([]string{asset.Caption, asset.Alt}, asset.Tags...)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTags trims tags and drops empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	out := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+// mediaLookupError maps a media repository error to a response
+func mediaLookupError(c echo.Context, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Media not found"})
+	}
+	c.Logger().Errorf("Failed to fetch media: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch media"})
+}
@@ -1,29 +1,47 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
-	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
 	"github.com/networkcaretaker/garden_app/backend/internal/config"
-	"github.com/networkcaretaker/garden_app/backend/internal/db"
+	"github.com/networkcaretaker/garden_app/backend/internal/media"
 	"github.com/networkcaretaker/garden_app/backend/internal/models"
-	"google.golang.org/api/iterator"
+	"github.com/networkcaretaker/garden_app/backend/internal/patch"
+	"github.com/networkcaretaker/garden_app/backend/internal/publish"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+	"github.com/networkcaretaker/garden_app/backend/internal/search"
+	"github.com/networkcaretaker/garden_app/backend/internal/slug"
+	"github.com/networkcaretaker/garden_app/backend/internal/validation"
+	"github.com/networkcaretaker/garden_app/backend/internal/workflow"
 )
 
-// ProjectHandler holds the database connection and configuration
+// ProjectHandler holds the repositories, blob store, search index, media library, publish scheduler and configuration
 type ProjectHandler struct {
-	Client *db.Client
-	Config *config.Config
+	Projects  repository.ProjectRepository
+	Revisions repository.RevisionRepository
+	Settings  repository.SettingsRepository
+	Blobs     blob.Store
+	Search    *search.Index
+	Media     *media.Library
+	Scheduler *publish.Scheduler
+	Config    *config.Config
 }
 
 // NewProjectHandler creates a new handler instance
-func NewProjectHandler(client *db.Client, cfg *config.Config) *ProjectHandler {
-	return &ProjectHandler{Client: client, Config: cfg}
+func NewProjectHandler(projects repository.ProjectRepository, revisions repository.RevisionRepository, settings repository.SettingsRepository, blobs blob.Store, index *search.Index, library *media.Library, scheduler *publish.Scheduler, cfg *config.Config) *ProjectHandler {
+	return &ProjectHandler{Projects: projects, Revisions: revisions, Settings: settings, Blobs: blobs, Search: index, Media: library, Scheduler: scheduler, Config: cfg}
 }
 
 // CreateProject handles POST /projects
@@ -33,86 +51,234 @@ func (h *ProjectHandler) CreateProject(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 	}
 
+	ctx := context.Background()
+	uid, role := currentUser(c)
+
+	req.Status = models.NormalizeStatus(req.Status)
+	if errs, err := h.validateProject(ctx, req, nil); err != nil {
+		c.Logger().Errorf("Failed to load project settings: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to validate project"})
+	} else if errs != nil {
+		return validationError(c, errs)
+	}
+	if !workflow.CanCreate(req.Status, role) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": fmt.Sprintf("Your role cannot create %s projects", req.Status)})
+	}
+
 	now := time.Now()
 	newProject := models.Project{
-		ID:            req.ID,
-		Title:         req.Title,
-		Description:   req.Description,
-		Location:      req.Location,
-		Category:      req.Category,
-		Tags:          req.Tags,
-		Status:        req.Status,
-		Images:        req.Images,
-		CoverImage:    req.CoverImage, // Use the provided cover image
-		ImageGroups:   req.ImageGroups,
+		ID:             req.ID,
+		Title:          req.Title,
+		Description:    req.Description,
+		Location:       req.Location,
+		Category:       req.Category,
+		Tags:           req.Tags,
+		Status:         req.Status,
+		Images:         req.Images,
+		CoverImage:     req.CoverImage, // Use the provided cover image
+		ImageGroups:    req.ImageGroups,
 		HasTestimonial: req.HasTestimonial,
 		Testimonial:    req.Testimonial,
-		Published:     false,
-		CreatedAt:     now,
-		UpdatedAt:     now,
+		Published:      false,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
+	if req.Featured != nil {
+		newProject.Featured = *req.Featured
+	}
+	if req.CompletedDate != nil {
+		newProject.CompletedDate = *req.CompletedDate
+	}
+	workflow.Record(&newProject, uid, now)
+
 	// Fallback: If no cover image is explicitly set, but there are images, use the first one
 	if newProject.CoverImage == "" && len(newProject.Images) > 0 {
 		newProject.CoverImage = newProject.Images[0].URL
 	}
 
-	ctx := context.Background()
-	var err error
-
-	if newProject.ID != "" {
-		_, err = h.Client.Firestore.Collection("projects").Doc(newProject.ID).Set(ctx, newProject)
-	} else {
-		var ref *firestore.DocumentRef
-		ref, _, err = h.Client.Firestore.Collection("projects").Add(ctx, newProject)
-		if err == nil {
-			newProject.ID = ref.ID
-		}
+	// Generate a unique, human readable slug from the requested slug or the title
+	slugSource := req.Slug
+	if slugSource == "" {
+		slugSource = req.Title
 	}
-
+	projectSlug, err := h.uniqueSlug(ctx, slugSource, newProject.ID)
 	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to generate slug"})
+	}
+	newProject.Slug = projectSlug
+
+	if err := h.Projects.Create(ctx, &newProject); errors.Is(err, repository.ErrConflict) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A project with this ID already exists"})
+	} else if err != nil {
+		c.Logger().Errorf("Failed to create project: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save project"})
 	}
+	h.Search.Put(newProject)
+	h.recordRevision(c, nil, newProject, "create")
+	for _, img := range newProject.Images {
+		if err := h.Media.Retain(ctx, img, models.ProjectMediaRef(newProject.ID, img.ID)); err != nil {
+			c.Logger().Errorf("Failed to record use of image %s: %v", img.ID, err)
+		}
+	}
 
 	// Update website settings timestamp if active
-	if strings.ToLower(newProject.Status) == "active" {
-		_, err := h.Client.Firestore.Collection(settingsCollection).Doc(websiteDocument).Set(ctx, map[string]interface{}{
-			"projectUpdatedAt": time.Now(),
-		}, firestore.MergeAll)
-		if err != nil {
-			c.Logger().Errorf("Failed to update projectUpdatedAt in settings: %v", err)
-		}
+	if workflow.AffectsPublicSite("", newProject.Status) {
+		h.touchProjectUpdatedAt(c)
 	}
 
+	setETag(c, newProject.Version)
 	return c.JSON(http.StatusCreated, newProject)
 }
 
+// ListResponse is the envelope every project listing is returned in
+type ListResponse[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
 // GetProjects handles GET /projects
+// Only active projects are returned, using the public-safe projection.
 func (h *ProjectHandler) GetProjects(c echo.Context) error {
 	ctx := context.Background()
-	var projects []models.Project
 
-	iter := h.Client.Firestore.Collection("projects").OrderBy("createdAt", firestore.Desc).Documents(ctx)
-	defer iter.Stop()
+	opts, err := parseListOptions(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	opts.Status = models.StatusActive
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch projects"})
-		}
+	projects, nextCursor, err := h.Projects.List(ctx, opts)
+	if err != nil {
+		return listError(c, err)
+	}
 
-		var p models.Project
-		if err := doc.DataTo(&p); err != nil {
-			continue
-		}
-		p.ID = doc.Ref.ID
-		projects = append(projects, p)
+	publicProjects := make([]models.PublicProject, 0, len(projects))
+	for _, p := range projects {
+		publicProjects = append(publicProjects, p.Public())
+	}
+
+	return c.JSON(http.StatusOK, ListResponse[models.PublicProject]{Items: publicProjects, NextCursor: nextCursor})
+}
+
+// GetAdminProjects handles GET /admin/projects
+// Returns projects of any status (filterable with ?status=), including internal fields.
+func (h *ProjectHandler) GetAdminProjects(c echo.Context) error {
+	ctx := context.Background()
+
+	opts, err := parseListOptions(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	opts.Status = c.QueryParam("status")
+
+	projects, nextCursor, err := h.Projects.List(ctx, opts)
+	if err != nil {
+		return listError(c, err)
+	}
+	if projects == nil {
+		projects = []models.Project{}
+	}
+
+	return c.JSON(http.StatusOK, ListResponse[models.Project]{Items: projects, NextCursor: nextCursor})
+}
+
+// SearchProjects handles GET /projects/search?q=
+// Only active projects are searched, results are ordered by relevance.
+func (h *ProjectHandler) SearchProjects(c echo.Context) error {
+	query, limit, err := parseSearchParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	results := h.Search.Search(query, search.Options{Status: models.StatusActive, Limit: limit})
+	items := make([]models.PublicProject, 0, len(results))
+	for _, r := range results {
+		items = append(items, r.Project.Public())
+	}
+
+	return c.JSON(http.StatusOK, ListResponse[models.PublicProject]{Items: items})
+}
+
+// SearchAdminProjects handles GET /admin/projects/search?q=&status=
+func (h *ProjectHandler) SearchAdminProjects(c echo.Context) error {
+	query, limit, err := parseSearchParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	results := h.Search.Search(query, search.Options{Status: c.QueryParam("status"), Limit: limit})
+	items := make([]models.Project, 0, len(results))
+	for _, r := range results {
+		items = append(items, r.Project)
+	}
+
+	return c.JSON(http.StatusOK, ListResponse[models.Project]{Items: items})
+}
+
+// RebuildSearchIndex reloads every project into the search index.
+// Called on startup and periodically so instances pick up each other's writes.
+func (h *ProjectHandler) RebuildSearchIndex(ctx context.Context) error {
+	projects, _, err := h.Projects.List(ctx, repository.ListOptions{})
+	if err != nil {
+		return err
+	}
+	h.Search.Rebuild(projects)
+	return nil
+}
+
+// GetProject handles GET /projects/:id
+// Only active projects outside the trash are visible publicly.
+func (h *ProjectHandler) GetProject(c echo.Context) error {
+	project, err := h.Projects.Get(context.Background(), c.Param("id"))
+	if err != nil || !project.IsPublic() {
+		return projectLookupError(c, err)
+	}
+
+	setETag(c, project.Version)
+	return c.JSON(http.StatusOK, project.Public())
+}
+
+// GetProjectBySlug handles GET /projects/by-slug/:slug
+// Old slugs redirect permanently to the project's current slug.
+func (h *ProjectHandler) GetProjectBySlug(c echo.Context) error {
+	requested := c.Param("slug")
+	project, err := h.Projects.FindBySlug(context.Background(), requested)
+	if err != nil || !project.IsPublic() {
+		return projectLookupError(c, err)
+	}
+
+	if project.Slug != requested {
+		return c.Redirect(http.StatusMovedPermanently, "/projects/by-slug/"+project.Slug)
+	}
+	setETag(c, project.Version)
+	return c.JSON(http.StatusOK, project.Public())
+}
+
+// GetAdminProject handles GET /admin/projects/:id
+func (h *ProjectHandler) GetAdminProject(c echo.Context) error {
+	project, err := h.Projects.Get(context.Background(), c.Param("id"))
+	if err != nil {
+		return projectLookupError(c, err)
+	}
+
+	setETag(c, project.Version)
+	return c.JSON(http.StatusOK, project)
+}
+
+// GetAdminProjectBySlug handles GET /admin/projects/by-slug/:slug
+func (h *ProjectHandler) GetAdminProjectBySlug(c echo.Context) error {
+	requested := c.Param("slug")
+	project, err := h.Projects.FindBySlug(context.Background(), requested)
+	if err != nil {
+		return projectLookupError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, projects)
+	if project.Slug != requested {
+		return c.Redirect(http.StatusMovedPermanently, "/admin/projects/by-slug/"+project.Slug)
+	}
+	setETag(c, project.Version)
+	return c.JSON(http.StatusOK, project)
 }
 
 // UpdateProject handles PUT /projects/:id
@@ -122,6 +288,11 @@ func (h *ProjectHandler) UpdateProject(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing project ID"})
 	}
 
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchError(c, err)
+	}
+
 	req := new(models.CreateProjectRequest)
 	if err := c.Bind(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
@@ -130,128 +301,389 @@ func (h *ProjectHandler) UpdateProject(c echo.Context) error {
 	ctx := context.Background()
 
 	// 1. Fetch the EXISTING project first to compare images
-	docRef := h.Client.Firestore.Collection("projects").Doc(id)
-	docSnap, err := docRef.Get(ctx)
+	existing, err := h.Projects.Get(ctx, id)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to parse existing project"})
 	}
 
-	var oldProject models.Project
-	if err := docSnap.DataTo(&oldProject); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to parse existing project"})
+	// The editor must have seen the latest version, otherwise we'd clobber someone else's changes
+	if expectedVersion != anyVersion && existing.Version != expectedVersion {
+		return h.versionConflict(c, http.StatusPreconditionFailed, existing)
 	}
 
-	// 2. Identify images to delete
+	return h.saveProject(c, existing, req, "update", nil)
+}
+
+// saveProject applies an edit request on top of the existing project and
+// stores it, cleaning up images the edit removed. Shared by PUT, PATCH and
+// revision restores; action is recorded in the revision history and extra
+// fields are added to the success response.
+func (h *ProjectHandler) saveProject(c echo.Context, existing *models.Project, req *models.CreateProjectRequest, action string, extra map[string]interface{}) error {
+	ctx := context.Background()
+	id := existing.ID
+	oldProject := *existing
+	uid, role := currentUser(c)
+
+	if existing.DeletedAt != nil {
+		return trashedError(c)
+	}
+
+	req.Status = models.NormalizeStatus(req.Status)
+	if errs, err := h.validateProject(ctx, req, existing); err != nil {
+		c.Logger().Errorf("Failed to load project settings: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to validate project"})
+	} else if errs != nil {
+		return validationError(c, errs)
+	}
+
+	// 2. Identify images to delete. Deduplicated images are tracked by ID,
+	// the media library keeps their files while anything else uses them.
 	newImageMap := make(map[string]bool)
+	newImageIDs := make(map[string]bool)
 	for _, img := range req.Images {
 		newImageMap[img.URL] = true
+		newImageIDs[img.ID] = true
 	}
 
-	bucketName := h.Config.FirebaseStorageBucket
-	bucket, err := h.Client.Storage.Bucket(bucketName)
-
-	c.Logger().Infof("DEBUG: Checking for deleted images. Old count: %d, New count: %d", len(oldProject.Images), len(req.Images))
-
-	if err == nil {
-		for _, oldImg := range oldProject.Images {
-			// Check if the old image exists in the new map
-			exists := newImageMap[oldImg.URL]
-			c.Logger().Infof("DEBUG: Checking image %s. Keep? %v", oldImg.URL, exists)
-
-			if !exists {
-				// Image removed! Determine the path to delete.
-				var objectPath string
-
-				if oldImg.StoragePath != "" {
-					objectPath = oldImg.StoragePath
-					c.Logger().Infof("DEBUG: Found StoragePath: %s", objectPath)
-				} else {
-					// Plan B: Fallback for legacy images
-					c.Logger().Infof("DEBUG: No StoragePath, trying URL parsing for %s", oldImg.URL)
-					parsedURL, parseErr := url.Parse(oldImg.URL)
-					if parseErr == nil {
-						parts := strings.Split(parsedURL.Path, "/o/")
-						if len(parts) > 1 {
-							decodedPath, decodeErr := url.QueryUnescape(parts[1])
-							if decodeErr == nil {
-								objectPath = decodedPath
-								c.Logger().Infof("DEBUG: Parsed path: %s", objectPath)
-							} else {
-								c.Logger().Errorf("DEBUG: Failed to decode path: %v", decodeErr)
-							}
-						} else {
-							c.Logger().Warnf("DEBUG: URL split failed, parts: %v", parts)
-						}
-					} else {
-						c.Logger().Errorf("DEBUG: URL parse failed: %v", parseErr)
-					}
-				}
+	var removedImages []models.ProjectImage
+	for _, oldImg := range oldProject.Images {
+		// Check if the old image exists in the new map
+		exists := newImageMap[oldImg.URL]
+		if oldImg.Hash != "" {
+			exists = newImageIDs[oldImg.ID]
+		}
+		if !exists {
+			removedImages = append(removedImages, oldImg)
+		}
+	}
 
-				// Execute Deletion if we found a path
-				if objectPath != "" {
-					go func(path string) {
-						c.Logger().Infof("DEBUG: Attempting to delete object at path: %s from bucket %s", path, bucketName)
-						if err := bucket.Object(path).Delete(context.Background()); err != nil {
-							c.Logger().Errorf("Failed to delete image %s: %v", path, err)
-						} else {
-							c.Logger().Infof("Successfully deleted orphaned image: %s", path)
-						}
-					}(objectPath)
-				} else {
-					c.Logger().Warnf("Could not determine path for deletion: %s", oldImg.URL)
-				}
-			}
+	oldImageIDs := make(map[string]bool)
+	for _, oldImg := range oldProject.Images {
+		oldImageIDs[oldImg.ID] = true
+	}
+	var addedImages []models.ProjectImage
+	for _, img := range req.Images {
+		if !oldImageIDs[img.ID] {
+			addedImages = append(addedImages, img)
+		}
+	}
+	// An image whose files are being deleted right now can no longer be linked
+	for _, img := range addedImages {
+		if deleting, err := h.Media.Deleting(ctx, img); err != nil {
+			c.Logger().Errorf("Failed to check the deletion of image %s: %v", img.ID, err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check images in storage"})
+		} else if deleting {
+			return c.JSON(http.StatusConflict, map[string]string{"error": fmt.Sprintf("Image %s is being deleted, upload it again", img.ID)})
 		}
-	} else {
-		c.Logger().Errorf("Failed to get bucket handle: %v", err)
 	}
 
 	// 3. Perform the Database Update
-	
+
 	// Determine correct cover image
 	finalCoverImage := req.CoverImage
 	if finalCoverImage == "" && len(req.Images) > 0 {
 		finalCoverImage = req.Images[0].URL
 	}
 
-	updates := []firestore.Update{
-		{Path: "title", Value: req.Title},
-		{Path: "description", Value: req.Description},
-		{Path: "location", Value: req.Location},
-		{Path: "category", Value: req.Category},
-		{Path: "tags", Value: req.Tags},
-		{Path: "status", Value: req.Status},
-		{Path: "images", Value: req.Images},
-		{Path: "coverImage", Value: finalCoverImage}, // Use calculated cover image
-		{Path: "imageGroups", Value: req.ImageGroups},
-		{Path: "hasTestimonial", Value: req.HasTestimonial},
-		{Path: "testimonial", Value: req.Testimonial},
-		{Path: "updatedAt", Value: time.Now()},
+	updated := *existing
+	updated.ID = id
+
+	// Slugs stay stable when the title changes; only an explicit edit renames them.
+	// Legacy projects without a slug get one generated from the title.
+	if req.Slug != "" || existing.Slug == "" {
+		slugSource := req.Slug
+		if slugSource == "" {
+			slugSource = req.Title
+		}
+		if slug.Make(slugSource) != existing.Slug {
+			newSlug, err := h.uniqueSlug(ctx, slugSource, id)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to generate slug"})
+			}
+			if newSlug != existing.Slug {
+				updated.PreviousSlugs = renameSlug(existing.PreviousSlugs, existing.Slug, newSlug)
+				updated.Slug = newSlug
+			}
+		}
+	}
+	updated.Title = req.Title
+	updated.Description = req.Description
+	updated.Location = req.Location
+	updated.Category = req.Category
+	updated.Tags = req.Tags
+	updated.Images = req.Images
+	updated.CoverImage = finalCoverImage // Use calculated cover image
+	updated.ImageGroups = req.ImageGroups
+	updated.HasTestimonial = req.HasTestimonial
+	updated.Testimonial = req.Testimonial
+	if req.Featured != nil {
+		updated.Featured = *req.Featured
+	}
+	if req.CompletedDate != nil {
+		updated.CompletedDate = *req.CompletedDate
+	}
+	updated.UpdatedAt = time.Now()
+
+	// Status changes must follow the workflow, as if the matching transition endpoint was called
+	if oldStatus := models.NormalizeStatus(existing.Status); req.Status != oldStatus {
+		t, ok := workflow.Between(oldStatus, req.Status)
+		if !ok {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error":   fmt.Sprintf("Cannot change status from %s to %s", oldStatus, req.Status),
+				"actions": workflow.Available(oldStatus, role),
+			})
+		}
+		if err := workflow.Apply(&updated, t.Action, role, uid, "", updated.UpdatedAt); err != nil {
+			return transitionError(c, err)
+		}
+	} else {
+		updated.Status = req.Status
 	}
 
-	_, err = docRef.Update(ctx, updates)
-	if err != nil {
+	if err := h.Projects.Update(ctx, &updated); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			// Someone saved between our read and write
+			current, getErr := h.Projects.Get(ctx, id)
+			if getErr != nil {
+				return projectLookupError(c, getErr)
+			}
+			return h.versionConflict(c, http.StatusConflict, current)
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update project"})
 	}
+	h.Search.Put(updated)
+	h.recordRevision(c, &oldProject, updated, action)
+
+	// 4. Count the new images as used before releasing the removed ones, the
+	// files of a removed image are queued for deletion once nothing uses them.
+	// Added images may have been removed before (and restored from a
+	// revision), so their queued deletions are cancelled first.
+	for _, img := range addedImages {
+		if err := h.Media.Reclaim(ctx, img); err != nil {
+			c.Logger().Errorf("Failed to cancel the deletion of image %s: %v", img.ID, err)
+		}
+		if err := h.Media.Retain(ctx, img, models.ProjectMediaRef(id, img.ID)); err != nil {
+			c.Logger().Errorf("Failed to record use of image %s: %v", img.ID, err)
+		}
+	}
+	for _, oldImg := range removedImages {
+		if err := h.Media.Release(ctx, oldImg, models.ProjectMediaRef(id, oldImg.ID)); err != nil {
+			c.Logger().Errorf("Failed to release image %s: %v", oldImg.URL, err)
+		}
+	}
 
 	// Update website settings timestamp if active or was active
-	if strings.ToLower(req.Status) == "active" || strings.ToLower(oldProject.Status) == "active" {
-		_, err := h.Client.Firestore.Collection(settingsCollection).Doc(websiteDocument).Set(ctx, map[string]interface{}{
-			"projectUpdatedAt": time.Now(),
-		}, firestore.MergeAll)
-		if err != nil {
-			c.Logger().Errorf("Failed to update projectUpdatedAt in settings: %v", err)
-		}
+	if workflow.AffectsPublicSite(oldProject.Status, updated.Status) {
+		h.touchProjectUpdatedAt(c)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
+	response := map[string]interface{}{
 		"id":      id,
 		"status":  "updated",
 		"message": "Project updated successfully",
-	})
+		"version": updated.Version,
+	}
+	for key, value := range extra {
+		response[key] = value
+	}
+
+	setETag(c, updated.Version)
+	return c.JSON(http.StatusOK, response)
+}
+
+// PatchProject handles PATCH /projects/:id
+// The body is an RFC 7396 merge patch (application/merge-patch+json, or plain
+// application/json) or an RFC 6902 JSON Patch (application/json-patch+json),
+// applied to the project's editable fields.
+func (h *ProjectHandler) PatchProject(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing project ID"})
+	}
+
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchError(c, err)
+	}
+
+	var apply func(doc, p []byte) ([]byte, error)
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case patch.MergePatchContentType, echo.MIMEApplicationJSON:
+		apply = patch.Merge
+	case patch.JSONPatchContentType:
+		apply = patch.Apply
+	default:
+		c.Response().Header().Set("Accept-Patch", patch.MergePatchContentType+", "+patch.JSONPatchContentType)
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": "Unsupported patch format"})
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	ctx := context.Background()
+
+	// 1. Fetch the current project the patch applies to
+	existing, err := h.Projects.Get(ctx, id)
+	if err != nil {
+		return projectLookupError(c, err)
+	}
+	if expectedVersion != anyVersion && existing.Version != expectedVersion {
+		return h.versionConflict(c, http.StatusPreconditionFailed, existing)
+	}
+
+	// 2. Apply the patch to the editable view of the project
+	doc, err := json.Marshal(existing.EditableFields())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to encode project"})
+	}
+	patched, err := apply(doc, body)
+	if err != nil {
+		return patchError(c, err)
+	}
+
+	// 3. Decode strictly so patches touching read-only fields (createdAt, version, ...) fail loudly
+	req := new(models.CreateProjectRequest)
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Patched project is invalid: " + err.Error()})
+	}
+	if req.ID != id {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Project ID cannot be changed"})
+	}
+
+	return h.saveProject(c, existing, req, "patch", nil)
+}
+
+// validateProject checks req against the categories and tags configured in
+// the settings/projects document. previous is nil when creating.
+func (h *ProjectHandler) validateProject(ctx context.Context, req *models.CreateProjectRequest, previous *models.Project) (validation.Errors, error) {
+	settings, err := h.Settings.Get(ctx, repository.ProjectsDocument)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	return validation.Project(req, validation.RulesFromSettings(settings), previous), nil
+}
+
+// validationError reports every invalid field with 422
+func validationError(c echo.Context, errs validation.Errors) error {
+	return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{"errors": errs})
+}
+
+// patchError maps patch failures onto HTTP responses
+func patchError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, patch.ErrTestFailed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, patch.ErrInvalidPath):
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
+
+// TransitionProject returns the handler for POST /projects/:id/<action>,
+// moving a project through the status workflow. The optional JSON body
+// {"note": "..."} is kept in the status history, e.g. a rejection reason.
+func (h *ProjectHandler) TransitionProject(action string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if id == "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing project ID"})
+		}
+
+		// If-Match is optional here, the transition itself checks the current status
+		expectedVersion, err := ifMatchVersion(c)
+		if errors.Is(err, errMissingIfMatch) {
+			expectedVersion = anyVersion
+		} else if err != nil {
+			return ifMatchError(c, err)
+		}
+
+		var req struct {
+			Note string `json:"note"`
+		}
+		if c.Request().ContentLength > 0 {
+			if err := c.Bind(&req); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+			}
+		}
+
+		ctx := context.Background()
+		uid, role := currentUser(c)
+
+		// 1. Fetch the project and make sure the caller saw its latest version
+		project, err := h.Projects.Get(ctx, id)
+		if err != nil {
+			return projectLookupError(c, err)
+		}
+		if expectedVersion != anyVersion && project.Version != expectedVersion {
+			return h.versionConflict(c, http.StatusPreconditionFailed, project)
+		}
+		if project.DeletedAt != nil {
+			return trashedError(c)
+		}
+		before := *project
+
+		// 2. Apply the transition and save
+		now := time.Now()
+		if err := workflow.Apply(project, action, role, uid, strings.TrimSpace(req.Note), now); err != nil {
+			return transitionError(c, err)
+		}
+		if project.Status == models.StatusActive && models.NormalizeStatus(before.Status) != models.StatusActive {
+			if errs := validation.Publishable(*project); errs != nil {
+				return validationError(c, errs)
+			}
+		}
+		project.UpdatedAt = now
+
+		if err := h.Projects.Update(ctx, project); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				current, getErr := h.Projects.Get(ctx, id)
+				if getErr != nil {
+					return projectLookupError(c, getErr)
+				}
+				return h.versionConflict(c, http.StatusConflict, current)
+			}
+			return projectLookupError(c, err)
+		}
+		h.Search.Put(*project)
+		h.recordRevision(c, &before, *project, action)
+
+		// 3. Going live or coming down changes the public website
+		if workflow.AffectsPublicSite(before.Status, project.Status) {
+			h.touchProjectUpdatedAt(c)
+		}
+
+		setETag(c, project.Version)
+		return c.JSON(http.StatusOK, project)
+	}
+}
+
+// transitionError maps workflow errors onto HTTP responses
+func transitionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, workflow.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Your role cannot perform this status change"})
+	case errors.Is(err, workflow.ErrInvalidTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "This action is not allowed from the project's current status"})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 }
 
 // DeleteProject handles DELETE /projects/:id
+// The project moves to the trash, hidden everywhere public, until it is
+// restored or purged once Config.TrashRetention has passed.
 func (h *ProjectHandler) DeleteProject(c echo.Context) error {
 	id := c.Param("id")
 	if id == "" {
@@ -259,80 +691,226 @@ func (h *ProjectHandler) DeleteProject(c echo.Context) error {
 	}
 
 	ctx := context.Background()
+	uid, _ := currentUser(c)
 
-	// 1. Fetch the project first so we know which images to delete
-	docRef := h.Client.Firestore.Collection("projects").Doc(id)
-	docSnap, err := docRef.Get(ctx)
+	// 1. Fetch the project
+	existing, err := h.Projects.Get(ctx, id)
 	if err != nil {
-		if strings.Contains(err.Error(), "NotFound") {
+		if errors.Is(err, repository.ErrNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
 		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch project details"})
 	}
+	if existing.DeletedAt != nil {
+		return trashedError(c)
+	}
 
-	var project models.Project
-	if err := docSnap.DataTo(&project); err != nil {
-		c.Logger().Errorf("Failed to parse project data for deletion: %v", err)
-		// We continue even if parsing fails, to at least try deleting the document
-	}
-
-	// 2. Delete images from Storage
-	// We do this asynchronously or simply iterate. Since it's a delete op, best effort is usually acceptable.
-	bucketName := h.Config.FirebaseStorageBucket
-	bucket, err := h.Client.Storage.Bucket(bucketName)
-
-	if err == nil && len(project.Images) > 0 {
-		for _, img := range project.Images {
-			// Use StoragePath if available, otherwise try to parse URL
-			var objectPath string
-			if img.StoragePath != "" {
-				objectPath = img.StoragePath
-			} else {
-				// Parse URL logic (fallback)
-				parsedURL, parseErr := url.Parse(img.URL)
-				if parseErr == nil {
-					parts := strings.Split(parsedURL.Path, "/o/")
-					if len(parts) > 1 {
-						decodedPath, decodeErr := url.QueryUnescape(parts[1])
-						if decodeErr == nil {
-							objectPath = decodedPath
-						}
-					}
-				}
-			}
+	// 2. Move it to the trash, keeping its images until it is purged
+	now := time.Now()
+	trashed := *existing
+	trashed.DeletedAt = &now
+	trashed.DeletedBy = uid
+	if err := h.Projects.Update(ctx, &trashed); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Project was changed while deleting, please try again"})
+		}
+		c.Logger().Errorf("Failed to move project to trash: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete project"})
+	}
+	h.Search.Remove(id)
+	h.recordRevision(c, existing, trashed, "trash")
 
-			if objectPath != "" {
-				// Run deletes in background to keep response fast
-				go func(p string) {
-					if err := bucket.Object(p).Delete(context.Background()); err != nil {
-						c.Logger().Warnf("Failed to delete image during project cleanup: %s. Error: %v", p, err)
-					}
-				}(objectPath)
-			}
+	// Update website settings timestamp if deleted project was active
+	if workflow.AffectsPublicSite(existing.Status, "") {
+		h.touchProjectUpdatedAt(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":         id,
+		"status":     "trashed",
+		"message":    "Project moved to the trash",
+		"purgeAfter": now.Add(h.Config.TrashRetention),
+	})
+}
+
+// touchProjectUpdatedAt records that published project data changed and
+// queues an auto-publish when it is enabled
+func (h *ProjectHandler) touchProjectUpdatedAt(c echo.Context) {
+	err := h.Settings.Merge(context.Background(), repository.WebsiteDocument, map[string]interface{}{
+		"projectUpdatedAt": time.Now(),
+	})
+	if err != nil {
+		c.Logger().Errorf("Failed to update projectUpdatedAt in settings: %v", err)
+	}
+	if err := h.Scheduler.ProjectsChanged(context.Background()); err != nil {
+		c.Logger().Errorf("Failed to queue auto-publish: %v", err)
+	}
+}
+
+// imageObjectPath returns the storage path of an image, falling back to
+// parsing the download URL for legacy images saved without a StoragePath
+func imageObjectPath(img models.ProjectImage) string {
+	if img.StoragePath != "" {
+		return img.StoragePath
+	}
+	return blob.PathFromURL(img.URL)
+}
+
+// versionConflict rejects a stale edit, returning the server's current copy
+// so the editor can reapply their changes on top of it
+func (h *ProjectHandler) versionConflict(c echo.Context, status int, current *models.Project) error {
+	setETag(c, current.Version)
+	return c.JSON(status, map[string]interface{}{
+		"error":   "Project was changed by someone else, reload and reapply your edits",
+		"current": current,
+	})
+}
+
+// projectLookupError maps a failed (or hidden) project lookup to a response.
+// A nil error means the project exists but must not be shown, which is a 404 too.
+func projectLookupError(c echo.Context, err error) error {
+	if err == nil || errors.Is(err, repository.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch project"})
+}
+
+// uniqueSlug slugifies text and appends -2, -3, ... until no other project
+// uses the slug, either currently or as a redirecting previous slug
+func (h *ProjectHandler) uniqueSlug(ctx context.Context, text string, projectID string) (string, error) {
+	base := slug.Make(text)
+	if base == "" {
+		base = "project"
+	}
+
+	candidate := base
+	for n := 2; ; n++ {
+		existing, err := h.Projects.FindBySlug(ctx, candidate)
+		if errors.Is(err, repository.ErrNotFound) {
+			return candidate, nil
 		}
-	} else if err != nil {
-		c.Logger().Errorf("Failed to access storage bucket: %v", err)
+		if err != nil {
+			return "", err
+		}
+		if projectID != "" && existing.ID == projectID {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// renameSlug records oldSlug as a redirect and makes sure newSlug is no longer one
+func renameSlug(previous []string, oldSlug, newSlug string) []string {
+	var result []string
+	for _, s := range previous {
+		if s != newSlug && s != oldSlug {
+			result = append(result, s)
+		}
+	}
+	if oldSlug != "" {
+		result = append(result, oldSlug)
 	}
+	return result
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
 
-	// 3. Delete the Firestore Document
-	if _, err := docRef.Delete(ctx); err != nil {
-		c.Logger().Errorf("Failed to delete project document: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete project document"})
+// parseListOptions reads pagination, filter and sort query parameters:
+// limit, cursor, category, tags (comma separated), tagMatch (any|all), featured,
+// location, completedFrom, completedTo, sort and order (asc|desc)
+func parseListOptions(c echo.Context) (repository.ListOptions, error) {
+	opts := repository.ListOptions{
+		Limit:         defaultPageSize,
+		Cursor:        c.QueryParam("cursor"),
+		Category:      c.QueryParam("category"),
+		Location:      c.QueryParam("location"),
+		CompletedFrom: c.QueryParam("completedFrom"),
+		CompletedTo:   c.QueryParam("completedTo"),
+		SortBy:        c.QueryParam("sort"),
 	}
 
-	// Update website settings timestamp if deleted project was active
-	if strings.ToLower(project.Status) == "active" {
-		_, err := h.Client.Firestore.Collection(settingsCollection).Doc(websiteDocument).Set(ctx, map[string]interface{}{
-			"projectUpdatedAt": time.Now(),
-		}, firestore.MergeAll)
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxPageSize {
+			return opts, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		opts.Limit = n
+	}
+
+	if tags := c.QueryParam("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				opts.Tags = append(opts.Tags, tag)
+			}
+		}
+	}
+	switch c.QueryParam("tagMatch") {
+	case "", "any":
+	case "all":
+		opts.MatchAllTags = true
+	default:
+		return opts, errors.New("tagMatch must be 'any' or 'all'")
+	}
+
+	if featured := c.QueryParam("featured"); featured != "" {
+		value, err := strconv.ParseBool(featured)
 		if err != nil {
-			c.Logger().Errorf("Failed to update projectUpdatedAt in settings: %v", err)
+			return opts, errors.New("featured must be true or false")
 		}
+		opts.Featured = &value
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"id":      id,
-		"status":  "deleted",
-		"message": "Project and associated resources deleted successfully",
-	})
-}
\ No newline at end of file
+	for _, date := range []string{opts.CompletedFrom, opts.CompletedTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return opts, errors.New("completedFrom and completedTo must be dates in YYYY-MM-DD format")
+		}
+	}
+
+	if opts.SortBy != "" && !repository.ValidSortField(opts.SortBy) {
+		return opts, errors.New("sort must be one of createdAt, updatedAt, completedDate, title")
+	}
+	// Dates default to newest first, titles to A-Z
+	switch c.QueryParam("order") {
+	case "":
+		opts.Ascending = opts.SortBy == repository.SortTitle
+	case "asc":
+		opts.Ascending = true
+	case "desc":
+	default:
+		return opts, errors.New("order must be 'asc' or 'desc'")
+	}
+
+	return opts, nil
+}
+
+// parseSearchParams reads the q and limit query parameters of search endpoints
+func parseSearchParams(c echo.Context) (string, int, error) {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return "", 0, errors.New("q is required")
+	}
+
+	limit := defaultPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPageSize {
+			return "", 0, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		limit = n
+	}
+	return query, limit, nil
+}
+
+// listError maps repository listing failures to a response
+func listError(c echo.Context, err error) error {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch projects"})
+}
@@ -0,0 +1,615 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/config"
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/media"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/patch"
+	"github.com/networkcaretaker/garden_app/backend/internal/publish"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+	"github.com/networkcaretaker/garden_app/backend/internal/search"
+	"github.com/networkcaretaker/garden_app/backend/internal/workflow"
+)
+
+// testRoleHeader sets the caller's role in tests, standing in for the ID token
+const testRoleHeader = "X-Test-Role"
+
+// projectServer routes the admin project endpoints to a handler backed by
+// in-memory repositories
+type projectServer struct {
+	echo      *echo.Echo
+	handler   *ProjectHandler
+	projects  *repository.MemoryProjectRepository
+	revisions *repository.MemoryRevisionRepository
+	settings  *repository.MemorySettingsRepository
+	jobs      *repository.MemoryJobRepository
+	blobs     *blob.LocalStore
+}
+
+func newProjectServer(t *testing.T) *projectServer {
+	t.Helper()
+	blobs, err := blob.NewLocalStore(t.TempDir(), "http://localhost/storage")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	projects := repository.NewMemoryProjectRepository()
+	revisions := repository.NewMemoryRevisionRepository()
+	settings := repository.NewMemorySettingsRepository()
+	jobRepo := repository.NewMemoryJobRepository()
+	queue := jobs.NewQueue(jobRepo)
+	publisher := publish.NewPublisher(projects, revisions, settings, repository.NewMemoryReleaseRepository(), blobs)
+	h := NewProjectHandler(projects, revisions, settings, blobs, search.NewIndex(),
+		media.NewLibrary(repository.NewMemoryMediaRepository(), blobs, queue),
+		publish.NewScheduler(publisher, queue, 0), &config.Config{})
+
+	e := echo.New()
+	e.GET("/projects", h.GetProjects)
+	e.GET("/projects/:id", h.GetProject)
+	e.GET("/projects/by-slug/:slug", h.GetProjectBySlug)
+	admin := e.Group("/admin", func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("uid", "test-user")
+			c.Set("role", c.Request().Header.Get(testRoleHeader))
+			return next(c)
+		}
+	})
+	admin.GET("/projects", h.GetAdminProjects)
+	admin.GET("/projects/trash", h.GetTrashedProjects)
+	admin.POST("/projects", h.CreateProject)
+	admin.GET("/projects/:id", h.GetAdminProject)
+	admin.PUT("/projects/:id", h.UpdateProject)
+	admin.PATCH("/projects/:id", h.PatchProject)
+	admin.POST("/projects/:id/restore", h.RestoreProject)
+	admin.GET("/projects/:id/revisions", h.ListRevisions)
+	admin.GET("/projects/:id/revisions/:rev", h.GetRevision)
+	admin.GET("/projects/:id/revisions/:rev/diff", h.DiffRevision)
+	admin.POST("/projects/:id/revisions/:rev/restore", h.RestoreRevision)
+	admin.DELETE("/projects/:id", h.DeleteProject)
+	for _, tr := range workflow.Transitions {
+		admin.POST("/projects/:id/"+tr.Action, h.TransitionProject(tr.Action))
+	}
+	return &projectServer{echo: e, handler: h, projects: projects, revisions: revisions, settings: settings, jobs: jobRepo, blobs: blobs}
+}
+
+// do sends a request with a JSON body, unless header sets another Content-Type
+func (s *projectServer) do(method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+// seed stores a project directly, bypassing validation like legacy data does
+func (s *projectServer) seed(t *testing.T, p models.Project) models.Project {
+	t.Helper()
+	if err := s.projects.Create(context.Background(), &p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return p
+}
+
+func (s *projectServer) stored(t *testing.T, id string) *models.Project {
+	t.Helper()
+	p, err := s.projects.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", id, err)
+	}
+	return p
+}
+
+func decodeProject(t *testing.T, rec *httptest.ResponseRecorder) models.Project {
+	t.Helper()
+	var p models.Project
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return p
+}
+
+func TestCreateProject(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		body       string
+		wantStatus int
+	}{
+		{"draft", "", `{"id":"patio","title":"New patio","category":"hardscape"}`, http.StatusCreated},
+		{"generated ID", "", `{"title":"New patio","category":"hardscape"}`, http.StatusCreated},
+		{"active by an admin", models.RoleAdmin, `{"id":"patio","title":"New patio","category":"hardscape","status":"active"}`, http.StatusCreated},
+		{"active by an editor", models.RoleEditor, `{"id":"patio","title":"New patio","category":"hardscape","status":"active"}`, http.StatusForbidden},
+		{"missing category", "", `{"id":"patio","title":"New patio"}`, http.StatusUnprocessableEntity},
+		{"taken ID", "", `{"id":"existing","title":"New patio","category":"hardscape"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newProjectServer(t)
+			s.seed(t, models.Project{ID: "existing", Title: "Existing", Status: models.StatusDraft})
+
+			rec := s.do(http.MethodPost, "/admin/projects", tt.body, map[string]string{testRoleHeader: tt.role})
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			if etag := rec.Header().Get("ETag"); etag != `"1"` {
+				t.Errorf("ETag = %s, want \"1\"", etag)
+			}
+			if p := decodeProject(t, rec); p.Slug != "new-patio" {
+				t.Errorf("slug = %q, want new-patio", p.Slug)
+			}
+		})
+	}
+}
+
+// listedIDs decodes a project listing into its IDs, in order
+func listedIDs(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	t.Helper()
+	var list ListResponse[map[string]interface{}]
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	ids := []string{}
+	for _, item := range list.Items {
+		id, _ := item["id"].(string)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestProjectListingsByAudience(t *testing.T) {
+	s := newProjectServer(t)
+	trashedAt := time.Now()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, p := range []models.Project{
+		{ID: "live", Status: models.StatusActive},
+		{ID: "draft", Status: models.StatusDraft},
+		{ID: "review", Status: models.StatusReview},
+		{ID: "archived", Status: models.StatusArchived},
+		{ID: "trashed", Status: models.StatusActive, DeletedAt: &trashedAt},
+	} {
+		p.Title = p.ID
+		p.CreatedAt = created.Add(time.Duration(-i) * time.Hour)
+		p.StatusHistory = []models.StatusChange{{Action: "create", To: p.Status, By: "owner-uid"}}
+		s.seed(t, p)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		want   []string
+	}{
+		{"public listing shows live projects only", "/projects", []string{"live"}},
+		{"public listing ignores a status filter", "/projects?status=draft", []string{"live"}},
+		{"admin listing shows every status", "/admin/projects", []string{"live", "draft", "review", "archived"}},
+		{"admin listing filters by status", "/admin/projects?status=draft", []string{"draft"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := s.do(http.MethodGet, tt.target, "", nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+			}
+			if got := listedIDs(t, rec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The public projection leaves out internal fields
+	rec := s.do(http.MethodGet, "/projects", "", nil)
+	for _, field := range []string{`"status"`, `"version"`, `"statusHistory"`, "owner-uid"} {
+		if strings.Contains(rec.Body.String(), field) {
+			t.Errorf("public listing exposes %s: %s", field, rec.Body.String())
+		}
+	}
+}
+
+func TestGetSingleProject(t *testing.T) {
+	s := newProjectServer(t)
+	s.seed(t, models.Project{ID: "live", Title: "Live", Slug: "rose-garden", PreviousSlugs: []string{"roses"}, Status: models.StatusActive})
+	s.seed(t, models.Project{ID: "draft", Title: "Draft", Slug: "new-pond", Status: models.StatusDraft})
+
+	tests := []struct {
+		name         string
+		target       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"live by ID", "/projects/live", http.StatusOK, ""},
+		{"draft by ID", "/projects/draft", http.StatusNotFound, ""},
+		{"missing ID", "/projects/missing", http.StatusNotFound, ""},
+		{"current slug", "/projects/by-slug/rose-garden", http.StatusOK, ""},
+		{"previous slug redirects", "/projects/by-slug/roses", http.StatusMovedPermanently, "/projects/by-slug/rose-garden"},
+		{"draft slug", "/projects/by-slug/new-pond", http.StatusNotFound, ""},
+		{"admin sees drafts", "/admin/projects/draft", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := s.do(http.MethodGet, tt.target, "", nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if location := rec.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", location, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestCreateProjectSlugs(t *testing.T) {
+	s := newProjectServer(t)
+	s.seed(t, models.Project{ID: "old", Title: "Old", Slug: "patio", Status: models.StatusDraft})
+	s.seed(t, models.Project{ID: "renamed", Title: "Renamed", Slug: "terrace", PreviousSlugs: []string{"patio-2"}, Status: models.StatusDraft})
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"from the title", `{"title":"Rose Garden!","category":"planting"}`, "rose-garden"},
+		{"requested slug", `{"title":"Whatever","slug":"My Pond","category":"water"}`, "my-pond"},
+		{"skips current and previous slugs", `{"title":"Patio","category":"hardscape"}`, "patio-3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := s.do(http.MethodPost, "/admin/projects", tt.body, nil)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+			}
+			if p := decodeProject(t, rec); p.Slug != tt.want {
+				t.Errorf("slug = %q, want %q", p.Slug, tt.want)
+			}
+		})
+	}
+}
+
+func TestListQueryParameters(t *testing.T) {
+	s := newProjectServer(t)
+	for i, title := range []string{"Beech hedge", "Alpine bed", "Cedar deck"} {
+		s.seed(t, models.Project{
+			ID:        fmt.Sprintf("p%d", i),
+			Title:     title,
+			Status:    models.StatusActive,
+			Tags:      []string{"tag" + fmt.Sprint(i%2)},
+			Featured:  i == 2,
+			CreatedAt: time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		want       []string
+	}{
+		{"", http.StatusOK, []string{"p2", "p1", "p0"}},
+		{"order=asc", http.StatusOK, []string{"p0", "p1", "p2"}},
+		{"sort=title", http.StatusOK, []string{"p1", "p0", "p2"}},
+		{"sort=title&order=desc", http.StatusOK, []string{"p2", "p0", "p1"}},
+		{"tags=tag0", http.StatusOK, []string{"p2", "p0"}},
+		{"tags=tag0,tag1&tagMatch=all", http.StatusOK, []string{}},
+		{"featured=true", http.StatusOK, []string{"p2"}},
+		{"limit=0", http.StatusBadRequest, nil},
+		{"limit=many", http.StatusBadRequest, nil},
+		{"sort=colour", http.StatusBadRequest, nil},
+		{"order=up", http.StatusBadRequest, nil},
+		{"tagMatch=some", http.StatusBadRequest, nil},
+		{"featured=maybe", http.StatusBadRequest, nil},
+		{"completedFrom=01/02/2024", http.StatusBadRequest, nil},
+		{"cursor=bogus", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := s.do(http.MethodGet, "/projects?"+tt.query, "", nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.want == nil {
+				return
+			}
+			if got := listedIDs(t, rec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListPaging(t *testing.T) {
+	s := newProjectServer(t)
+	for i := 0; i < 5; i++ {
+		s.seed(t, models.Project{ID: fmt.Sprintf("p%d", i), Title: "Project", Status: models.StatusActive,
+			CreatedAt: time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC)})
+	}
+
+	var got []string
+	target := "/projects?limit=2"
+	for page := 0; page < 5; page++ {
+		rec := s.do(http.MethodGet, target, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+		var list ListResponse[models.PublicProject]
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		for _, p := range list.Items {
+			got = append(got, p.ID)
+		}
+		if list.NextCursor == "" {
+			break
+		}
+		target = "/projects?limit=2&cursor=" + list.NextCursor
+	}
+	if want := []string{"p4", "p3", "p2", "p1", "p0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pages gave %v, want %v", got, want)
+	}
+}
+
+func TestPatchProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		check       func(t *testing.T, p *models.Project)
+	}{
+		{
+			name:        "merge patch",
+			contentType: patch.MergePatchContentType,
+			body:        `{"title":"Sunny patio","tags":["stone"]}`,
+			wantStatus:  http.StatusOK,
+			check: func(t *testing.T, p *models.Project) {
+				if p.Title != "Sunny patio" || len(p.Tags) != 1 || p.Tags[0] != "stone" || p.Location != "Leeds" {
+					t.Errorf("got title %q, tags %v, location %q", p.Title, p.Tags, p.Location)
+				}
+			},
+		},
+		{
+			name:        "merge patch removes a field",
+			contentType: patch.MergePatchContentType,
+			body:        `{"location":null}`,
+			wantStatus:  http.StatusOK,
+			check: func(t *testing.T, p *models.Project) {
+				if p.Location != "" || p.Title != "Patio" {
+					t.Errorf("got title %q, location %q", p.Title, p.Location)
+				}
+			},
+		},
+		{
+			name:        "JSON patch",
+			contentType: patch.JSONPatchContentType,
+			body:        `[{"op":"test","path":"/title","value":"Patio"},{"op":"replace","path":"/title","value":"Big patio"}]`,
+			wantStatus:  http.StatusOK,
+			check: func(t *testing.T, p *models.Project) {
+				if p.Title != "Big patio" {
+					t.Errorf("title = %q, want Big patio", p.Title)
+				}
+			},
+		},
+		{
+			name:        "failed JSON patch test",
+			contentType: patch.JSONPatchContentType,
+			body:        `[{"op":"test","path":"/title","value":"Pond"},{"op":"replace","path":"/title","value":"Big pond"}]`,
+			wantStatus:  http.StatusConflict,
+		},
+		{
+			name:        "read-only field",
+			contentType: patch.MergePatchContentType,
+			body:        `{"version":10}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "changing the ID",
+			contentType: patch.MergePatchContentType,
+			body:        `{"id":"pond"}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "removing the category",
+			contentType: patch.MergePatchContentType,
+			body:        `{"category":""}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "unsupported format",
+			contentType: "text/plain",
+			body:        `title=Pond`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newProjectServer(t)
+			s.seed(t, models.Project{ID: "patio", Title: "Patio", Category: "hardscape", Location: "Leeds", Status: models.StatusDraft})
+
+			rec := s.do(http.MethodPatch, "/admin/projects/patio", tt.body, map[string]string{
+				echo.HeaderContentType: tt.contentType,
+				"If-Match":             `"1"`,
+			})
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			stored := s.stored(t, "patio")
+			if tt.check == nil {
+				if stored.Version != 1 {
+					t.Errorf("version = %d, a rejected patch was saved", stored.Version)
+				}
+				return
+			}
+			if stored.Version != 2 {
+				t.Errorf("version = %d, want 2", stored.Version)
+			}
+			tt.check(t, stored)
+		})
+	}
+}
+
+func TestPatchLegacyProjectWithoutCategory(t *testing.T) {
+	s := newProjectServer(t)
+	s.seed(t, models.Project{ID: "legacy", Title: "Old garden", Status: models.StatusActive})
+
+	rec := s.do(http.MethodPatch, "/admin/projects/legacy", `{"title":"Old walled garden"}`, map[string]string{
+		echo.HeaderContentType: patch.MergePatchContentType,
+		"If-Match":             `"1"`,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if stored := s.stored(t, "legacy"); stored.Title != "Old walled garden" {
+		t.Errorf("title = %q, the patch was not saved", stored.Title)
+	}
+}
+
+func TestPatchKeepsRetiredTags(t *testing.T) {
+	s := newProjectServer(t)
+	if err := s.settings.Merge(context.Background(), "projects", map[string]interface{}{
+		"categories": []interface{}{"hardscape"},
+		"tags":       []interface{}{"stone"},
+	}); err != nil {
+		t.Fatalf("Merge settings: %v", err)
+	}
+	s.seed(t, models.Project{ID: "patio", Title: "Patio", Category: "hardscape", Tags: []string{"decking"}, Status: models.StatusDraft})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"retired tag kept", `{"title":"Big patio","tags":["decking","stone"]}`, http.StatusOK},
+		{"retired tag added", `{"tags":["decking","stone","gravel"]}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version := s.stored(t, "patio").Version
+			rec := s.do(http.MethodPatch, "/admin/projects/patio", tt.body, map[string]string{
+				echo.HeaderContentType: patch.MergePatchContentType,
+				"If-Match":             fmt.Sprintf(`"%d"`, version),
+			})
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTransitionProject(t *testing.T) {
+	tests := []struct {
+		name       string
+		project    models.Project
+		action     string
+		role       string
+		ifMatch    string
+		wantStatus int
+		wantState  string // Project status afterwards
+	}{
+		{"editor submits a draft", models.Project{Status: models.StatusDraft, Category: "hardscape"}, "submit", models.RoleEditor, "", http.StatusOK, models.StatusReview},
+		{"admin approves", models.Project{Status: models.StatusReview, Category: "hardscape"}, "approve", models.RoleAdmin, `"1"`, http.StatusOK, models.StatusActive},
+		{"editor cannot approve", models.Project{Status: models.StatusReview, Category: "hardscape"}, "approve", models.RoleEditor, "", http.StatusForbidden, models.StatusReview},
+		{"admin publishes a draft", models.Project{Status: models.StatusDraft, Category: "hardscape"}, "publish", models.RoleAdmin, "", http.StatusOK, models.StatusActive},
+		{"approve outside review", models.Project{Status: models.StatusDraft, Category: "hardscape"}, "approve", models.RoleAdmin, "", http.StatusConflict, models.StatusDraft},
+		{"admin rejects", models.Project{Status: models.StatusReview, Category: "hardscape"}, "reject", models.RoleAdmin, "", http.StatusOK, models.StatusDraft},
+		{"unpublish", models.Project{Status: models.StatusActive, Category: "hardscape"}, "unpublish", models.RoleAdmin, "", http.StatusOK, models.StatusDraft},
+		{"editor reopens", models.Project{Status: models.StatusArchived}, "reopen", models.RoleEditor, "", http.StatusOK, models.StatusDraft},
+		{"publishing needs a category", models.Project{Status: models.StatusDraft}, "publish", models.RoleAdmin, "", http.StatusUnprocessableEntity, models.StatusDraft},
+		{"archiving needs no category", models.Project{Status: models.StatusDraft}, "archive", models.RoleAdmin, "", http.StatusOK, models.StatusArchived},
+		{"stale If-Match", models.Project{Status: models.StatusDraft, Category: "hardscape"}, "submit", models.RoleEditor, `"7"`, http.StatusPreconditionFailed, models.StatusDraft},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newProjectServer(t)
+			tt.project.ID = "patio"
+			tt.project.Title = "Patio"
+			s.seed(t, tt.project)
+
+			header := map[string]string{testRoleHeader: tt.role}
+			if tt.ifMatch != "" {
+				header["If-Match"] = tt.ifMatch
+			}
+			rec := s.do(http.MethodPost, "/admin/projects/patio/"+tt.action, `{"note":"checked on site"}`, header)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			stored := s.stored(t, "patio")
+			if stored.Status != tt.wantState {
+				t.Errorf("status = %s, want %s", stored.Status, tt.wantState)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if stored.Version != 1 {
+					t.Errorf("version = %d, a rejected transition was saved", stored.Version)
+				}
+				return
+			}
+			if rec.Header().Get("ETag") != `"2"` {
+				t.Errorf("ETag = %s, want \"2\"", rec.Header().Get("ETag"))
+			}
+			last := stored.StatusHistory[len(stored.StatusHistory)-1]
+			if last.Action != tt.action || last.To != tt.wantState || last.By != "test-user" || last.Note != "checked on site" {
+				t.Errorf("history entry = %+v", last)
+			}
+			if tt.wantState == models.StatusActive && stored.PublishedAt == nil {
+				t.Errorf("publishedAt was not set")
+			}
+		})
+	}
+}
+
+func TestUpdateReaddsRemovedImage(t *testing.T) {
+	readd := `{"id":"patio","title":"Stone patio","category":"hardscape",
+	"images":[{"id":"a","url":"http://localhost/storage/projects/patio/a.jpg","storagePath":"projects/patio/a.jpg"}]}`
+
+	tests := []struct {
+		name        string
+		claim       bool
+		wantStatus  int
+		wantDeletes []string
+	}{
+		{"deletion queued", false, http.StatusOK, []string{"projects/patio/b.jpg"}},
+		{"deletion running", true, http.StatusConflict, []string{"projects/patio/a.jpg", "projects/patio/b.jpg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRevisedProject(t)
+			if tt.claim {
+				if _, err := s.jobs.ClaimDue(context.Background(), time.Now().Add(time.Second), 10, time.Minute); err != nil {
+					t.Fatalf("ClaimDue: %v", err)
+				}
+			}
+
+			rec := s.do(http.MethodPut, "/admin/projects/patio", readd, map[string]string{"If-Match": `"2"`})
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := s.queuedDeletes(t); !reflect.DeepEqual(got, tt.wantDeletes) {
+				t.Errorf("queued deletions = %v, want %v", got, tt.wantDeletes)
+			}
+			wantImages := 1
+			if tt.wantStatus != http.StatusOK {
+				wantImages = 0
+			}
+			if got := len(s.stored(t, "patio").Images); got != wantImages {
+				t.Errorf("stored %d images, want %d", got, wantImages)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+	"github.com/networkcaretaker/garden_app/backend/internal/revision"
+)
+
+// recordRevision stores a snapshot of a project that was just saved.
+// previous is the project before the save, nil when it was just created.
+// History is best effort: a failure is logged but does not undo the save.
+func (h *ProjectHandler) recordRevision(c echo.Context, previous *models.Project, saved models.Project, action string) {
+	var changes []string
+	if previous != nil {
+		changes = revision.Summary(revision.Diff(*previous, saved))
+	}
+	uid, _ := currentUser(c)
+
+	rev := &models.Revision{
+		ProjectID: saved.ID,
+		Number:    saved.Version,
+		Action:    action,
+		By:        uid,
+		At:        time.Now(),
+		Changes:   changes,
+		Snapshot:  &saved,
+	}
+	if err := h.Revisions.Add(context.Background(), rev); err != nil {
+		c.Logger().Errorf("Failed to record revision %d of project %s: %v", saved.Version, saved.ID, err)
+	}
+}
+
+// ListRevisions handles GET /admin/projects/:id/revisions
+// Revisions are listed newest first without their snapshots; pass the
+// nextCursor back as ?cursor= for older ones.
+func (h *ProjectHandler) ListRevisions(c echo.Context) error {
+	id := c.Param("id")
+	limit := defaultPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPageSize {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxPageSize)})
+		}
+		limit = n
+	}
+	var before int64
+	if raw := c.QueryParam("cursor"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		}
+		before = n
+	}
+
+	ctx := context.Background()
+	if _, err := h.Projects.Get(ctx, id); err != nil {
+		return projectLookupError(c, err)
+	}
+
+	// Fetch one extra revision to know whether another page exists
+	revisions, err := h.Revisions.List(ctx, id, before, limit+1)
+	if err != nil {
+		c.Logger().Errorf("Failed to list revisions: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list revisions"})
+	}
+	nextCursor := ""
+	if len(revisions) > limit {
+		revisions = revisions[:limit]
+		nextCursor = strconv.FormatInt(revisions[limit-1].Number, 10)
+	}
+	for i := range revisions {
+		revisions[i].Snapshot = nil
+	}
+
+	return c.JSON(http.StatusOK, ListResponse[models.Revision]{Items: revisions, NextCursor: nextCursor})
+}
+
+// GetRevision handles GET /admin/projects/:id/revisions/:rev
+func (h *ProjectHandler) GetRevision(c echo.Context) error {
+	rev, err := h.lookupRevision(c, c.Param("rev"))
+	if err != nil {
+		return revisionLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, rev)
+}
+
+// DiffRevision handles GET /admin/projects/:id/revisions/:rev/diff
+// The revision is compared with the current project, or with another
+// revision given as ?against=<number>.
+func (h *ProjectHandler) DiffRevision(c echo.Context) error {
+	rev, err := h.lookupRevision(c, c.Param("rev"))
+	if err != nil {
+		return revisionLookupError(c, err)
+	}
+
+	var against models.Project
+	againstLabel := "current"
+	if raw := c.QueryParam("against"); raw != "" {
+		other, err := h.lookupRevision(c, raw)
+		if err != nil {
+			return revisionLookupError(c, err)
+		}
+		against = *other.Snapshot
+		againstLabel = raw
+	} else {
+		current, err := h.Projects.Get(context.Background(), c.Param("id"))
+		if err != nil {
+			return projectLookupError(c, err)
+		}
+		against = *current
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revision": rev.Number,
+		"against":  againstLabel,
+		"changes":  revision.Diff(*rev.Snapshot, against),
+	})
+}
+
+// RestoreRevision handles POST /admin/projects/:id/revisions/:rev/restore
+// The revision's content is saved as a new revision on top of the current
+// project. The current slug and status are kept (status changes go through
+// the workflow). Images no longer in storage, or being deleted, are left
+// out; saveProject cancels the deletions still queued for the others once
+// the restore is saved.
+func (h *ProjectHandler) RestoreRevision(c echo.Context) error {
+	// If-Match is optional, restoring is an explicit overwrite
+	expectedVersion, err := ifMatchVersion(c)
+	if errors.Is(err, errMissingIfMatch) {
+		expectedVersion = anyVersion
+	} else if err != nil {
+		return ifMatchError(c, err)
+	}
+
+	ctx := context.Background()
+
+	// 1. Fetch the current project and the revision to restore
+	existing, err := h.Projects.Get(ctx, c.Param("id"))
+	if err != nil {
+		return projectLookupError(c, err)
+	}
+	if expectedVersion != anyVersion && existing.Version != expectedVersion {
+		return h.versionConflict(c, http.StatusPreconditionFailed, existing)
+	}
+	rev, err := h.lookupRevision(c, c.Param("rev"))
+	if err != nil {
+		return revisionLookupError(c, err)
+	}
+
+	// 2. Re-link the images that are still in storage
+	var images []models.ProjectImage
+	var missing []models.ProjectImage
+	kept := make(map[string]bool)
+	for _, img := range rev.Snapshot.Images {
+		if deleting, err := h.Media.Deleting(ctx, img); err != nil {
+			c.Logger().Errorf("Failed to check the deletion of image %s: %v", img.ID, err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check images in storage"})
+		} else if deleting {
+			missing = append(missing, img)
+			continue
+		}
+		if objectPath := imageObjectPath(img); objectPath != "" {
+			if _, err := h.Blobs.Stat(ctx, objectPath); errors.Is(err, blob.ErrNotFound) {
+				missing = append(missing, img)
+				continue
+			} else if err != nil {
+				c.Logger().Errorf("Failed to check image %s: %v", objectPath, err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check images in storage"})
+			}
+		}
+		images = append(images, img)
+		kept[img.ID] = true
+	}
+
+	req := rev.Snapshot.EditableFields()
+	req.ID = existing.ID
+	req.Slug = existing.Slug
+	req.Status = existing.Status
+	req.Images = images
+	if len(missing) > 0 {
+		coverKept := false
+		for _, img := range images {
+			coverKept = coverKept || img.URL == req.CoverImage
+		}
+		if !coverKept {
+			req.CoverImage = ""
+		}
+		req.ImageGroups = withoutMissingImages(req.ImageGroups, kept)
+	}
+
+	// 3. Save through the regular update path
+	if missing == nil {
+		missing = []models.ProjectImage{}
+	}
+	return h.saveProject(c, existing, &req, "rollback", map[string]interface{}{
+		"restoredRevision": rev.Number,
+		"missingImages":    missing,
+	})
+}
+
+// withoutMissingImages drops image references that were not re-linked
+func withoutMissingImages(groups []models.ImageGroup, kept map[string]bool) []models.ImageGroup {
+	out := make([]models.ImageGroup, len(groups))
+	for i, group := range groups {
+		var images []string
+		for _, id := range group.Images {
+			if kept[id] {
+				images = append(images, id)
+			}
+		}
+		group.Images = images
+		out[i] = group
+	}
+	return out
+}
+
+// lookupRevision loads a revision of the project in the :id path parameter
+func (h *ProjectHandler) lookupRevision(c echo.Context, rawNumber string) (*models.Revision, error) {
+	number, err := strconv.ParseInt(rawNumber, 10, 64)
+	if err != nil || number < 1 {
+		return nil, repository.ErrNotFound
+	}
+	rev, err := h.Revisions.Get(context.Background(), c.Param("id"), number)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Snapshot == nil {
+		return nil, errors.New("revision has no snapshot")
+	}
+	return rev, nil
+}
+
+// revisionLookupError maps revision lookup failures onto HTTP responses
+func revisionLookupError(c echo.Context, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Revision not found"})
+	}
+	c.Logger().Errorf("Failed to fetch revision: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch revision"})
+}
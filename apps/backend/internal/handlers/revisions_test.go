@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+	"github.com/networkcaretaker/garden_app/backend/internal/revision"
+)
+
+// Revision 1 of the project saved by newRevisedProject: image a is still in
+// storage, image b's file is gone
+const revisedProjectV1 = `{"id":"patio","title":"Patio","category":"hardscape",
+	"coverImage":"http://localhost/storage/projects/patio/b.jpg",
+	"images":[
+		{"id":"a","url":"http://localhost/storage/projects/patio/a.jpg","storagePath":"projects/patio/a.jpg"},
+		{"id":"b","url":"http://localhost/storage/projects/patio/b.jpg","storagePath":"projects/patio/b.jpg"}
+	],
+	"imageGroups":[{"name":"All","images":["a","b"]}]}`
+
+// newRevisedProject creates a project and then removes its images, leaving
+// two revisions and the deletion of the images' files queued
+func newRevisedProject(t *testing.T) *projectServer {
+	t.Helper()
+	s := newProjectServer(t)
+	if err := s.blobs.Put(context.Background(), "projects/patio/a.jpg", strings.NewReader("a"), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if rec := s.do(http.MethodPost, "/admin/projects", revisedProjectV1, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := s.do(http.MethodPut, "/admin/projects/patio", `{"id":"patio","title":"Stone patio","category":"hardscape"}`, map[string]string{"If-Match": `"1"`})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := s.queuedDeletes(t); !reflect.DeepEqual(got, []string{"projects/patio/a.jpg", "projects/patio/b.jpg"}) {
+		t.Fatalf("queued deletions = %v after removing the images", got)
+	}
+	return s
+}
+
+func TestListRevisions(t *testing.T) {
+	s := newRevisedProject(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       []int64
+		wantCursor string
+	}{
+		{"newest first", "", http.StatusOK, []int64{2, 1}, ""},
+		{"first page", "?limit=1", http.StatusOK, []int64{2}, "2"},
+		{"next page", "?limit=1&cursor=2", http.StatusOK, []int64{1}, ""},
+		{"bad limit", "?limit=0", http.StatusBadRequest, nil, ""},
+		{"bad cursor", "?cursor=x", http.StatusBadRequest, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := s.do(http.MethodGet, "/admin/projects/patio/revisions"+tt.query, "", nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var page ListResponse[models.Revision]
+			if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			var got []int64
+			for _, rev := range page.Items {
+				got = append(got, rev.Number)
+				if rev.Snapshot != nil {
+					t.Errorf("revision %d listed with its snapshot", rev.Number)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) || page.NextCursor != tt.wantCursor {
+				t.Errorf("got %v with cursor %q, want %v with %q", got, page.NextCursor, tt.want, tt.wantCursor)
+			}
+		})
+	}
+
+	if rec := s.do(http.MethodGet, "/admin/projects/missing/revisions", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing project status = %d, want 404", rec.Code)
+	}
+}
+
+func TestGetRevision(t *testing.T) {
+	s := newRevisedProject(t)
+
+	rec := s.do(http.MethodGet, "/admin/projects/patio/revisions/2", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var rev models.Revision
+	if err := json.Unmarshal(rec.Body.Bytes(), &rev); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rev.Action != "update" || rev.By != "test-user" || rev.Snapshot == nil || rev.Snapshot.Title != "Stone patio" {
+		t.Errorf("revision = %+v", rev)
+	}
+	if want := []string{"coverImage", "imageGroups", "images", "title"}; !reflect.DeepEqual(rev.Changes, want) {
+		t.Errorf("changes = %v, want %v", rev.Changes, want)
+	}
+
+	for _, number := range []string{"3", "0", "x"} {
+		if rec := s.do(http.MethodGet, "/admin/projects/patio/revisions/"+number, "", nil); rec.Code != http.StatusNotFound {
+			t.Errorf("revision %s status = %d, want 404", number, rec.Code)
+		}
+	}
+}
+
+func TestDiffRevision(t *testing.T) {
+	s := newRevisedProject(t)
+
+	tests := []struct {
+		target      string
+		wantAgainst string
+		wantFields  []string
+	}{
+		{"/admin/projects/patio/revisions/1/diff", "current", []string{"coverImage", "imageGroups", "images", "title"}},
+		{"/admin/projects/patio/revisions/1/diff?against=2", "2", []string{"coverImage", "imageGroups", "images", "title"}},
+		{"/admin/projects/patio/revisions/2/diff", "current", []string{}},
+	}
+	for _, tt := range tests {
+		rec := s.do(http.MethodGet, tt.target, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d: %s", tt.target, rec.Code, rec.Body.String())
+		}
+		var diff struct {
+			Against string            `json:"against"`
+			Changes []revision.Change `json:"changes"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &diff); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got := revision.Summary(diff.Changes); diff.Against != tt.wantAgainst || !reflect.DeepEqual(got, tt.wantFields) {
+			t.Errorf("%s: against %q changed %v, want %q changed %v", tt.target, diff.Against, got, tt.wantAgainst, tt.wantFields)
+		}
+	}
+
+	if rec := s.do(http.MethodGet, "/admin/projects/patio/revisions/1/diff?against=9", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing comparison status = %d, want 404", rec.Code)
+	}
+}
+
+func TestRestoreRevision(t *testing.T) {
+	s := newRevisedProject(t)
+
+	rec := s.do(http.MethodPost, "/admin/projects/patio/revisions/1/restore", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		RestoredRevision int64                 `json:"restoredRevision"`
+		MissingImages    []models.ProjectImage `json:"missingImages"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.RestoredRevision != 1 || len(body.MissingImages) != 1 || body.MissingImages[0].ID != "b" {
+		t.Errorf("response = %s, want revision 1 restored without image b", rec.Body.String())
+	}
+
+	stored := s.stored(t, "patio")
+	if stored.Version != 3 || stored.Title != "Patio" {
+		t.Errorf("version %d titled %q, want version 3 titled Patio", stored.Version, stored.Title)
+	}
+	if len(stored.Images) != 1 || stored.Images[0].ID != "a" {
+		t.Errorf("images = %+v, want only image a re-linked", stored.Images)
+	}
+	if stored.CoverImage != stored.Images[0].URL {
+		t.Errorf("cover = %q, want the first kept image once the old cover is missing", stored.CoverImage)
+	}
+	if len(stored.ImageGroups) != 1 || !reflect.DeepEqual(stored.ImageGroups[0].Images, []string{"a"}) {
+		t.Errorf("image groups = %+v, want image b dropped", stored.ImageGroups)
+	}
+	if got := s.queuedDeletes(t); !reflect.DeepEqual(got, []string{"projects/patio/b.jpg"}) {
+		t.Errorf("queued deletions = %v, want only the missing image's", got)
+	}
+
+	rev, err := s.revisions.Get(context.Background(), "patio", 3)
+	if err != nil || rev.Action != "rollback" {
+		t.Errorf("revision 3 = %+v, %v, want a rollback", rev, err)
+	}
+
+	stale := s.do(http.MethodPost, "/admin/projects/patio/revisions/1/restore", "", map[string]string{"If-Match": `"2"`})
+	if stale.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale If-Match status = %d, want 412", stale.Code)
+	}
+}
+
+func TestRestoreRevisionKeepsDeletionsWhenTheSaveFails(t *testing.T) {
+	s := newRevisedProject(t)
+	rec := s.do(http.MethodPut, "/admin/projects/patio", `{"id":"patio","title":"Stone patio","category":"paving"}`, map[string]string{"If-Match": `"2"`})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := s.settings.Merge(context.Background(), "projects", map[string]interface{}{"categories": []interface{}{"paving"}}); err != nil {
+		t.Fatalf("Merge settings: %v", err)
+	}
+
+	// Revision 1's category has been retired since
+	rec = s.do(http.MethodPost, "/admin/projects/patio/revisions/1/restore", "", nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body.String())
+	}
+	if got := s.queuedDeletes(t); !reflect.DeepEqual(got, []string{"projects/patio/a.jpg", "projects/patio/b.jpg"}) {
+		t.Errorf("queued deletions = %v, a failed restore cancelled them", got)
+	}
+}
+
+func TestRestoreRevisionSkipsImagesBeingDeleted(t *testing.T) {
+	s := newRevisedProject(t)
+	queued, err := s.jobs.List(context.Background(), repository.JobFilter{Payload: map[string]string{"path": "projects/patio/a.jpg"}}, 0)
+	if err != nil || len(queued) != 1 {
+		t.Fatalf("List = %v, %v", queued, err)
+	}
+	running := queued[0]
+	running.Status = models.JobRunning
+	if err := s.jobs.Save(context.Background(), &running); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	rec := s.do(http.MethodPost, "/admin/projects/patio/revisions/1/restore", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if stored := s.stored(t, "patio"); len(stored.Images) != 0 {
+		t.Errorf("images = %+v, want the image being deleted left out", stored.Images)
+	}
+}
@@ -2,41 +2,45 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
 	"github.com/networkcaretaker/garden_app/backend/internal/config"
-	"github.com/networkcaretaker/garden_app/backend/internal/db"
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/media"
 	"github.com/networkcaretaker/garden_app/backend/internal/models"
-	"google.golang.org/api/iterator"
+	"github.com/networkcaretaker/garden_app/backend/internal/publish"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
 )
 
-// SettingsHandler holds the database connection
+// SettingsHandler holds the repositories, blob store, media library, publisher and publish scheduler
 type SettingsHandler struct {
-	Client *db.Client
-	Config *config.Config
+	Projects  repository.ProjectRepository
+	Settings  repository.SettingsRepository
+	Blobs     blob.Store
+	Media     *media.Library
+	Publisher *publish.Publisher
+	Scheduler *publish.Scheduler
+	Config    *config.Config
 }
 
 // NewSettingsHandler creates a new handler instance
-func NewSettingsHandler(client *db.Client, cfg *config.Config) *SettingsHandler {
-	return &SettingsHandler{Client: client, Config: cfg}
+func NewSettingsHandler(projects repository.ProjectRepository, settings repository.SettingsRepository, blobs blob.Store, library *media.Library, publisher *publish.Publisher, scheduler *publish.Scheduler, cfg *config.Config) *SettingsHandler {
+	return &SettingsHandler{Projects: projects, Settings: settings, Blobs: blobs, Media: library, Publisher: publisher, Scheduler: scheduler, Config: cfg}
 }
 
-const settingsCollection = "settings"
-const websiteDocument = "website"
-const projectsDocument = "projects"
-
 // GetWebsiteSettings handles GET /settings/website
 func (h *SettingsHandler) GetWebsiteSettings(c echo.Context) error {
 	ctx := context.Background()
-	doc, err := h.Client.Firestore.Collection(settingsCollection).Doc(websiteDocument).Get(ctx)
+	settings, err := h.Settings.Get(ctx, repository.WebsiteDocument)
 	if err != nil {
 		// If the document doesn't exist, return default/empty values
-		if strings.Contains(err.Error(), "NotFound") {
+		if errors.Is(err, repository.ErrNotFound) {
+			setETag(c, 0)
 			return c.JSON(http.StatusOK, map[string]interface{}{
 				"websiteURL": "",
 				"title":      "",
@@ -47,12 +51,12 @@ func (h *SettingsHandler) GetWebsiteSettings(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch website settings"})
 	}
 
-	var settings map[string]interface{}
-	if err := doc.DataTo(&settings); err != nil {
-		c.Logger().Errorf("Failed to parse website settings: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to parse website settings"})
+	// Who edited what is only reported to admins, through the publish status
+	for _, field := range publish.PrivateFields {
+		delete(settings, field)
 	}
 
+	setETag(c, repository.SettingsVersion(settings))
 	return c.JSON(http.StatusOK, settings)
 }
 
@@ -67,6 +71,11 @@ func (h *SettingsHandler) UpdateWebsiteSettings(c echo.Context) error {
 		WhatsappMessage string `json:"whatsappMessage"`
 	}
 
+	type RobotsSettings struct {
+		NoIndex  bool     `json:"noIndex"`  // Keep search engines off the whole website
+		Disallow []string `json:"disallow"` // Paths crawlers should skip, e.g. "/projects"
+	}
+
 	var req struct {
 		Title       string                 `json:"title"`
 		WebsiteURL  string                 `json:"websiteURL"`
@@ -76,17 +85,31 @@ func (h *SettingsHandler) UpdateWebsiteSettings(c echo.Context) error {
 		Logo        map[string]interface{} `json:"logo"`
 		Social      SocialLinks            `json:"social"`
 		SEO         []string               `json:"seo"`
+		Robots      RobotsSettings         `json:"robots"`
 		Content     map[string]interface{} `json:"content"`
 	}
 
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchError(c, err)
+	}
+
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 	}
 
 	ctx := context.Background()
-	docRef := h.Client.Firestore.Collection(settingsCollection).Doc(websiteDocument)
+	uid, _ := currentUser(c)
+
+	// Remember which uploaded images the website used before this save
+	previous, err := h.Settings.Get(ctx, repository.WebsiteDocument)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.Logger().Errorf("Failed to fetch website settings: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update settings"})
+	}
 
 	// Construct the map to save.
+	now := time.Now()
 	// We map the struct fields explicitly to ensure only valid data is saved.
 	data := map[string]interface{}{
 		"title":       req.Title,
@@ -102,28 +125,72 @@ func (h *SettingsHandler) UpdateWebsiteSettings(c echo.Context) error {
 			"whatsapp":        req.Social.Whatsapp,
 			"whatsappMessage": req.Social.WhatsappMessage,
 		},
+		"robots": map[string]interface{}{
+			"noIndex":  req.Robots.NoIndex,
+			"disallow": req.Robots.Disallow,
+		},
 		"seo":       req.SEO,
 		"content":   req.Content,
-		"updatedAt": time.Now(),
+		"updatedAt": now,
+		"updatedBy": uid,
+	}
+	// Note who changed which section, for the publish status
+	if sections := publish.ChangedSections(previous, data); len(sections) > 0 {
+		data[publish.SectionUpdatesField] = publish.SectionUpdates(sections, uid, now)
 	}
 
-	// Use Set with MergeAll to create the document if it doesn't exist or update existing fields
-	_, err := docRef.Set(ctx, data, firestore.MergeAll)
-
+	// Merge creates the document if it doesn't exist or updates existing fields,
+	// as long as nobody saved since the editor loaded the settings
+	version, err := h.Settings.MergeVersioned(ctx, repository.WebsiteDocument, data, expectedVersion)
+	if errors.Is(err, repository.ErrConflict) {
+		return h.versionConflict(c, repository.WebsiteDocument)
+	}
 	if err != nil {
 		c.Logger().Errorf("Failed to update website settings: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update settings"})
 	}
+	h.updateMediaReferences(c, previous, data)
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	setETag(c, version)
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success", "version": version})
+}
+
+// updateMediaReferences counts the uploaded images in the saved website
+// settings as used and releases those the save removed, which deletes
+// their files unless a project still uses them
+func (h *SettingsHandler) updateMediaReferences(c echo.Context, previous, saved map[string]interface{}) {
+	ctx := context.Background()
+
+	kept := make(map[string]bool)
+	for _, img := range media.ImagesIn(saved) {
+		if kept[img.Hash] {
+			continue
+		}
+		kept[img.Hash] = true
+		if err := h.Media.Retain(ctx, img, models.WebsiteMediaRef); err != nil {
+			c.Logger().Errorf("Failed to record use of website image %s: %v", img.URL, err)
+		}
+	}
+
+	released := make(map[string]bool)
+	for _, img := range media.ImagesIn(previous) {
+		if kept[img.Hash] || released[img.Hash] {
+			continue
+		}
+		released[img.Hash] = true
+		if err := h.Media.Release(ctx, img, models.WebsiteMediaRef); err != nil {
+			c.Logger().Errorf("Failed to release website image %s: %v", img.URL, err)
+		}
+	}
 }
 
 // GetProjectSettings handles GET /settings/projects
 func (h *SettingsHandler) GetProjectSettings(c echo.Context) error {
 	ctx := context.Background()
-	doc, err := h.Client.Firestore.Collection(settingsCollection).Doc(projectsDocument).Get(ctx)
+	settings, err := h.Settings.Get(ctx, repository.ProjectsDocument)
 	if err != nil {
-		if strings.Contains(err.Error(), "NotFound") {
+		if errors.Is(err, repository.ErrNotFound) {
+			setETag(c, 0)
 			return c.JSON(http.StatusOK, map[string]interface{}{
 				"categories": []string{},
 				"tags":       []string{},
@@ -133,12 +200,7 @@ func (h *SettingsHandler) GetProjectSettings(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch project settings"})
 	}
 
-	var settings map[string]interface{}
-	if err := doc.DataTo(&settings); err != nil {
-		c.Logger().Errorf("Failed to parse project settings: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to parse project settings"})
-	}
-
+	setETag(c, repository.SettingsVersion(settings))
 	return c.JSON(http.StatusOK, settings)
 }
 
@@ -149,12 +211,16 @@ func (h *SettingsHandler) UpdateProjectSettings(c echo.Context) error {
 		Tags       []string `json:"tags"`
 	}
 
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchError(c, err)
+	}
+
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 	}
 
 	ctx := context.Background()
-	docRef := h.Client.Firestore.Collection(settingsCollection).Doc(projectsDocument)
 
 	data := map[string]interface{}{
 		"categories": req.Categories,
@@ -162,170 +228,176 @@ func (h *SettingsHandler) UpdateProjectSettings(c echo.Context) error {
 		"updatedAt":  time.Now(),
 	}
 
-	_, err := docRef.Set(ctx, data, firestore.MergeAll)
+	version, err := h.Settings.MergeVersioned(ctx, repository.ProjectsDocument, data, expectedVersion)
+	if errors.Is(err, repository.ErrConflict) {
+		return h.versionConflict(c, repository.ProjectsDocument)
+	}
 	if err != nil {
 		c.Logger().Errorf("Failed to update project settings: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update project settings"})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	setETag(c, version)
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success", "version": version})
 }
 
-// PublishWebsiteData handles POST /admin/settings/website/publish
-func (h *SettingsHandler) PublishWebsiteData(c echo.Context) error {
-	ctx := context.Background()
-
-	// Get bucket handle once for both operations
-	bucket, err := h.Client.Storage.Bucket(h.Config.FirebaseStorageBucket)
-	if err != nil {
-		c.Logger().Errorf("Failed to get storage bucket: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to access storage bucket"})
+// versionConflict rejects a stale settings edit with 412, returning the
+// server's current copy so the editor can reapply their changes
+func (h *SettingsHandler) versionConflict(c echo.Context, doc string) error {
+	current, err := h.Settings.Get(context.Background(), doc)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.Logger().Errorf("Failed to fetch current settings after conflict: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch current settings"})
 	}
 
-	// Helper function to upload JSON to a specific path
-	uploadJSON := func(objectPath string, data interface{}) error {
-		jsonData, err := json.MarshalIndent(data, "", "  ")
+	setETag(c, repository.SettingsVersion(current))
+	return c.JSON(http.StatusPreconditionFailed, map[string]interface{}{
+		"error":   "Settings were changed by someone else, reload and reapply your edits",
+		"current": current,
+	})
+}
+
+// PublishWebsiteData handles POST /admin/settings/website/publish
+// Writes a new release of projects.json and websiteConfig.json and makes
+// it live in one step. With ?dryRun=true nothing is written, the response
+// is the diff against the live release instead.
+func (h *SettingsHandler) PublishWebsiteData(c echo.Context) error {
+	dryRun := false
+	if raw := c.QueryParam("dryRun"); raw != "" {
+		value, err := strconv.ParseBool(raw)
 		if err != nil {
-			return err
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "dryRun must be true or false"})
 		}
-
-		wc := bucket.Object(objectPath).NewWriter(ctx)
-		wc.ContentType = "application/json"
-		if _, err := wc.Write(jsonData); err != nil {
-			return err
+		dryRun = value
+	}
+	if dryRun {
+		diff, err := h.Publisher.Preview(context.Background())
+		if err != nil {
+			c.Logger().Errorf("Failed to preview publish: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to preview website data"})
 		}
-		return wc.Close()
+		return c.JSON(http.StatusOK, diff)
 	}
 
-	// --- OPERATION 1: PROJECTS JSON ---
-
-	// 1. Fetch all 'active' projects
-	var projectsForJSON []map[string]interface{} // This will hold the transformed projects
-
-	// Define a local struct that mirrors the necessary fields of models.Image.
-	// This is used to create a lookup map for image details, bypassing potential
-	// compiler issues with directly referencing models.Image in this context.
-	type imageDetails struct {
-		ID      string `json:"id"`
-		URL     string `json:"url"`
-		Caption string `json:"caption"`
-		Alt     string `json:"alt"`
+	uid, _ := currentUser(c)
+	release, err := h.Publisher.Publish(context.Background(), uid, models.ReleaseManual)
+	if err != nil {
+		c.Logger().Errorf("Failed to publish website: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to publish website data"})
 	}
-	iter := h.Client.Firestore.Collection("projects").
-		Where("status", "==", "active").
-		OrderBy("createdAt", firestore.Desc).
-		Documents(ctx)
-	defer iter.Stop()
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			c.Logger().Errorf("Failed to iterate projects: %v", err)
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch projects"})
-		}
+	c.Logger().Infof("Published website release %s", release.ID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Website data and configuration published successfully",
+		"release": release,
+	})
+}
 
-		var p models.Project
-		if err := doc.DataTo(&p); err != nil {
-			c.Logger().Warnf("Failed to parse project %s: %v", doc.Ref.ID, err)
-			continue
-		}
-		p.ID = doc.Ref.ID // Ensure ID is set from Firestore document ID
-
-		// Create a map for quick lookup of image details by ID using the local struct.
-		// We populate this map from the 'p.Images' (which are of type models.Image).
-		imageDetailsMap := make(map[string]imageDetails)
-		for _, img := range p.Images {
-			imageDetailsMap[img.ID] = imageDetails{
-				ID:      img.ID,
-				URL:     img.URL,
-				Caption: img.Caption,
-				Alt:     img.Alt,
-			}
-		}
+// GetPublishStatus handles GET /admin/settings/website/status
+// Reports whether the live website is stale: which projects and settings
+// sections differ from the live release, and who changed them since.
+func (h *SettingsHandler) GetPublishStatus(c echo.Context) error {
+	status, err := h.Publisher.Status(context.Background())
+	if err != nil {
+		c.Logger().Errorf("Failed to compute publish status: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to compute publish status"})
+	}
+	return c.JSON(http.StatusOK, status)
+}
 
-		// Convert the models.Project struct to a generic map[string]interface{}
-		// This allows us to modify the structure of imageGroups before marshaling to JSON.
-		projectBytes, err := json.Marshal(p)
-		if err != nil {
-			c.Logger().Warnf("Failed to marshal project %s to JSON: %v", p.ID, err)
-			continue
-		}
-		var projectMap map[string]interface{}
-		if err := json.Unmarshal(projectBytes, &projectMap); err != nil {
-			c.Logger().Warnf("Failed to unmarshal project %s JSON to map: %v", p.ID, err)
-			continue
+// ListReleases handles GET /admin/settings/website/releases
+// Lists website releases newest first; pass the nextCursor back as ?cursor=
+// for older ones.
+func (h *SettingsHandler) ListReleases(c echo.Context) error {
+	limit := defaultPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPageSize {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxPageSize)})
 		}
+		limit = n
+	}
 
-		// Transform the 'imageGroups' field
-		if rawImageGroups, ok := projectMap["imageGroups"].([]interface{}); ok {
-			transformedImageGroups := make([]map[string]interface{}, 0, len(rawImageGroups))
-			for _, rawGroup := range rawImageGroups {
-				if groupMap, isMap := rawGroup.(map[string]interface{}); isMap {
-					if rawImages, hasImages := groupMap["images"].([]interface{}); hasImages {
-						var newImages []map[string]interface{}
-						for _, imgIDInterface := range rawImages {
-							if imgID, isString := imgIDInterface.(string); isString {
-								if imgDetail, found := imageDetailsMap[imgID]; found {
-									newImages = append(newImages, map[string]interface{}{
-										"id":      imgDetail.ID,
-										"url":     imgDetail.URL,
-										"caption": imgDetail.Caption,
-										"alt":     imgDetail.Alt,
-									})
-								}
-							}
-						}
-						groupMap["images"] = newImages // Replace the images array with the transformed one
-					}
-					transformedImageGroups = append(transformedImageGroups, groupMap)
-				}
-			}
-			projectMap["imageGroups"] = transformedImageGroups // Replace the project's imageGroups
-		}
-		projectsForJSON = append(projectsForJSON, projectMap)
+	// Fetch one extra release to know whether another page exists
+	releases, err := h.Publisher.History(context.Background(), c.QueryParam("cursor"), limit+1)
+	if err != nil {
+		c.Logger().Errorf("Failed to list releases: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list releases"})
+	}
+	nextCursor := ""
+	if len(releases) > limit {
+		releases = releases[:limit]
+		nextCursor = releases[limit-1].ID
 	}
+	return c.JSON(http.StatusOK, ListResponse[models.Release]{Items: releases, NextCursor: nextCursor})
+}
 
-	if err := uploadJSON("website/projects.json", projectsForJSON); err != nil {
-		c.Logger().Errorf("Failed to upload projects JSON: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to upload projects data"})
+// RollbackWebsite handles POST /admin/settings/website/rollback/:releaseId
+// Makes an earlier release live again without rebuilding it
+func (h *SettingsHandler) RollbackWebsite(c echo.Context) error {
+	uid, _ := currentUser(c)
+	release, err := h.Publisher.Rollback(context.Background(), c.Param("releaseId"), uid)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Release not found"})
+	case errors.Is(err, publish.ErrIncompleteRelease):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "The files of this release are missing, it cannot go live again"})
+	case err != nil:
+		c.Logger().Errorf("Failed to roll back to release %s: %v", c.Param("releaseId"), err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to roll back website"})
 	}
 
-	// --- OPERATION 2: SETTINGS JSON ---
+	c.Logger().Infof("Rolled website back to release %s", release.ID)
+	return c.JSON(http.StatusOK, release)
+}
 
-	// 1. Fetch website settings
-	settingsDoc, err := h.Client.Firestore.Collection(settingsCollection).Doc(websiteDocument).Get(ctx)
-	if err != nil {
-		c.Logger().Errorf("Failed to fetch settings for publish: %v", err)
-		// We continue even if settings fail? Or return error?
-		// Usually safer to fail so state isn't partial.
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch website settings"})
+// SchedulePublish handles POST /admin/settings/website/schedule
+// Takes {"at": "<RFC 3339 time>"} and publishes the website then
+func (h *SettingsHandler) SchedulePublish(c echo.Context) error {
+	var req struct {
+		At time.Time `json:"at"`
 	}
-
-	var settingsData map[string]interface{}
-	if err := settingsDoc.DataTo(&settingsData); err != nil {
-		c.Logger().Errorf("Failed to parse settings for publish: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to parse website settings"})
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "at must be an RFC 3339 time"})
+	}
+	if !req.At.After(time.Now()) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "at must be in the future"})
 	}
 
-	if err := uploadJSON("website/websiteConfig.json", settingsData); err != nil {
-		c.Logger().Errorf("Failed to upload settings JSON: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to upload settings data"})
+	uid, _ := currentUser(c)
+	job, err := h.Scheduler.Schedule(context.Background(), req.At, uid)
+	if err != nil {
+		c.Logger().Errorf("Failed to schedule publish: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to schedule publish"})
 	}
+	return c.JSON(http.StatusCreated, job)
+}
 
-	// Update publishedAt timestamp
-	_, err = h.Client.Firestore.Collection(settingsCollection).Doc(websiteDocument).Set(ctx, map[string]interface{}{
-		"publishedAt": time.Now(),
-	}, firestore.MergeAll)
+// ListScheduledPublishes handles GET /admin/settings/website/schedule
+// Lists scheduled and pending auto publishes, soonest first. Failed ones
+// stay listed while they wait for a retry; finished ones are in the
+// release history.
+func (h *SettingsHandler) ListScheduledPublishes(c echo.Context) error {
+	scheduled, err := h.Scheduler.Scheduled(context.Background())
 	if err != nil {
-		c.Logger().Errorf("Failed to update publishedAt timestamp: %v", err)
+		c.Logger().Errorf("Failed to list scheduled publishes: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list scheduled publishes"})
 	}
+	return c.JSON(http.StatusOK, ListResponse[models.Job]{Items: scheduled})
+}
 
-	c.Logger().Info("Successfully published website data (projects.json and websiteConfig.json)")
-	return c.JSON(http.StatusOK, map[string]string{
-		"status":  "success",
-		"message": "Website data and configuration published successfully",
-	})
+// CancelScheduledPublish handles DELETE /admin/settings/website/schedule/:id
+func (h *SettingsHandler) CancelScheduledPublish(c echo.Context) error {
+	err := h.Scheduler.Cancel(context.Background(), c.Param("id"))
+	switch {
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, publish.ErrNotScheduled):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Scheduled publish not found"})
+	case errors.Is(err, jobs.ErrRunning):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "The publish is running, it can no longer be cancelled"})
+	case err != nil:
+		c.Logger().Errorf("Failed to cancel scheduled publish: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to cancel scheduled publish"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id"), "status": "cancelled"})
 }
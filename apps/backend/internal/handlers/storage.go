@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/gc"
+)
+
+// StorageHandler exposes storage maintenance to admins
+type StorageHandler struct {
+	GC *gc.Collector
+}
+
+// NewStorageHandler creates a new handler instance
+func NewStorageHandler(collector *gc.Collector) *StorageHandler {
+	return &StorageHandler{GC: collector}
+}
+
+// GetOrphanReport handles GET /admin/storage/orphans
+// A dry run: lists unreferenced images without deleting anything.
+func (h *StorageHandler) GetOrphanReport(c echo.Context) error {
+	return h.collect(c, true)
+}
+
+// CollectOrphans handles POST /admin/storage/orphans/collect
+// Queues every unreferenced image past the grace period for deletion.
+func (h *StorageHandler) CollectOrphans(c echo.Context) error {
+	return h.collect(c, false)
+}
+
+func (h *StorageHandler) collect(c echo.Context, dryRun bool) error {
+	report, err := h.GC.Run(context.Background(), dryRun)
+	if err != nil {
+		c.Logger().Errorf("Storage garbage collection failed: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to scan storage"})
+	}
+	return c.JSON(http.StatusOK, report)
+}
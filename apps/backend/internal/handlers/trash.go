@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+	"github.com/networkcaretaker/garden_app/backend/internal/workflow"
+)
+
+// TrashedProject is a project in the trash along with when it will be purged
+type TrashedProject struct {
+	models.Project
+	PurgeAfter time.Time `json:"purgeAfter"`
+}
+
+// GetTrashedProjects handles GET /admin/projects/trash
+func (h *ProjectHandler) GetTrashedProjects(c echo.Context) error {
+	opts, err := parseListOptions(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	opts.Trashed = true
+
+	projects, nextCursor, err := h.Projects.List(context.Background(), opts)
+	if err != nil {
+		return listError(c, err)
+	}
+
+	items := make([]TrashedProject, 0, len(projects))
+	for _, p := range projects {
+		items = append(items, TrashedProject{Project: p, PurgeAfter: p.DeletedAt.Add(h.Config.TrashRetention)})
+	}
+	return c.JSON(http.StatusOK, ListResponse[TrashedProject]{Items: items, NextCursor: nextCursor})
+}
+
+// RestoreProject handles POST /admin/projects/:id/restore
+func (h *ProjectHandler) RestoreProject(c echo.Context) error {
+	ctx := context.Background()
+
+	project, err := h.Projects.Get(ctx, c.Param("id"))
+	if err != nil {
+		return projectLookupError(c, err)
+	}
+	if project.DeletedAt == nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Project is not in the trash"})
+	}
+	before := *project
+
+	project.DeletedAt = nil
+	project.DeletedBy = ""
+	project.UpdatedAt = time.Now()
+	if err := h.Projects.Update(ctx, project); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Project was changed while restoring, please try again"})
+		}
+		return projectLookupError(c, err)
+	}
+	h.Search.Put(*project)
+	h.recordRevision(c, &before, *project, "restore")
+
+	// An active project reappears on the website
+	if workflow.AffectsPublicSite("", project.Status) {
+		h.touchProjectUpdatedAt(c)
+	}
+
+	setETag(c, project.Version)
+	return c.JSON(http.StatusOK, project)
+}
+
+// PurgeTrash permanently deletes projects, and their images, that have been
+// in the trash for longer than Config.TrashRetention. Returns how many were purged.
+func (h *ProjectHandler) PurgeTrash(ctx context.Context) (int, error) {
+	projects, _, err := h.Projects.List(ctx, repository.ListOptions{Trashed: true})
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-h.Config.TrashRetention)
+	purged := 0
+	for _, p := range projects {
+		if p.DeletedAt.After(cutoff) {
+			continue
+		}
+
+		// Delete the document first: a restore racing the purge must not
+		// leave a live project pointing at deleted images
+		if err := h.Projects.Delete(ctx, p.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return purged, err
+		}
+		if err := h.Revisions.DeleteAll(ctx, p.ID); err != nil {
+			log.Printf("Failed to delete revisions of purged project %s: %v", p.ID, err)
+		}
+		for _, img := range p.Images {
+			if err := h.Media.Release(ctx, img, models.ProjectMediaRef(p.ID, img.ID)); err != nil {
+				log.Printf("Failed to release image %s of purged project %s: %v", img.URL, p.ID, err)
+			}
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// trashedError rejects changes to a project that is in the trash
+func trashedError(c echo.Context) error {
+	return c.JSON(http.StatusConflict, map[string]string{"error": "Project is in the trash, restore it first"})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// queuedDeletes returns the sorted paths of every queued blob deletion
+func (s *projectServer) queuedDeletes(t *testing.T) []string {
+	t.Helper()
+	queued, err := s.jobs.List(context.Background(), repository.JobFilter{Type: jobs.TypeDeleteBlob}, 0)
+	if err != nil {
+		t.Fatalf("List jobs: %v", err)
+	}
+	paths := []string{}
+	for _, job := range queued {
+		paths = append(paths, job.Payload["path"])
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestDeleteProjectMovesToTrash(t *testing.T) {
+	s := newProjectServer(t)
+	s.handler.Config.TrashRetention = 48 * time.Hour
+	s.seed(t, models.Project{ID: "patio", Title: "Patio", Category: "hardscape", Status: models.StatusActive,
+		Images: []models.ProjectImage{{ID: "a", URL: "http://localhost/storage/projects/patio/a.jpg", StoragePath: "projects/patio/a.jpg"}}})
+
+	rec := s.do(http.MethodDelete, "/admin/projects/patio", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	stored := s.stored(t, "patio")
+	if stored.DeletedAt == nil || stored.DeletedBy != "test-user" {
+		t.Fatalf("deletedAt = %v, deletedBy = %q, want the project in the trash", stored.DeletedAt, stored.DeletedBy)
+	}
+	if got := s.queuedDeletes(t); len(got) != 0 {
+		t.Errorf("images queued for deletion before the purge: %v", got)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{"hidden publicly", http.MethodGet, "/projects/patio", "", http.StatusNotFound},
+		{"hidden from the admin listing", http.MethodGet, "/admin/projects", "", http.StatusOK},
+		{"deleted again", http.MethodDelete, "/admin/projects/patio", "", http.StatusConflict},
+		{"edited", http.MethodPut, "/admin/projects/patio", `{"id":"patio","title":"Big patio","category":"hardscape","status":"active"}`, http.StatusConflict},
+		{"transitioned", http.MethodPost, "/admin/projects/patio/unpublish", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := s.do(tt.method, tt.target, tt.body, map[string]string{testRoleHeader: models.RoleAdmin, "If-Match": "*"})
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				if ids := listedIDs(t, rec); len(ids) != 0 {
+					t.Errorf("listed %v", ids)
+				}
+			}
+		})
+	}
+
+	rec = s.do(http.MethodGet, "/admin/projects/trash", "", nil)
+	var trash ListResponse[TrashedProject]
+	if err := json.Unmarshal(rec.Body.Bytes(), &trash); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	if len(trash.Items) != 1 || trash.Items[0].ID != "patio" {
+		t.Fatalf("trash = %s, want the patio", rec.Body.String())
+	}
+	if want := stored.DeletedAt.Add(48 * time.Hour); !trash.Items[0].PurgeAfter.Equal(want) {
+		t.Errorf("purgeAfter = %v, want %v", trash.Items[0].PurgeAfter, want)
+	}
+}
+
+func TestRestoreProject(t *testing.T) {
+	deletedAt := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name       string
+		deletedAt  *time.Time
+		wantStatus int
+	}{
+		{"from the trash", &deletedAt, http.StatusOK},
+		{"not in the trash", nil, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newProjectServer(t)
+			s.seed(t, models.Project{ID: "patio", Title: "Patio", Category: "hardscape", Status: models.StatusActive, DeletedAt: tt.deletedAt})
+
+			rec := s.do(http.MethodPost, "/admin/projects/patio/restore", "", nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			stored := s.stored(t, "patio")
+			if stored.DeletedAt != nil {
+				t.Errorf("deletedAt = %v after restoring", stored.DeletedAt)
+			}
+			if rec := s.do(http.MethodGet, "/projects/patio", "", nil); rec.Code != http.StatusOK {
+				t.Errorf("public status = %d, want the project visible again", rec.Code)
+			}
+		})
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	s := newProjectServer(t)
+	s.handler.Config.TrashRetention = 24 * time.Hour
+	expired, recent := time.Now().Add(-25*time.Hour), time.Now().Add(-23*time.Hour)
+	image := func(project string) []models.ProjectImage {
+		return []models.ProjectImage{{
+			ID: "a", URL: "http://localhost/storage/projects/" + project + "/a.jpg",
+			StoragePath: "projects/" + project + "/a.jpg", ThumbnailPath: "projects/" + project + "/a_thumb.jpg",
+		}}
+	}
+	s.seed(t, models.Project{ID: "old", Title: "Old", Images: image("old"), DeletedAt: &expired})
+	s.seed(t, models.Project{ID: "new", Title: "New", Images: image("new"), DeletedAt: &recent})
+	s.seed(t, models.Project{ID: "live", Title: "Live", Images: image("live")})
+	if err := s.revisions.Add(context.Background(), &models.Revision{ProjectID: "old", Number: 1}); err != nil {
+		t.Fatalf("Add revision: %v", err)
+	}
+
+	purged, err := s.handler.PurgeTrash(context.Background())
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeTrash = %d, %v, want 1 purged", purged, err)
+	}
+
+	tests := []struct {
+		id   string
+		kept bool
+	}{
+		{"old", false},
+		{"new", true},
+		{"live", true},
+	}
+	for _, tt := range tests {
+		_, err := s.projects.Get(context.Background(), tt.id)
+		if kept := err == nil; kept != tt.kept {
+			t.Errorf("project %s kept = %v, want %v (%v)", tt.id, kept, tt.kept, err)
+		}
+	}
+	if revs, _ := s.revisions.List(context.Background(), "old", 0, 0); len(revs) != 0 {
+		t.Errorf("revisions of the purged project were kept: %v", revs)
+	}
+	if got, want := s.queuedDeletes(t), []string{"projects/old/a.jpg", "projects/old/a_thumb.jpg"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queued deletions = %v, want %v", got, want)
+	}
+
+	if purged, err := s.handler.PurgeTrash(context.Background()); err != nil || purged != 0 {
+		t.Errorf("second PurgeTrash = %d, %v, want nothing left to purge", purged, err)
+	}
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/config"
+	"github.com/networkcaretaker/garden_app/backend/internal/imaging"
+	"github.com/networkcaretaker/garden_app/backend/internal/media"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// defaultRenditionWidth picks the rendition used as an image's main URL,
+// the size the website has always displayed
+const defaultRenditionWidth = 1280
+
+// errMissingFile is returned when the upload has no readable "file" field
+var errMissingFile = errors.New("missing file field")
+
+// safeIDPattern restricts IDs that become part of storage paths
+var safeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// UploadHandler processes image uploads into stored renditions
+type UploadHandler struct {
+	Blobs  blob.Store
+	Media  *media.Library
+	Config *config.Config
+}
+
+// NewUploadHandler creates a new handler instance
+func NewUploadHandler(blobs blob.Store, library *media.Library, cfg *config.Config) *UploadHandler {
+	return &UploadHandler{Blobs: blobs, Media: library, Config: cfg}
+}
+
+// UploadProjectImage handles POST /admin/projects/:id/images
+// Takes a multipart "file" (JPEG, PNG or WebP) with optional "caption" and
+// "alt" fields and returns the ProjectImage to add to the project. The
+// project does not have to exist yet, new projects upload before saving.
+// Re-uploading a known image reuses its stored files and answers 200.
+func (h *UploadHandler) UploadProjectImage(c echo.Context) error {
+	projectID := c.Param("id")
+	if !safeIDPattern.MatchString(projectID) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid project ID"})
+	}
+
+	imageID := newImageID()
+	return h.upload(c, "project-images/"+projectID+"/"+imageID, imageID)
+}
+
+// websiteImageSections are the website content folders images can go to
+var websiteImageSections = map[string]bool{
+	"logo": true, "about": true, "benefits": true, "services": true, "testimonials": true,
+}
+
+// UploadWebsiteImage handles POST /admin/settings/website/images
+// Takes a multipart "file" and the "section" it belongs to, and returns a
+// WebsiteImage with renditions to save into the website settings.
+func (h *UploadHandler) UploadWebsiteImage(c echo.Context) error {
+	section := c.FormValue("section")
+	if !websiteImageSections[section] {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "section must be one of logo, about, benefits, services or testimonials"})
+	}
+
+	imageID := section + "-" + newImageID()
+	return h.upload(c, "website/images/"+section+"/"+imageID, imageID)
+}
+
+// UploadMedia handles POST /admin/media
+// Adds an image to the media library without using it anywhere yet. Takes a
+// multipart "file" with optional "caption", "alt" and comma separated
+// "tags", and returns the media asset (200 when the image was known).
+func (h *UploadHandler) UploadMedia(c echo.Context) error {
+	ctx := context.Background()
+	asset, created, err := h.storeUpload(c, "media/"+newImageID())
+	if err != nil {
+		return uploadError(c, err)
+	}
+	if err := h.Media.Retain(ctx, asset.Image, models.LibraryMediaRef); err != nil {
+		c.Logger().Errorf("Failed to add image %s to the library: %v", asset.Hash, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add image to the library"})
+	}
+	tags := normalizeTags(append(asset.Tags, strings.Split(c.FormValue("tags"), ",")...))
+	caption := formValueOr(c, "caption", asset.Caption)
+	alt := formValueOr(c, "alt", asset.Alt)
+	if asset, err = h.Media.Media.UpdateDetails(ctx, asset.Hash, caption, alt, tags); err != nil {
+		return mediaLookupError(c, err)
+	}
+
+	if created {
+		return c.JSON(http.StatusCreated, asset)
+	}
+	return c.JSON(http.StatusOK, asset)
+}
+
+// upload stores the multipart "file" below folder and responds with the
+// image, or with the files of an identical earlier upload (200)
+func (h *UploadHandler) upload(c echo.Context, folder, imageID string) error {
+	asset, created, err := h.storeUpload(c, folder)
+	if err != nil {
+		return uploadError(c, err)
+	}
+
+	img := asset.Image
+	img.ID = imageID
+	img.Caption = formValueOr(c, "caption", asset.Caption)
+	img.Alt = formValueOr(c, "alt", asset.Alt)
+	if created {
+		return c.JSON(http.StatusCreated, img)
+	}
+	return c.JSON(http.StatusOK, img)
+}
+
+// storeUpload processes and stores the multipart "file" below folder and
+// adds it to the media index. When the same bytes were uploaded before,
+// their asset is returned instead and created is false.
+func (h *UploadHandler) storeUpload(c echo.Context, folder string) (asset *models.MediaAsset, created bool, err error) {
+	ctx := context.Background()
+
+	// 1. Read the upload and look its content hash up in the media index
+	data, err := readUpload(c)
+	if err != nil {
+		return nil, false, err
+	}
+	hash := media.Hash(data)
+
+	asset, err = h.Media.Find(ctx, hash)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return asset, false, err
+	}
+
+	// 2. New image: decode, orient and resize it, dropping the metadata
+	result, err := imaging.Process(bytes.NewReader(data), imaging.Options{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 3. Store every file under its own folder and index it
+	img, err := h.storeImage(ctx, folder, result)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to store image in %s: %w", folder, err)
+	}
+	img.Caption = c.FormValue("caption")
+	img.Alt = c.FormValue("alt")
+	asset, err = h.Media.Register(ctx, hash, img, int64(len(data)))
+	if errors.Is(err, repository.ErrConflict) {
+		// The same bytes were uploaded concurrently, keep the other copy
+		h.removeFiles(ctx, img.StoragePaths())
+		asset, err = h.Media.Find(ctx, hash)
+		return asset, false, err
+	}
+	return asset, err == nil, err
+}
+
+// formValueOr returns the form field, or fallback when it is empty
+func formValueOr(c echo.Context, name, fallback string) string {
+	if value := c.FormValue(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// readUpload reads the multipart "file" field
+func readUpload(c echo.Context) ([]byte, error) {
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, imaging.MaxUploadBytes+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, errMissingFile
+	}
+	if fileHeader.Size > imaging.MaxUploadBytes {
+		return nil, imaging.ErrTooLarge
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, errMissingFile
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// storeImage writes the processed files below folder and describes them as
+// a ProjectImage. Files already written are removed if a later write fails.
+func (h *UploadHandler) storeImage(ctx context.Context, folder string, result *imaging.Result) (models.ProjectImage, error) {
+	var written []string
+	put := func(name string, encoded imaging.Encoded) (string, error) {
+		objectPath := folder + "/" + name + "." + encoded.Extension
+		if err := h.Blobs.Put(ctx, objectPath, bytes.NewReader(encoded.Data), encoded.ContentType); err != nil {
+			return "", err
+		}
+		written = append(written, objectPath)
+		return objectPath, nil
+	}
+	cleanup := func() { h.removeFiles(ctx, written) }
+
+	img := models.ProjectImage{
+		Width:         result.Width,
+		Height:        result.Height,
+		BlurHash:      result.Placeholder.BlurHash,
+		LQIP:          result.Placeholder.LQIP,
+		DominantColor: result.Placeholder.DominantColor,
+	}
+	var err error
+	if img.OriginalPath, err = put("original", result.Original); err != nil {
+		cleanup()
+		return img, err
+	}
+	for _, r := range result.Renditions {
+		objectPath, err := put(fmt.Sprintf("w%d", r.Width), r)
+		if err != nil {
+			cleanup()
+			return img, err
+		}
+		img.Renditions = append(img.Renditions, models.ImageRendition{
+			Width:       r.Width,
+			Height:      r.Height,
+			URL:         h.Blobs.PublicURL(objectPath),
+			StoragePath: objectPath,
+		})
+	}
+	if img.ThumbnailPath, err = put("thumb", result.Thumbnail); err != nil {
+		cleanup()
+		return img, err
+	}
+	img.Thumbnail = h.Blobs.PublicURL(img.ThumbnailPath)
+
+	main := defaultRendition(img.Renditions)
+	img.URL = main.URL
+	img.StoragePath = main.StoragePath
+	return img, nil
+}
+
+// removeFiles deletes files written for an upload that is not used after all
+func (h *UploadHandler) removeFiles(ctx context.Context, paths []string) {
+	for _, objectPath := range paths {
+		if err := h.Blobs.Delete(ctx, objectPath); err != nil {
+			log.Printf("Failed to remove unused upload %s: %v", objectPath, err)
+		}
+	}
+}
+
+// defaultRendition returns the largest rendition no wider than
+// defaultRenditionWidth, or the smallest one when all are wider
+func defaultRendition(renditions []models.ImageRendition) models.ImageRendition {
+	best := renditions[0]
+	for _, r := range renditions[1:] {
+		if r.Width <= defaultRenditionWidth {
+			best = r
+		}
+	}
+	return best
+}
+
+// uploadError maps upload and image processing failures to a response
+func uploadError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errMissingFile):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, imaging.ErrTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+	case errors.Is(err, imaging.ErrInvalidImage):
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Could not read image, the file may be corrupt"})
+	default:
+		c.Logger().Errorf("Failed to store upload: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to store image"})
+	}
+}
+
+const imageIDAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// newImageID generates an image ID in the shape the admin PWA has always
+// used: upload time in milliseconds and 9 random characters
+func newImageID() string {
+	suffix := make([]byte, 9)
+	for i := range suffix {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(imageIDAlphabet))))
+		suffix[i] = imageIDAlphabet[n.Int64()]
+	}
+	return fmt.Sprintf("%d-%s", time.Now().UnixMilli(), suffix)
+}
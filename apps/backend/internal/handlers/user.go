@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// currentUser returns the UID and role AuthMiddleware stored on the request
+func currentUser(c echo.Context) (uid, role string) {
+	uid, _ = c.Get("uid").(string)
+	role, _ = c.Get("role").(string)
+	if role == "" {
+		role = models.RoleAdmin
+	}
+	return uid, role
+}
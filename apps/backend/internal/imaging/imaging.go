@@ -0,0 +1,279 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Registers the WebP decoder
+)
+
+// Limits on what the pipeline accepts
+const (
+	MaxUploadBytes = 25 << 20   // 25 MB
+	MaxPixels      = 60_000_000 // Guards against decompression bombs
+)
+
+// DefaultWidths are the rendition widths generated for responsive images
+var DefaultWidths = []int{320, 640, 1280, 1920}
+
+// DefaultThumbnailSize is the edge length of the square thumbnail
+const DefaultThumbnailSize = 300
+
+// Encoding qualities: the original JPEG is kept close to the upload, the
+// WebP renditions and thumbnails are what pages load, so they trade a
+// little detail for size
+const (
+	OriginalQuality  = 90
+	RenditionQuality = 80
+)
+
+var (
+	// ErrUnsupportedFormat is returned for anything but JPEG, PNG and WebP
+	ErrUnsupportedFormat = errors.New("unsupported image format, use JPEG, PNG or WebP")
+	// ErrTooLarge is returned when an image exceeds MaxUploadBytes or MaxPixels
+	ErrTooLarge = errors.New("image is too large")
+	// ErrInvalidImage is returned when a supported image cannot be decoded
+	ErrInvalidImage = errors.New("could not read image, the file may be corrupt")
+)
+
+// Encoded is one generated file
+type Encoded struct {
+	Width       int
+	Height      int
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// Result holds every file produced for an upload
+type Result struct {
+	Width       int // Dimensions of the upright original
+	Height      int
+	Original    Encoded // Full size, upright, with all metadata (EXIF, GPS) stripped
+	Renditions  []Encoded
+	Thumbnail   Encoded
+	Placeholder Placeholder
+}
+
+// Options controls which files Process generates
+type Options struct {
+	Widths        []int // Rendition widths; widths beyond the original are skipped
+	ThumbnailSize int
+}
+
+// Process decodes a JPEG, PNG or WebP upload, turns it upright according
+// to its EXIF orientation, generates renditions and a square thumbnail and
+// computes the loading placeholders. Every output is re-encoded from
+// pixels, so no metadata from the upload (GPS position included) survives.
+// Renditions and thumbnails are lossy WebP at RenditionQuality, encoded
+// with libwebp; without it they fall back to larger lossless WebP.
+func Process(r io.Reader, opts Options) (*Result, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxUploadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxUploadBytes {
+		return nil, ErrTooLarge
+	}
+
+	img, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+
+	// Keep a full size copy; transparent images stay PNG, everything else becomes JPEG
+	if !img.Opaque() {
+		result.Original, err = encodePNG(img)
+	} else {
+		result.Original, err = encodeJPEG(img, OriginalQuality)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, width := range renditionWidths(opts.Widths, result.Width) {
+		encoded, err := encodeRendition(resize(img, width))
+		if err != nil {
+			return nil, err
+		}
+		result.Renditions = append(result.Renditions, encoded)
+	}
+
+	thumbnailSize := opts.ThumbnailSize
+	if thumbnailSize <= 0 {
+		thumbnailSize = DefaultThumbnailSize
+	}
+	if result.Thumbnail, err = encodeRendition(squareThumbnail(img, thumbnailSize)); err != nil {
+		return nil, err
+	}
+	if result.Placeholder, err = placeholders(img); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// decode checks the format and size of an encoded image, decodes it and
+// turns it upright according to its EXIF orientation
+func decode(data []byte) (*image.NRGBA, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png" && format != "webp") {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	return orient(toNRGBA(decoded), orientation(data, format)), nil
+}
+
+// renditionWidths keeps the widths smaller than the original, plus the
+// original width itself when it falls short of the largest requested width
+func renditionWidths(widths []int, originalWidth int) []int {
+	if len(widths) == 0 {
+		widths = DefaultWidths
+	}
+	var out []int
+	largest := 0
+	for _, w := range widths {
+		if w < originalWidth {
+			out = append(out, w)
+		}
+		if w > largest {
+			largest = w
+		}
+	}
+	if originalWidth <= largest {
+		out = append(out, originalWidth)
+	}
+	return out
+}
+
+func toNRGBA(src image.Image) *image.NRGBA {
+	if img, ok := src.(*image.NRGBA); ok {
+		return img
+	}
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// orient applies an EXIF orientation so the image is upright
+func orient(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 { // 5-8 swap width and height
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // Rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // Mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // Transposed
+				dx, dy = y, x
+			case 6: // Rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // Transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // Rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// resize scales img to width, keeping its aspect ratio
+func resize(img *image.NRGBA, width int) *image.NRGBA {
+	b := img.Bounds()
+	if width >= b.Dx() {
+		return img
+	}
+	height := max(1, b.Dy()*width/b.Dx())
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// squareThumbnail crops the centre square of img and scales it to size
+func squareThumbnail(img *image.NRGBA, size int) *image.NRGBA {
+	b := img.Bounds()
+	edge := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, edge, edge).Add(image.Pt(b.Min.X+(b.Dx()-edge)/2, b.Min.Y+(b.Dy()-edge)/2))
+	size = min(size, edge)
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+	return dst
+}
+
+// errNoLossyWebP is returned by encodeLossyWebP when libwebp is missing
+var errNoLossyWebP = errors.New("lossy WebP encoding needs libwebp")
+
+var warnLossless sync.Once
+
+// encodeRendition encodes a resized copy for pages as lossy WebP, falling
+// back to lossless WebP when libwebp is not installed
+func encodeRendition(img *image.NRGBA) (Encoded, error) {
+	data, err := encodeLossyWebP(img, RenditionQuality)
+	if errors.Is(err, errNoLossyWebP) {
+		warnLossless.Do(func() {
+			log.Printf("libwebp is not available, image renditions are encoded lossless and will be larger")
+		})
+		return encodeWebP(img)
+	}
+	if err != nil {
+		return Encoded{}, fmt.Errorf("failed to encode WebP: %w", err)
+	}
+	return Encoded{Width: img.Bounds().Dx(), Height: img.Bounds().Dy(), Data: data, ContentType: "image/webp", Extension: "webp"}, nil
+}
+
+func encodeWebP(img *image.NRGBA) (Encoded, error) {
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return Encoded{}, fmt.Errorf("failed to encode WebP: %w", err)
+	}
+	return Encoded{Width: img.Bounds().Dx(), Height: img.Bounds().Dy(), Data: buf.Bytes(), ContentType: "image/webp", Extension: "webp"}, nil
+}
+
+func encodeJPEG(img *image.NRGBA, quality int) (Encoded, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return Encoded{}, fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+	return Encoded{Width: img.Bounds().Dx(), Height: img.Bounds().Dy(), Data: buf.Bytes(), ContentType: "image/jpeg", Extension: "jpg"}, nil
+}
+
+func encodePNG(img *image.NRGBA) (Encoded, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return Encoded{}, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return Encoded{Width: img.Bounds().Dx(), Height: img.Bounds().Dy(), Data: buf.Bytes(), ContentType: "image/png", Extension: "png"}, nil
+}
@@ -0,0 +1,275 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"reflect"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// testPhoto returns a w×h opaque image whose left half is red and right half blue
+func testPhoto(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 200, G: 30, B: 30, A: 255}
+			if x >= w/2 {
+				c = color.NRGBA{R: 30, G: 30, B: 200, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// exifTIFF returns a little-endian TIFF structure with an orientation tag
+// and a GPS latitude reference, standing in for a phone's EXIF block
+func exifTIFF(orientation int) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	entries := []struct {
+		tag   uint16
+		typ   uint16
+		value uint32
+	}{
+		{exifOrientationTag, 3, uint32(orientation)},
+		{0x0001, 2, binary.LittleEndian.Uint32([]byte("N\x00\x00\x00"))}, // GPSLatitudeRef
+	}
+	tiff = binary.LittleEndian.AppendUint16(tiff, uint16(len(entries)))
+	for _, e := range entries {
+		tiff = binary.LittleEndian.AppendUint16(tiff, e.tag)
+		tiff = binary.LittleEndian.AppendUint16(tiff, e.typ)
+		tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+		tiff = binary.LittleEndian.AppendUint32(tiff, e.value)
+	}
+	return append(tiff, 0, 0, 0, 0)
+}
+
+// jpegWithExif encodes img as a JPEG carrying an APP1 Exif segment
+func jpegWithExif(t *testing.T, img image.Image, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	payload := append([]byte("Exif\x00\x00"), exifTIFF(orientation)...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func encodedPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestOrientation(t *testing.T) {
+	plain := encodedPNG(t, testPhoto(2, 2))
+
+	// A PNG with an eXIf chunk right after IHDR
+	tiff := exifTIFF(3)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(tiff)))
+	chunk = append(chunk, "eXIf"...)
+	chunk = append(chunk, tiff...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	ihdrEnd := 8 + 12 + 13
+	pngExif := append(append(append([]byte{}, plain[:ihdrEnd]...), chunk...), plain[ihdrEnd:]...)
+
+	// A WebP container with just an EXIF chunk
+	webpExif := append([]byte("EXIF"), binary.LittleEndian.AppendUint32(nil, uint32(len(tiff)))...)
+	webpExif = append(webpExif, tiff...)
+	webpExif = append([]byte("RIFF\x00\x00\x00\x00WEBP"), webpExif...)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		want   int
+	}{
+		{"jpeg rotated", jpegWithExif(t, testPhoto(4, 2), 6), "jpeg", 6},
+		{"jpeg mirrored", jpegWithExif(t, testPhoto(4, 2), 2), "jpeg", 2},
+		{"jpeg out of range", jpegWithExif(t, testPhoto(4, 2), 9), "jpeg", 1},
+		{"png eXIf chunk", pngExif, "png", 3},
+		{"png without EXIF", plain, "png", 1},
+		{"webp EXIF chunk", webpExif, "webp", 3},
+		{"garbage", []byte("not an image"), "jpeg", 1},
+	}
+	for _, tt := range tests {
+		if got := orientation(tt.data, tt.format); got != tt.want {
+			t.Errorf("%s: orientation = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3×2 image whose pixels are numbered 0-5 in reading order
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		src.SetNRGBA(i%3, i/3, color.NRGBA{R: uint8(i), A: 255})
+	}
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8 // Pixel numbers row by row once upright
+	}{
+		{1, [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{2, [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{3, [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{4, [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+		{5, [][]uint8{{0, 3}, {1, 4}, {2, 5}}},
+		{6, [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{7, [][]uint8{{5, 2}, {4, 1}, {3, 0}}},
+		{8, [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+	}
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+		var got [][]uint8
+		for y := 0; y < dst.Bounds().Dy(); y++ {
+			var row []uint8
+			for x := 0; x < dst.Bounds().Dx(); x++ {
+				row = append(row, dst.NRGBAAt(x, y).R)
+			}
+			got = append(got, row)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("orientation %d: got %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func TestRenditionWidths(t *testing.T) {
+	tests := []struct {
+		widths   []int
+		original int
+		want     []int
+	}{
+		{nil, 4000, []int{320, 640, 1280, 1920}},
+		{nil, 1000, []int{320, 640, 1000}},
+		{nil, 1920, []int{320, 640, 1280, 1920}},
+		{nil, 200, []int{200}},
+		{[]int{100, 500}, 300, []int{100, 300}},
+	}
+	for _, tt := range tests {
+		if got := renditionWidths(tt.widths, tt.original); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("renditionWidths(%v, %d) = %v, want %v", tt.widths, tt.original, got, tt.want)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	// A landscape photo stored sideways, as phones do, with GPS metadata
+	upload := jpegWithExif(t, testPhoto(800, 400), 6)
+
+	result, err := Process(bytes.NewReader(upload), Options{Widths: []int{320, 640}, ThumbnailSize: 100})
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if result.Width != 400 || result.Height != 800 {
+		t.Errorf("size = %d×%d, want the upright 400×800", result.Width, result.Height)
+	}
+
+	original := result.Original
+	if original.ContentType != "image/jpeg" || original.Width != 400 || original.Height != 800 {
+		t.Errorf("original = %s %d×%d, want an upright JPEG", original.ContentType, original.Width, original.Height)
+	}
+	if bytes.Contains(original.Data, []byte("Exif")) {
+		t.Errorf("original kept the EXIF block")
+	}
+	if o := orientation(original.Data, "jpeg"); o != 1 {
+		t.Errorf("original orientation = %d, want no orientation left to apply", o)
+	}
+
+	// Renditions are lossy WebP when libwebp is installed
+	wantChunk := "VP8 "
+	if _, err := encodeLossyWebP(testPhoto(2, 2), RenditionQuality); errors.Is(err, errNoLossyWebP) {
+		wantChunk = "VP8L"
+	}
+	files := append(append([]Encoded{}, result.Renditions...), result.Thumbnail)
+	wantSizes := [][2]int{{320, 640}, {400, 800}, {100, 100}}
+	if len(files) != len(wantSizes) {
+		t.Fatalf("got %d renditions, want 2 and a thumbnail", len(result.Renditions))
+	}
+	for i, f := range files {
+		if f.ContentType != "image/webp" || f.Extension != "webp" {
+			t.Errorf("file %d is %s (.%s), want WebP", i, f.ContentType, f.Extension)
+		}
+		if len(f.Data) < 16 || string(f.Data[12:16]) != wantChunk {
+			t.Errorf("file %d is not encoded with a %q chunk", i, wantChunk)
+		}
+		cfg, err := webp.DecodeConfig(bytes.NewReader(f.Data))
+		if err != nil {
+			t.Fatalf("file %d: %v", i, err)
+		}
+		if cfg.Width != wantSizes[i][0] || cfg.Height != wantSizes[i][1] || f.Width != cfg.Width || f.Height != cfg.Height {
+			t.Errorf("file %d is %d×%d (recorded %d×%d), want %d×%d", i, cfg.Width, cfg.Height, f.Width, f.Height, wantSizes[i][0], wantSizes[i][1])
+		}
+	}
+}
+
+func TestProcessKeepsTransparency(t *testing.T) {
+	img := testPhoto(50, 50)
+	img.SetNRGBA(0, 0, color.NRGBA{})
+
+	result, err := Process(bytes.NewReader(encodedPNG(t, img)), Options{})
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if result.Original.ContentType != "image/png" {
+		t.Errorf("original is %s, want PNG to keep transparency", result.Original.ContentType)
+	}
+	decoded, err := webp.Decode(bytes.NewReader(result.Renditions[0].Data))
+	if err != nil {
+		t.Fatalf("decode rendition: %v", err)
+	}
+	if _, _, _, a := decoded.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("rendition corner alpha = %d, want transparent", a)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, testPhoto(4, 4), nil); err != nil {
+		t.Fatalf("gif.Encode: %v", err)
+	}
+
+	// A PNG header claiming more pixels than MaxPixels
+	bomb := encodedPNG(t, testPhoto(2, 2))
+	binary.BigEndian.PutUint32(bomb[16:20], 10000)
+	binary.BigEndian.PutUint32(bomb[20:24], 10000)
+	binary.BigEndian.PutUint32(bomb[29:33], crc32.ChecksumIEEE(bomb[12:29]))
+
+	truncated := encodedPNG(t, testPhoto(64, 64))
+	truncated = truncated[:len(truncated)-20]
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"gif", gifData.Bytes(), ErrUnsupportedFormat},
+		{"text", []byte("hello"), ErrUnsupportedFormat},
+		{"decompression bomb", bomb, ErrTooLarge},
+		{"truncated", truncated, ErrInvalidImage},
+		{"over the upload limit", make([]byte, MaxUploadBytes+1), ErrTooLarge},
+	}
+	for _, tt := range tests {
+		if _, err := Process(bytes.NewReader(tt.data), Options{}); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: Process = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+
+	"github.com/buckket/go-blurhash"
+)
+
+// Placeholder holds what the website shows while an image loads
+type Placeholder struct {
+	BlurHash      string
+	LQIP          string // Tiny JPEG as a data URI
+	DominantColor string // "#rrggbb"
+}
+
+const (
+	blurHashWidth      = 32 // Blurhash only needs a few pixels per component
+	blurHashComponents = 4
+	lqipWidth          = 16
+	colorSampleWidth   = 64
+)
+
+// Analyze decodes an image and computes its placeholders, used to backfill
+// images uploaded before the pipeline computed them
+func Analyze(r io.Reader) (Placeholder, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxUploadBytes+1))
+	if err != nil {
+		return Placeholder{}, err
+	}
+	if len(data) > MaxUploadBytes {
+		return Placeholder{}, ErrTooLarge
+	}
+	img, err := decode(data)
+	if err != nil {
+		return Placeholder{}, err
+	}
+	return placeholders(img)
+}
+
+// placeholders computes the blurhash, LQIP and dominant colour of img
+func placeholders(img *image.NRGBA) (Placeholder, error) {
+	var p Placeholder
+
+	// Keep the blurhash components in proportion to the aspect ratio
+	small := resize(img, blurHashWidth)
+	x, y := blurHashComponents, blurHashComponents
+	if small.Bounds().Dx() > small.Bounds().Dy() {
+		y = max(1, blurHashComponents*small.Bounds().Dy()/small.Bounds().Dx())
+	} else {
+		x = max(1, blurHashComponents*small.Bounds().Dx()/small.Bounds().Dy())
+	}
+	hash, err := blurhash.Encode(x, y, small)
+	if err != nil {
+		return p, fmt.Errorf("failed to compute blurhash: %w", err)
+	}
+	p.BlurHash = hash
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resize(img, lqipWidth), &jpeg.Options{Quality: 50}); err != nil {
+		return p, fmt.Errorf("failed to encode LQIP: %w", err)
+	}
+	p.LQIP = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	p.DominantColor = dominantColor(resize(img, colorSampleWidth))
+	return p, nil
+}
+
+// dominantColor buckets the pixels into 4096 colours and returns the
+// average of the most common bucket. Transparent pixels are ignored.
+func dominantColor(img *image.NRGBA) string {
+	type bucket struct{ count, r, g, b int }
+	buckets := make(map[int]*bucket)
+	var best *bucket
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		r, g, b, a := int(img.Pix[i]), int(img.Pix[i+1]), int(img.Pix[i+2]), img.Pix[i+3]
+		if a < 128 {
+			continue
+		}
+		key := r>>4<<8 | g>>4<<4 | b>>4
+		bk := buckets[key]
+		if bk == nil {
+			bk = &bucket{}
+			buckets[key] = bk
+		}
+		bk.count++
+		bk.r += r
+		bk.g += g
+		bk.b += b
+		if best == nil || bk.count > best.count {
+			best = bk
+		}
+	}
+	if best == nil {
+		return "#ffffff"
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"github.com/buckket/go-blurhash"
+)
+
+func TestAnalyze(t *testing.T) {
+	result, err := Analyze(bytes.NewReader(encodedPNG(t, testPhoto(120, 60))))
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	if x, y, err := blurhash.Components(result.BlurHash); err != nil || x != 4 || y != 2 {
+		t.Errorf("blurhash %q has %d×%d components (%v), want 4×2 for a 2:1 image", result.BlurHash, x, y, err)
+	}
+
+	const prefix = "data:image/jpeg;base64,"
+	if !strings.HasPrefix(result.LQIP, prefix) {
+		t.Fatalf("LQIP = %q, want a JPEG data URI", result.LQIP)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(result.LQIP, prefix))
+	if err != nil {
+		t.Fatalf("LQIP is not base64: %v", err)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width != lqipWidth || cfg.Height != lqipWidth/2 {
+		t.Errorf("LQIP is %d×%d (%v), want %d×%d", cfg.Width, cfg.Height, err, lqipWidth, lqipWidth/2)
+	}
+
+	if _, err := Analyze(strings.NewReader("not an image")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Analyze of text = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestProcessComputesPlaceholders(t *testing.T) {
+	result, err := Process(bytes.NewReader(jpegWithExif(t, testPhoto(80, 40), 6)), Options{})
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	// Placeholders follow the upright image, which is portrait
+	if x, y, err := blurhash.Components(result.Placeholder.BlurHash); err != nil || x != 2 || y != 4 {
+		t.Errorf("blurhash has %d×%d components (%v), want 2×4", x, y, err)
+	}
+	if result.Placeholder.LQIP == "" || result.Placeholder.DominantColor == "" {
+		t.Errorf("placeholder = %+v, want every field set", result.Placeholder)
+	}
+}
+
+func TestDominantColor(t *testing.T) {
+	fill := func(img *image.NRGBA, from, to int, c color.NRGBA) {
+		for i := from; i < to; i++ {
+			img.SetNRGBA(i%10, i/10, c)
+		}
+	}
+	green := color.NRGBA{R: 40, G: 140, B: 60, A: 255}
+	red := color.NRGBA{R: 220, G: 20, B: 20, A: 255}
+
+	tests := []struct {
+		name  string
+		paint func(img *image.NRGBA)
+		want  string
+	}{
+		{"single colour", func(img *image.NRGBA) { fill(img, 0, 100, green) }, "#288c3c"},
+		{"most common colour wins", func(img *image.NRGBA) {
+			fill(img, 0, 60, green)
+			fill(img, 60, 100, red)
+		}, "#288c3c"},
+		{"transparent pixels are ignored", func(img *image.NRGBA) {
+			fill(img, 0, 90, color.NRGBA{R: 255, G: 255, B: 255, A: 0})
+			fill(img, 90, 100, red)
+		}, "#dc1414"},
+		{"fully transparent", func(img *image.NRGBA) {}, "#ffffff"},
+	}
+	for _, tt := range tests {
+		img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+		tt.paint(img)
+		if got := dominantColor(img); got != tt.want {
+			t.Errorf("%s: dominantColor = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
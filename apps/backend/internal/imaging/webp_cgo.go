@@ -0,0 +1,91 @@
+//go:build cgo
+
+package imaging
+
+/*
+#cgo linux LDFLAGS: -ldl
+#include <dlfcn.h>
+#include <stddef.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+// Signatures from libwebp's encode.h. The library is loaded at runtime so
+// the build needs neither its headers nor a link-time dependency.
+typedef size_t (*webp_encode_rgba_fn)(const uint8_t* rgba, int width, int height, int stride, float quality, uint8_t** output);
+typedef void (*webp_free_fn)(void* ptr);
+
+static webp_encode_rgba_fn webp_encode_rgba;
+static webp_free_fn webp_free;
+
+static int load_libwebp(const char* name) {
+	void* lib = dlopen(name, RTLD_NOW | RTLD_LOCAL);
+	if (lib == NULL) {
+		return 0;
+	}
+	webp_encode_rgba = (webp_encode_rgba_fn)dlsym(lib, "WebPEncodeRGBA");
+	webp_free = (webp_free_fn)dlsym(lib, "WebPFree");
+	if (webp_encode_rgba == NULL || webp_free == NULL) {
+		webp_encode_rgba = NULL;
+		webp_free = NULL;
+		dlclose(lib);
+		return 0;
+	}
+	return 1;
+}
+
+static size_t encode_rgba(const uint8_t* rgba, int width, int height, int stride, float quality, uint8_t** output) {
+	return webp_encode_rgba(rgba, width, height, stride, quality, output);
+}
+
+static void free_output(uint8_t* output) {
+	webp_free(output);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"unsafe"
+)
+
+// libwebpNames are the shared library names tried, in order
+var libwebpNames = []string{"libwebp.so.7", "libwebp.so", "libwebp.7.dylib", "libwebp.dylib"}
+
+var (
+	loadLibwebp sync.Once
+	hasLibwebp  bool
+)
+
+// encodeLossyWebP encodes img as a lossy WebP with libwebp, keeping any
+// transparency. It returns errNoLossyWebP when libwebp is not installed.
+func encodeLossyWebP(img *image.NRGBA, quality int) ([]byte, error) {
+	loadLibwebp.Do(func() {
+		for _, name := range libwebpNames {
+			cname := C.CString(name)
+			loaded := C.load_libwebp(cname) == 1
+			C.free(unsafe.Pointer(cname))
+			if loaded {
+				hasLibwebp = true
+				return
+			}
+		}
+	})
+	if !hasLibwebp {
+		return nil, errNoLossyWebP
+	}
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, errors.New("cannot encode an empty image")
+	}
+	var output *C.uint8_t
+	size := C.encode_rgba((*C.uint8_t)(unsafe.Pointer(&img.Pix[img.PixOffset(bounds.Min.X, bounds.Min.Y)])),
+		C.int(bounds.Dx()), C.int(bounds.Dy()), C.int(img.Stride), C.float(quality), &output)
+	if size == 0 || output == nil {
+		return nil, errors.New("libwebp failed to encode the image")
+	}
+	defer C.free_output(output)
+	return C.GoBytes(unsafe.Pointer(output), C.int(size)), nil
+}
@@ -0,0 +1,10 @@
+//go:build !cgo
+
+package imaging
+
+import "image"
+
+// encodeLossyWebP needs libwebp, which builds without cgo cannot load
+func encodeLossyWebP(img *image.NRGBA, quality int) ([]byte, error) {
+	return nil, errNoLossyWebP
+}
@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// TypeDeleteBlob deletes the storage object in the "path" payload field
+const TypeDeleteBlob = "blob.delete"
+
+// DeleteBlob returns the handler for TypeDeleteBlob jobs.
+// Objects that are already gone count as deleted.
+func DeleteBlob(store blob.Store) Handler {
+	return func(ctx context.Context, job models.Job) error {
+		path := job.Payload["path"]
+		if path == "" {
+			return fmt.Errorf("job has no path")
+		}
+		if err := store.Delete(ctx, path); err != nil && !errors.Is(err, blob.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+}
+
+// EnqueueDeleteBlob schedules the deletion of a storage object
+func (q *Queue) EnqueueDeleteBlob(ctx context.Context, path string) error {
+	_, err := q.Enqueue(ctx, TypeDeleteBlob, map[string]string{"path": path})
+	return err
+}
+
+// DeletingBlob reports whether a worker is deleting a storage object right now
+func (q *Queue) DeletingBlob(ctx context.Context, path string) (bool, error) {
+	running, err := q.repo.List(ctx, repository.JobFilter{Type: TypeDeleteBlob, Status: models.JobRunning, Payload: map[string]string{"path": path}}, 1)
+	if err != nil {
+		return false, err
+	}
+	return len(running) > 0, nil
+}
+
+// CancelDeleteBlob drops every queued deletion of a storage object, for
+// files that are in use again. It returns ErrRunning when a worker is
+// already deleting the object.
+func (q *Queue) CancelDeleteBlob(ctx context.Context, path string) error {
+	queued, err := q.repo.List(ctx, repository.JobFilter{Type: TypeDeleteBlob, Payload: map[string]string{"path": path}}, 0)
+	if err != nil {
+		return err
+	}
+	for _, job := range queued {
+		if err := q.Cancel(ctx, job.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Defaults for the queue's retry policy
+const (
+	DefaultMaxAttempts = 8
+	DefaultBaseDelay   = 30 * time.Second
+	DefaultMaxDelay    = 6 * time.Hour
+	DefaultLease       = 5 * time.Minute
+	batchSize          = 20
+)
+
+// ErrNotDead is returned when retrying a job that has not been dead-lettered
+var ErrNotDead = errors.New("job is not dead")
+
+// ErrRunning is returned when cancelling a job a worker is running
+var ErrRunning = errors.New("job is running")
+
+// Handler performs a job. Returning an error schedules a retry.
+type Handler func(ctx context.Context, job models.Job) error
+
+// Queue runs persisted jobs with retries and exponential backoff. Jobs that
+// keep failing are dead-lettered until an admin retries them.
+type Queue struct {
+	repo     repository.JobRepository
+	handlers map[string]Handler
+	wake     chan struct{}
+
+	MaxAttempts int
+	BaseDelay   time.Duration // Delay before the first retry, doubled for each further attempt
+	MaxDelay    time.Duration
+	Lease       time.Duration // How long a claimed job may go without renewal before another worker picks it up
+}
+
+// NewQueue creates a queue backed by repo with the default retry policy
+func NewQueue(repo repository.JobRepository) *Queue {
+	return &Queue{
+		repo:        repo,
+		handlers:    make(map[string]Handler),
+		wake:        make(chan struct{}, 1),
+		MaxAttempts: DefaultMaxAttempts,
+		BaseDelay:   DefaultBaseDelay,
+		MaxDelay:    DefaultMaxDelay,
+		Lease:       DefaultLease,
+	}
+}
+
+// Register sets the handler for a job type. Call before Start.
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.handlers[jobType] = handler
+}
+
+// Enqueue persists a job to run as soon as a worker is free
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload map[string]string) (*models.Job, error) {
+	return q.EnqueueAt(ctx, jobType, payload, time.Now())
+}
+
+// EnqueueAt persists a job that becomes due at runAt
+func (q *Queue) EnqueueAt(ctx context.Context, jobType string, payload map[string]string, runAt time.Time) (*models.Job, error) {
+	now := time.Now()
+	job := &models.Job{
+		Type:        jobType,
+		Payload:     payload,
+		Status:      models.JobPending,
+		MaxAttempts: q.MaxAttempts,
+		RunAt:       runAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := q.repo.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	q.notify()
+	return job, nil
+}
+
+// Start processes due jobs every interval, and right after Enqueue, until ctx is done
+func (q *Queue) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := q.RunDue(ctx); err != nil {
+			log.Printf("Job queue: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// RunDue claims and runs every job that is due, returning how many ran
+func (q *Queue) RunDue(ctx context.Context) (int, error) {
+	ran := 0
+	for {
+		jobs, err := q.repo.ClaimDue(ctx, time.Now(), batchSize, q.Lease)
+		if err != nil {
+			return ran, fmt.Errorf("failed to claim jobs: %w", err)
+		}
+		for _, job := range jobs {
+			q.run(ctx, job)
+		}
+		ran += len(jobs)
+		if len(jobs) < batchSize {
+			return ran, nil
+		}
+	}
+}
+
+// run performs a claimed job and records the outcome. The outcome is only
+// written while the job is still this worker's claim: once the lease is lost
+// another worker owns the job and its result.
+func (q *Queue) run(ctx context.Context, job models.Job) {
+	err := errors.New("no handler registered for job type " + job.Type)
+	if handler, ok := q.handlers[job.Type]; ok {
+		renewing, stop := context.WithCancel(ctx)
+		go q.keepLease(renewing, job)
+		err = call(ctx, handler, job)
+		stop()
+	}
+
+	if err == nil {
+		if err := q.repo.DeleteClaimed(ctx, job.ID, job.Attempts); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Job queue: failed to remove finished job %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	now := time.Now()
+	job.LastError = err.Error()
+	job.UpdatedAt = now
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = models.JobDead
+		log.Printf("Job queue: %s job %s is dead after %d attempts: %v", job.Type, job.ID, job.Attempts, err)
+	} else {
+		job.Status = models.JobPending
+		job.RunAt = now.Add(q.backoff(job.Attempts))
+	}
+	if err := q.repo.SaveClaimed(ctx, &job, job.Attempts); err != nil {
+		log.Printf("Job queue: failed to save job %s: %v", job.ID, err)
+	}
+}
+
+// call runs handler, turning a panic into an error so it counts as a
+// failed attempt instead of taking the worker down
+func call(ctx context.Context, handler Handler, job models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job queue: %s job %s panicked: %v\n%s", job.Type, job.ID, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// keepLease renews the lease of a running job every third of the lease
+// until ctx is done, so long jobs are not claimed again by another worker
+func (q *Queue) keepLease(ctx context.Context, job models.Job) {
+	ticker := time.NewTicker(q.Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := q.repo.ExtendLease(ctx, job.ID, job.Attempts, now.Add(q.Lease)); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Job queue: failed to renew the lease of %s job %s: %v", job.Type, job.ID, err)
+				}
+				if errors.Is(err, repository.ErrConflict) || errors.Is(err, repository.ErrNotFound) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= q.MaxDelay {
+			return q.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Retry gives a dead job a fresh set of attempts, running it right away
+func (q *Queue) Retry(ctx context.Context, id string) (*models.Job, error) {
+	job, err := q.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobDead {
+		return nil, ErrNotDead
+	}
+
+	now := time.Now()
+	job.Status = models.JobPending
+	job.Attempts = 0
+	job.MaxAttempts = q.MaxAttempts
+	job.RunAt = now
+	job.UpdatedAt = now
+	if err := q.repo.Save(ctx, job); err != nil {
+		return nil, err
+	}
+	q.notify()
+	return job, nil
+}
+
+// Cancel removes a pending or dead job before it runs. The check and the
+// delete are one step, so a job a worker claims meanwhile is never dropped.
+func (q *Queue) Cancel(ctx context.Context, id string) error {
+	err := q.repo.DeleteUnlessRunning(ctx, id)
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrRunning
+	}
+	return err
+}
+
+// Get returns a single job or repository.ErrNotFound
+func (q *Queue) Get(ctx context.Context, id string) (*models.Job, error) {
+	return q.repo.Get(ctx, id)
+}
+
+// List returns queued jobs matching filter, oldest first
+func (q *Queue) List(ctx context.Context, filter repository.JobFilter, limit int) ([]models.Job, error) {
+	return q.repo.List(ctx, filter, limit)
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
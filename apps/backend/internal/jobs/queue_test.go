@@ -0,0 +1,311 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+func newTestQueue() (*Queue, *repository.MemoryJobRepository) {
+	repo := repository.NewMemoryJobRepository()
+	return NewQueue(repo), repo
+}
+
+func TestBackoff(t *testing.T) {
+	q, _ := newTestQueue()
+	q.BaseDelay = time.Second
+	q.MaxDelay = 10 * time.Second
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{30, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := q.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	failure := errors.New("storage unavailable")
+
+	tests := []struct {
+		name        string
+		handler     Handler
+		attempts    int // Attempts made before this run
+		wantStatus  string
+		wantRemoved bool
+	}{
+		{"success removes the job", func(context.Context, models.Job) error { return nil }, 0, "", true},
+		{"failure retries later", func(context.Context, models.Job) error { return failure }, 0, models.JobPending, false},
+		{"last failure dead-letters", func(context.Context, models.Job) error { return failure }, 2, models.JobDead, false},
+		{"panic retries later", func(context.Context, models.Job) error { panic(failure) }, 0, models.JobPending, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			q, repo := newTestQueue()
+			q.MaxAttempts = 3
+			q.Register("test", tt.handler)
+
+			job, err := q.Enqueue(ctx, "test", map[string]string{"n": "1"})
+			if err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			job.Attempts = tt.attempts
+			if err := repo.Save(ctx, job); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			before := time.Now()
+			if ran, err := q.RunDue(ctx); err != nil || ran != 1 {
+				t.Fatalf("RunDue = %d, %v, want 1 job run", ran, err)
+			}
+			stored, err := q.Get(ctx, job.ID)
+			if tt.wantRemoved {
+				if !errors.Is(err, repository.ErrNotFound) {
+					t.Errorf("Get = %v, want the finished job removed", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if stored.Status != tt.wantStatus || stored.Attempts != tt.attempts+1 || !strings.HasSuffix(stored.LastError, failure.Error()) {
+				t.Errorf("job is %s after %d attempts with error %q, want %s after %d attempts",
+					stored.Status, stored.Attempts, stored.LastError, tt.wantStatus, tt.attempts+1)
+			}
+			if tt.wantStatus == models.JobPending && stored.RunAt.Before(before.Add(q.backoff(stored.Attempts))) {
+				t.Errorf("retry is due at %v, before the backoff", stored.RunAt)
+			}
+		})
+	}
+}
+
+func TestRunAfterLosingTheLease(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"success", nil},
+		{"failure", errors.New("storage unavailable")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			q, repo := newTestQueue()
+
+			// The lease runs out mid-job and another worker claims the job
+			q.Register("test", func(ctx context.Context, job models.Job) error {
+				job.Attempts++
+				if err := repo.Save(ctx, &job); err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+				return tt.err
+			})
+			job, err := q.Enqueue(ctx, "test", nil)
+			if err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			if _, err := q.RunDue(ctx); err != nil {
+				t.Fatalf("RunDue: %v", err)
+			}
+
+			stored, err := q.Get(ctx, job.ID)
+			if err != nil {
+				t.Fatalf("Get = %v, the other worker's job was removed", err)
+			}
+			if stored.Status != models.JobRunning || stored.Attempts != 2 || stored.LastError != "" {
+				t.Errorf("job is %s after %d attempts with error %q, want the other worker's claim untouched",
+					stored.Status, stored.Attempts, stored.LastError)
+			}
+		})
+	}
+}
+
+func TestRunKeepsLeaseOfLongJobs(t *testing.T) {
+	ctx := context.Background()
+	q, repo := newTestQueue()
+	q.Lease = 60 * time.Millisecond
+
+	started := make(chan models.Job)
+	release := make(chan struct{})
+	q.Register("slow", func(ctx context.Context, job models.Job) error {
+		started <- job
+		<-release
+		return nil
+	})
+	if _, err := q.Enqueue(ctx, "slow", nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		q.RunDue(ctx)
+		close(done)
+	}()
+	job := <-started
+
+	// Well past the original lease another worker still finds nothing to claim
+	time.Sleep(3 * q.Lease)
+	claimed, err := repo.ClaimDue(ctx, time.Now(), 10, q.Lease)
+	if err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+	close(release)
+	<-done
+
+	if len(claimed) != 0 {
+		t.Fatalf("job %s was claimed again while it was running", job.ID)
+	}
+	if _, err := q.Get(ctx, job.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Get = %v, want the finished job removed", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	q, repo := newTestQueue()
+
+	job, err := q.Enqueue(ctx, "test", nil)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := q.Retry(ctx, job.ID); !errors.Is(err, ErrNotDead) {
+		t.Fatalf("Retry of a pending job = %v, want ErrNotDead", err)
+	}
+
+	job.Status = models.JobDead
+	job.Attempts = job.MaxAttempts
+	job.RunAt = time.Now().Add(time.Hour)
+	if err := repo.Save(ctx, job); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	retried, err := q.Retry(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	if retried.Status != models.JobPending || retried.Attempts != 0 || retried.RunAt.After(time.Now()) {
+		t.Errorf("retried job is %s with %d attempts due at %v, want pending, fresh and due", retried.Status, retried.Attempts, retried.RunAt)
+	}
+}
+
+// claimingRepository lets another worker claim every job right after it is read
+type claimingRepository struct {
+	*repository.MemoryJobRepository
+	claimed int
+}
+
+func (r *claimingRepository) Get(ctx context.Context, id string) (*models.Job, error) {
+	job, err := r.MemoryJobRepository.Get(ctx, id)
+	if err == nil {
+		claimed, _ := r.ClaimDue(ctx, time.Now().Add(time.Hour), 10, time.Minute)
+		r.claimed += len(claimed)
+	}
+	return job, err
+}
+
+func TestCancel(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		id      string
+		wantErr error
+	}{
+		{"pending", models.JobPending, "job", nil},
+		{"dead", models.JobDead, "job", nil},
+		{"running", models.JobRunning, "job", ErrRunning},
+		{"missing", models.JobPending, "missing", repository.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			q, repo := newTestQueue()
+			if err := repo.Enqueue(ctx, &models.Job{ID: "job", Type: "test", Status: tt.status, RunAt: time.Now()}); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+
+			if err := q.Cancel(ctx, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Cancel = %v, want %v", err, tt.wantErr)
+			}
+			_, err := repo.Get(ctx, "job")
+			if deleted := errors.Is(err, repository.ErrNotFound); deleted != (tt.wantErr == nil) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestCancelWhileClaimed(t *testing.T) {
+	ctx := context.Background()
+	repo := &claimingRepository{MemoryJobRepository: repository.NewMemoryJobRepository()}
+	q := NewQueue(repo)
+	job, err := q.Enqueue(ctx, "test", nil)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	err = q.Cancel(ctx, job.ID)
+	_, getErr := repo.MemoryJobRepository.Get(ctx, job.ID)
+	switch {
+	case errors.Is(err, ErrRunning):
+		if getErr != nil {
+			t.Errorf("Get = %v, want the running job kept", getErr)
+		}
+	case err != nil:
+		t.Fatalf("Cancel: %v", err)
+	case repo.claimed > 0:
+		t.Errorf("cancelled job %s after a worker claimed it", job.ID)
+	case !errors.Is(getErr, repository.ErrNotFound):
+		t.Errorf("Get = %v, want the cancelled job removed", getErr)
+	}
+}
+
+func TestCancelDeleteBlob(t *testing.T) {
+	ctx := context.Background()
+	q, repo := newTestQueue()
+
+	for _, path := range []string{"images/a.jpg", "images/a.jpg", "images/b.jpg"} {
+		if err := q.EnqueueDeleteBlob(ctx, path); err != nil {
+			t.Fatalf("EnqueueDeleteBlob: %v", err)
+		}
+	}
+	if err := q.CancelDeleteBlob(ctx, "images/a.jpg"); err != nil {
+		t.Fatalf("CancelDeleteBlob: %v", err)
+	}
+	left, err := q.List(ctx, repository.JobFilter{Type: TypeDeleteBlob}, 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(left) != 1 || left[0].Payload["path"] != "images/b.jpg" {
+		t.Fatalf("left %v, want only the deletion of images/b.jpg", left)
+	}
+
+	// A deletion in progress cannot be taken back
+	running := left[0]
+	running.Status = models.JobRunning
+	if err := repo.Save(ctx, &running); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := q.CancelDeleteBlob(ctx, "images/b.jpg"); !errors.Is(err, ErrRunning) {
+		t.Errorf("CancelDeleteBlob = %v, want ErrRunning", err)
+	}
+	for path, want := range map[string]bool{"images/a.jpg": false, "images/b.jpg": true} {
+		if deleting, err := q.DeletingBlob(ctx, path); err != nil || deleting != want {
+			t.Errorf("DeletingBlob(%s) = %v, %v, want %v", path, deleting, err, want)
+		}
+	}
+}
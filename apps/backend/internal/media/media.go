@@ -0,0 +1,200 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Library deduplicates uploads by content hash and counts who uses each
+// stored image, so files are only deleted once nothing references them
+type Library struct {
+	Media repository.MediaRepository
+	Blobs blob.Store
+	Jobs  *jobs.Queue
+}
+
+// NewLibrary creates a media library
+func NewLibrary(media repository.MediaRepository, blobs blob.Store, queue *jobs.Queue) *Library {
+	return &Library{Media: media, Blobs: blobs, Jobs: queue}
+}
+
+// Hash returns the hex SHA-256 of uploaded bytes
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Find returns the stored asset for a content hash, or ErrNotFound. An
+// asset whose files have gone missing (collected as orphans after an
+// abandoned upload) is dropped from the index and reported as not found.
+func (l *Library) Find(ctx context.Context, hash string) (*models.MediaAsset, error) {
+	asset, err := l.Media.Get(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.Blobs.Stat(ctx, asset.Image.StoragePath); errors.Is(err, blob.ErrNotFound) {
+		if err := l.Media.Delete(ctx, hash); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		return nil, repository.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// Register adds a freshly stored upload to the index, taking the library
+// caption and alt text from img. It returns ErrConflict when the same bytes
+// were registered concurrently.
+func (l *Library) Register(ctx context.Context, hash string, img models.ProjectImage, size int64) (*models.MediaAsset, error) {
+	now := time.Now()
+	asset := &models.MediaAsset{
+		Hash:       hash,
+		Image:      assetImage(img, hash),
+		Size:       size,
+		Caption:    img.Caption,
+		Alt:        img.Alt,
+		Tags:       []string{},
+		References: []string{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := l.Media.Create(ctx, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// Retain records that ref uses img. Images uploaded before deduplication
+// have no hash and are not counted.
+func (l *Library) Retain(ctx context.Context, img models.ProjectImage, ref string) error {
+	if img.Hash == "" {
+		return nil
+	}
+	now := time.Now()
+	return l.Media.AddReference(ctx, &models.MediaAsset{
+		Hash:      img.Hash,
+		Image:     assetImage(img, img.Hash),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, ref)
+}
+
+// Release records that ref no longer uses img and queues its files for
+// deletion once nothing else does. Images without a hash have a single
+// owner, so their files are always deleted.
+func (l *Library) Release(ctx context.Context, img models.ProjectImage, ref string) error {
+	paths := ObjectPaths(img)
+	if img.Hash != "" {
+		remaining, err := l.Media.RemoveReference(ctx, img.Hash, ref)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			// Not indexed any more, nothing else can be using the files
+		case err != nil:
+			return err
+		case len(remaining.References) > 0:
+			return nil
+		default:
+			paths = append(paths, ObjectPaths(remaining.Image)...)
+		}
+	}
+
+	if len(paths) == 0 {
+		log.Printf("Could not determine path for deletion: %s", img.URL)
+	}
+	seen := make(map[string]bool)
+	for _, objectPath := range paths {
+		if seen[objectPath] {
+			continue
+		}
+		seen[objectPath] = true
+		if err := l.Jobs.EnqueueDeleteBlob(ctx, objectPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reclaim cancels the queued deletion of img's files, for an image linked
+// again after being released. It returns jobs.ErrRunning when a file is
+// being deleted already.
+func (l *Library) Reclaim(ctx context.Context, img models.ProjectImage) error {
+	for _, objectPath := range ObjectPaths(img) {
+		if err := l.Jobs.CancelDeleteBlob(ctx, objectPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deleting reports whether any of img's files is being deleted right now,
+// so the image can no longer be reclaimed
+func (l *Library) Deleting(ctx context.Context, img models.ProjectImage) (bool, error) {
+	for _, objectPath := range ObjectPaths(img) {
+		if deleting, err := l.Jobs.DeletingBlob(ctx, objectPath); err != nil || deleting {
+			return deleting, err
+		}
+	}
+	return false, nil
+}
+
+// ObjectPaths returns every stored object of an image: the main file, the
+// original, the thumbnail and each rendition. Legacy images saved without
+// a StoragePath fall back to parsing the download URL.
+func ObjectPaths(img models.ProjectImage) []string {
+	paths := img.StoragePaths()
+	if len(paths) == 0 {
+		if objectPath := blob.PathFromURL(img.URL); objectPath != "" {
+			paths = append(paths, objectPath)
+		}
+	}
+	return paths
+}
+
+// ImagesIn returns every deduplicated image stored anywhere in a settings
+// document, any nested map carrying a "hash"
+func ImagesIn(value interface{}) []models.ProjectImage {
+	var images []models.ProjectImage
+	var walk func(interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if hash, _ := v["hash"].(string); hash != "" {
+				var img models.ProjectImage
+				if data, err := json.Marshal(v); err == nil && json.Unmarshal(data, &img) == nil {
+					images = append(images, img)
+				}
+				return
+			}
+			for _, item := range v {
+				walk(item)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(value)
+	return images
+}
+
+// assetImage strips what belongs to one use of an image (its ID, caption
+// and alt text) and keeps what the stored files share
+func assetImage(img models.ProjectImage, hash string) models.ProjectImage {
+	img.ID = ""
+	img.Caption = ""
+	img.Alt = ""
+	img.Hash = hash
+	return img
+}
@@ -0,0 +1,247 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+type testLibrary struct {
+	*Library
+	jobs  *repository.MemoryJobRepository
+	blobs *blob.LocalStore
+}
+
+func newTestLibrary(t *testing.T) *testLibrary {
+	t.Helper()
+	store, err := blob.NewLocalStore(t.TempDir(), "http://localhost/storage")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	jobRepo := repository.NewMemoryJobRepository()
+	return &testLibrary{
+		Library: NewLibrary(repository.NewMemoryMediaRepository(), store, jobs.NewQueue(jobRepo)),
+		jobs:    jobRepo,
+		blobs:   store,
+	}
+}
+
+// queuedDeletes returns the sorted paths of every queued blob deletion
+func (l *testLibrary) queuedDeletes(t *testing.T) []string {
+	t.Helper()
+	queued, err := l.jobs.List(context.Background(), repository.JobFilter{Type: jobs.TypeDeleteBlob}, 0)
+	if err != nil {
+		t.Fatalf("List jobs: %v", err)
+	}
+	paths := []string{}
+	for _, job := range queued {
+		paths = append(paths, job.Payload["path"])
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// sharedImage is one use of a deduplicated upload
+func sharedImage(id string) models.ProjectImage {
+	return models.ProjectImage{
+		ID:          id,
+		Hash:        "abc123",
+		URL:         "http://localhost/storage/blobs/media/abc123.webp",
+		StoragePath: "media/abc123.webp",
+		Renditions:  []models.ImageRendition{{Width: 320, StoragePath: "media/abc123_320.webp"}},
+		Caption:     "Caption of " + id,
+	}
+}
+
+func TestRetainRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		retain      []string
+		release     []string
+		wantRefs    []string
+		wantDeletes []string
+	}{
+		{
+			name:        "last reference deletes the files",
+			retain:      []string{"projects/patio"},
+			release:     []string{"projects/patio"},
+			wantDeletes: []string{"media/abc123.webp", "media/abc123_320.webp"},
+		},
+		{
+			name:        "other references keep the files",
+			retain:      []string{"projects/patio", "projects/pond", "settings/website"},
+			release:     []string{"projects/patio"},
+			wantRefs:    []string{"projects/pond", "settings/website"},
+			wantDeletes: []string{},
+		},
+		{
+			name:        "retaining twice counts once",
+			retain:      []string{"projects/patio", "projects/patio"},
+			release:     []string{"projects/patio"},
+			wantDeletes: []string{"media/abc123.webp", "media/abc123_320.webp"},
+		},
+		{
+			name:        "releasing an unknown reference keeps the files",
+			retain:      []string{"projects/patio"},
+			release:     []string{"projects/pond"},
+			wantRefs:    []string{"projects/patio"},
+			wantDeletes: []string{},
+		},
+		{
+			name:        "releasing an unindexed image deletes its files",
+			release:     []string{"projects/patio"},
+			wantDeletes: []string{"media/abc123.webp", "media/abc123_320.webp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			l := newTestLibrary(t)
+			for _, ref := range tt.retain {
+				if err := l.Retain(ctx, sharedImage(ref), ref); err != nil {
+					t.Fatalf("Retain: %v", err)
+				}
+			}
+			for _, ref := range tt.release {
+				if err := l.Release(ctx, sharedImage(ref), ref); err != nil {
+					t.Fatalf("Release: %v", err)
+				}
+			}
+
+			asset, err := l.Media.Get(ctx, "abc123")
+			switch {
+			case len(tt.wantRefs) == 0:
+				if !errors.Is(err, repository.ErrNotFound) {
+					t.Errorf("Get = %v, want ErrNotFound once nothing uses the image", err)
+				}
+			case err != nil:
+				t.Fatalf("Get: %v", err)
+			default:
+				refs := append([]string(nil), asset.References...)
+				sort.Strings(refs)
+				if !reflect.DeepEqual(refs, tt.wantRefs) {
+					t.Errorf("references = %v, want %v", refs, tt.wantRefs)
+				}
+				if asset.Image.ID != "" || asset.Image.Caption != "" {
+					t.Errorf("asset image keeps per-use fields: %+v", asset.Image)
+				}
+			}
+			if got := l.queuedDeletes(t); !reflect.DeepEqual(got, tt.wantDeletes) {
+				t.Errorf("queued deletes = %v, want %v", got, tt.wantDeletes)
+			}
+		})
+	}
+}
+
+func TestReleaseLegacyImage(t *testing.T) {
+	tests := []struct {
+		name string
+		img  models.ProjectImage
+		want []string
+	}{
+		{
+			name: "stored paths",
+			img:  models.ProjectImage{StoragePath: "projects/patio/a.jpg", ThumbnailPath: "projects/patio/a_thumb.jpg"},
+			want: []string{"projects/patio/a.jpg", "projects/patio/a_thumb.jpg"},
+		},
+		{
+			name: "download URL only",
+			img:  models.ProjectImage{URL: "http://localhost/storage/blobs/projects/patio/a.jpg"},
+			want: []string{"projects/patio/a.jpg"},
+		},
+		{
+			name: "external URL",
+			img:  models.ProjectImage{URL: "https://example.com/a.jpg"},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLibrary(t)
+			if err := l.Retain(context.Background(), tt.img, "projects/patio"); err != nil {
+				t.Fatalf("Retain: %v", err)
+			}
+			if err := l.Release(context.Background(), tt.img, "projects/patio"); err != nil {
+				t.Fatalf("Release: %v", err)
+			}
+			if got := l.queuedDeletes(t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queued deletes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLibrary(t)
+	img := sharedImage("a")
+	if _, err := l.Register(ctx, img.Hash, img, 3); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := l.Register(ctx, img.Hash, img, 3); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Register twice = %v, want ErrConflict", err)
+	}
+
+	if _, err := l.Find(ctx, img.Hash); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Find with missing files = %v, want ErrNotFound", err)
+	}
+	if _, err := l.Media.Get(ctx, img.Hash); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("asset with missing files still indexed: %v", err)
+	}
+
+	if _, err := l.Register(ctx, img.Hash, img, 3); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := l.blobs.Put(ctx, img.StoragePath, strings.NewReader("abc"), "image/webp"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	asset, err := l.Find(ctx, img.Hash)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if asset.Caption != img.Caption || asset.Image.Caption != "" {
+		t.Errorf("asset caption = %q, image caption = %q", asset.Caption, asset.Image.Caption)
+	}
+}
+
+func TestReclaim(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLibrary(t)
+	img := sharedImage("a")
+	if err := l.Release(ctx, img, "projects/patio"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if err := l.Reclaim(ctx, img); err != nil {
+		t.Fatalf("Reclaim: %v", err)
+	}
+	if got := l.queuedDeletes(t); len(got) != 0 {
+		t.Errorf("queued deletes after Reclaim = %v", got)
+	}
+
+	if err := l.Release(ctx, img, "projects/patio"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if deleting, err := l.Deleting(ctx, img); err != nil || deleting {
+		t.Errorf("Deleting queued image = %v, %v, want false", deleting, err)
+	}
+	if _, err := l.jobs.ClaimDue(ctx, time.Now().Add(time.Second), 1, time.Minute); err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+	if deleting, err := l.Deleting(ctx, img); err != nil || !deleting {
+		t.Errorf("Deleting claimed image = %v, %v, want true", deleting, err)
+	}
+	if err := l.Reclaim(ctx, img); !errors.Is(err, jobs.ErrRunning) {
+		t.Errorf("Reclaim while deleting = %v, want ErrRunning", err)
+	}
+}
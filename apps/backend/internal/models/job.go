@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Job statuses. Jobs that succeed are removed from the queue.
+const (
+	JobPending = "pending" // Waiting for RunAt
+	JobRunning = "running" // Claimed by a worker until RunAt, when it becomes due again
+	JobDead    = "dead"    // Failed MaxAttempts times, waiting for someone to look at it
+)
+
+// Job is a unit of background work persisted so it survives restarts
+type Job struct {
+	ID          string            `json:"id" firestore:"id"`
+	Type        string            `json:"type" firestore:"type"`
+	Payload     map[string]string `json:"payload" firestore:"payload"`
+	Status      string            `json:"status" firestore:"status"`
+	Attempts    int               `json:"attempts" firestore:"attempts"`
+	MaxAttempts int               `json:"maxAttempts" firestore:"maxAttempts"`
+	LastError   string            `json:"lastError,omitempty" firestore:"lastError,omitempty"`
+	RunAt       time.Time         `json:"runAt" firestore:"runAt"` // When the job is next due
+	CreatedAt   time.Time         `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt" firestore:"updatedAt"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// References held on media assets besides those of project images
+const (
+	WebsiteMediaRef = "website" // Used somewhere in the website settings
+	LibraryMediaRef = "library" // Kept in the media library even when unused
+)
+
+// MediaAsset is an uploaded image stored once, however many projects or
+// website sections use it. It is keyed by the SHA-256 of the uploaded bytes
+// and removed, files included, when its last reference goes away.
+type MediaAsset struct {
+	Hash       string       `json:"hash" firestore:"hash"`
+	Image      ProjectImage `json:"image" firestore:"image"` // Stored files, dimensions and placeholders
+	Size       int64        `json:"size" firestore:"size"`   // Bytes uploaded
+	Caption    string       `json:"caption" firestore:"caption"`
+	Alt        string       `json:"alt" firestore:"alt"`
+	Tags       []string     `json:"tags" firestore:"tags"`
+	References []string     `json:"references" firestore:"references"`
+	CreatedAt  time.Time    `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt  time.Time    `json:"updatedAt" firestore:"updatedAt"`
+}
+
+// ProjectMediaRef is the reference held by an image of a project
+func ProjectMediaRef(projectID, imageID string) string {
+	return "project/" + projectID + "/" + imageID
+}
+
+// ParseProjectMediaRef splits a project image reference into its project
+// and image IDs
+func ParseProjectMediaRef(ref string) (projectID, imageID string, ok bool) {
+	rest, found := strings.CutPrefix(ref, "project/")
+	if !found {
+		return "", "", false
+	}
+	projectID, imageID, ok = strings.Cut(rest, "/")
+	return projectID, imageID, ok
+}
+
+// InLibrary reports whether the asset was added to the media library
+func (a MediaAsset) InLibrary() bool {
+	return slices.Contains(a.References, LibraryMediaRef)
+}
@@ -3,14 +3,48 @@ package models
 import "time"
 
 type ProjectImage struct {
-	ID          string `json:"id" firestore:"id"`
+	ID            string           `json:"id" firestore:"id"`
+	URL           string           `json:"url" firestore:"url"`
+	StoragePath   string           `json:"storagePath" firestore:"storagePath"`
+	Thumbnail     string           `json:"thumbnail,omitempty" firestore:"thumbnail,omitempty"`
+	ThumbnailPath string           `json:"thumbnailPath,omitempty" firestore:"thumbnailPath,omitempty"`
+	OriginalPath  string           `json:"originalPath,omitempty" firestore:"originalPath,omitempty"` // Full size copy with metadata stripped
+	Renditions    []ImageRendition `json:"renditions,omitempty" firestore:"renditions,omitempty"`     // Smallest first
+	Caption       string           `json:"caption,omitempty" firestore:"caption,omitempty"`
+	Alt           string           `json:"alt,omitempty" firestore:"alt,omitempty"`
+	Width         int              `json:"width,omitempty" firestore:"width,omitempty"`
+	Height        int              `json:"height,omitempty" firestore:"height,omitempty"`
+	BlurHash      string           `json:"blurHash,omitempty" firestore:"blurHash,omitempty"`
+	LQIP          string           `json:"lqip,omitempty" firestore:"lqip,omitempty"`                   // Tiny JPEG data URI shown while loading
+	DominantColor string           `json:"dominantColor,omitempty" firestore:"dominantColor,omitempty"` // "#rrggbb"
+	Hash          string           `json:"hash,omitempty" firestore:"hash,omitempty"`                   // SHA-256 of the upload, the MediaAsset holding the files
+}
+
+// ImageRendition is one resized copy of an uploaded image
+type ImageRendition struct {
+	Width       int    `json:"width" firestore:"width"`
+	Height      int    `json:"height" firestore:"height"`
 	URL         string `json:"url" firestore:"url"`
 	StoragePath string `json:"storagePath" firestore:"storagePath"`
-	Thumbnail   string `json:"thumbnail,omitempty" firestore:"thumbnail,omitempty"`
-	Caption     string `json:"caption,omitempty" firestore:"caption,omitempty"`
-	Alt         string `json:"alt,omitempty" firestore:"alt,omitempty"`
-	Width       int    `json:"width,omitempty" firestore:"width,omitempty"`
-	Height      int    `json:"height,omitempty" firestore:"height,omitempty"`
+}
+
+// StoragePaths lists every stored object belonging to the image
+func (img ProjectImage) StoragePaths() []string {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	add(img.StoragePath)
+	add(img.OriginalPath)
+	add(img.ThumbnailPath)
+	for _, r := range img.Renditions {
+		add(r.StoragePath)
+	}
+	return paths
 }
 
 type ImageGroup struct {
@@ -32,6 +66,8 @@ type Testimonial struct {
 type Project struct {
 	ID             string         `json:"id" firestore:"id"`
 	Title          string         `json:"title" firestore:"title"`
+	Slug           string         `json:"slug" firestore:"slug"`
+	PreviousSlugs  []string       `json:"previousSlugs,omitempty" firestore:"previousSlugs,omitempty"` // Old slugs that redirect to Slug
 	Description    string         `json:"description" firestore:"description"`
 	Location       string         `json:"location" firestore:"location"`
 	CompletedDate  string         `json:"completedDate" firestore:"completedDate"`
@@ -45,13 +81,19 @@ type Project struct {
 	Testimonial    *Testimonial   `json:"testimonial,omitempty" firestore:"testimonial,omitempty"`       // Pointer to allow nil/omission
 	Published      bool           `json:"published" firestore:"published"`
 	Status         string         `json:"status" firestore:"status,omitempty"`
+	StatusHistory  []StatusChange `json:"statusHistory,omitempty" firestore:"statusHistory,omitempty"` // Every workflow transition, oldest first
+	PublishedAt    *time.Time     `json:"publishedAt,omitempty" firestore:"publishedAt,omitempty"`     // Last time the project went active
+	DeletedAt      *time.Time     `json:"deletedAt,omitempty" firestore:"deletedAt,omitempty"`         // Set while the project is in the trash
+	DeletedBy      string         `json:"deletedBy,omitempty" firestore:"deletedBy,omitempty"`         // UID of the user who trashed it
 	CreatedAt      time.Time      `json:"createdAt" firestore:"createdAt"`
 	UpdatedAt      time.Time      `json:"updatedAt" firestore:"updatedAt"`
+	Version        int64          `json:"version" firestore:"version"` // Incremented on every update, exposed as the ETag
 }
 
 type CreateProjectRequest struct {
 	ID             string         `json:"id"`
 	Title          string         `json:"title"`
+	Slug           string         `json:"slug,omitempty"` // Optional, generated from the title when empty
 	Description    string         `json:"description"`
 	Location       string         `json:"location"`
 	Category       string         `json:"category"`
@@ -62,4 +104,123 @@ type CreateProjectRequest struct {
 	ImageGroups    []ImageGroup   `json:"imageGroups"`
 	HasTestimonial *bool          `json:"hasTestimonial,omitempty"`
 	Testimonial    *Testimonial   `json:"testimonial,omitempty"`
+	Featured       *bool          `json:"featured,omitempty"`      // Left unchanged on update when omitted
+	CompletedDate  *string        `json:"completedDate,omitempty"` // Left unchanged on update when omitted
+}
+
+// EditableFields returns the request that would recreate p's editable state,
+// the document PATCH requests are applied to
+func (p Project) EditableFields() CreateProjectRequest {
+	featured := p.Featured
+	completedDate := p.CompletedDate
+	return CreateProjectRequest{
+		ID:             p.ID,
+		Title:          p.Title,
+		Slug:           p.Slug,
+		Description:    p.Description,
+		Location:       p.Location,
+		Category:       p.Category,
+		Tags:           p.Tags,
+		Status:         p.Status,
+		CoverImage:     p.CoverImage,
+		Images:         p.Images,
+		ImageGroups:    p.ImageGroups,
+		HasTestimonial: p.HasTestimonial,
+		Testimonial:    p.Testimonial,
+		Featured:       &featured,
+		CompletedDate:  &completedDate,
+	}
+}
+
+// PublicImage is the public-safe view of a ProjectImage, without storage internals
+type PublicImage struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	Thumbnail     string            `json:"thumbnail,omitempty"`
+	Caption       string            `json:"caption,omitempty"`
+	Alt           string            `json:"alt,omitempty"`
+	Width         int               `json:"width,omitempty"`
+	Height        int               `json:"height,omitempty"`
+	Renditions    []PublicRendition `json:"renditions,omitempty"`
+	BlurHash      string            `json:"blurHash,omitempty"`
+	LQIP          string            `json:"lqip,omitempty"`
+	DominantColor string            `json:"dominantColor,omitempty"`
+}
+
+// PublicRendition is the public-safe view of an ImageRendition
+type PublicRendition struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// PublicRenditions projects renditions onto their public fields
+func PublicRenditions(renditions []ImageRendition) []PublicRendition {
+	if len(renditions) == 0 {
+		return nil
+	}
+	public := make([]PublicRendition, 0, len(renditions))
+	for _, r := range renditions {
+		public = append(public, PublicRendition{Width: r.Width, Height: r.Height, URL: r.URL})
+	}
+	return public
+}
+
+// PublicProject is the public-safe view of a Project returned by the public API
+type PublicProject struct {
+	ID             string        `json:"id"`
+	Title          string        `json:"title"`
+	Slug           string        `json:"slug"`
+	Description    string        `json:"description"`
+	Location       string        `json:"location"`
+	CompletedDate  string        `json:"completedDate"`
+	Category       string        `json:"category"`
+	Tags           []string      `json:"tags"`
+	CoverImage     string        `json:"coverImage"`
+	Images         []PublicImage `json:"images"`
+	ImageGroups    []ImageGroup  `json:"imageGroups"`
+	Featured       bool          `json:"featured"`
+	HasTestimonial *bool         `json:"hasTestimonial,omitempty"`
+	Testimonial    *Testimonial  `json:"testimonial,omitempty"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+}
+
+// Public projects a Project onto the fields that are safe to expose publicly
+func (p Project) Public() PublicProject {
+	images := make([]PublicImage, 0, len(p.Images))
+	for _, img := range p.Images {
+		images = append(images, PublicImage{
+			ID:            img.ID,
+			URL:           img.URL,
+			Thumbnail:     img.Thumbnail,
+			Caption:       img.Caption,
+			Alt:           img.Alt,
+			Width:         img.Width,
+			Height:        img.Height,
+			Renditions:    PublicRenditions(img.Renditions),
+			BlurHash:      img.BlurHash,
+			LQIP:          img.LQIP,
+			DominantColor: img.DominantColor,
+		})
+	}
+
+	return PublicProject{
+		ID:             p.ID,
+		Title:          p.Title,
+		Slug:           p.Slug,
+		Description:    p.Description,
+		Location:       p.Location,
+		CompletedDate:  p.CompletedDate,
+		Category:       p.Category,
+		Tags:           p.Tags,
+		CoverImage:     p.CoverImage,
+		Images:         images,
+		ImageGroups:    p.ImageGroups,
+		Featured:       p.Featured,
+		HasTestimonial: p.HasTestimonial,
+		Testimonial:    p.Testimonial,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
 }
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// What started a publish
+const (
+	ReleaseManual    = "manual"    // An admin pressed publish
+	ReleaseScheduled = "scheduled" // A publish scheduled for a given time
+	ReleaseAuto      = "auto"      // Auto-publish after active projects changed
+)
+
+// Release is one publish of the public website. Its files are written once
+// under their own folder and never changed, so any release can go live again.
+// Failed publishes are kept too, with Error set and no files.
+type Release struct {
+	ID          string            `json:"id" firestore:"id"`
+	Files       map[string]string `json:"files" firestore:"files"`       // File name, e.g. "projects.json", to storage path
+	Projects    int               `json:"projects" firestore:"projects"` // Active projects published
+	PublishedBy string            `json:"publishedBy,omitempty" firestore:"publishedBy,omitempty"`
+	PublishedAt time.Time         `json:"publishedAt" firestore:"publishedAt"`
+	Trigger     string            `json:"trigger" firestore:"trigger"` // manual, scheduled or auto
+	Error       string            `json:"error,omitempty" firestore:"error,omitempty"`
+	RestoredBy  string            `json:"restoredBy,omitempty" firestore:"restoredBy,omitempty"`
+	RestoredAt  *time.Time        `json:"restoredAt,omitempty" firestore:"restoredAt,omitempty"` // Last rollback to this release
+	Live        bool              `json:"live" firestore:"-"`                                    // Whether the manifest points at it
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Revision is an immutable snapshot of a project taken every time it is saved
+type Revision struct {
+	ProjectID string    `json:"projectId" firestore:"projectId"`
+	Number    int64     `json:"number" firestore:"number"` // The project version the snapshot was saved as
+	Action    string    `json:"action" firestore:"action"` // create, update, patch, rollback or a workflow action
+	By        string    `json:"by,omitempty" firestore:"by,omitempty"`
+	At        time.Time `json:"at" firestore:"at"`
+	Changes   []string  `json:"changes,omitempty" firestore:"changes,omitempty"` // Fields that differ from the previous revision
+	Snapshot  *Project  `json:"snapshot,omitempty" firestore:"snapshot"`
+}
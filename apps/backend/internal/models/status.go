@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Project lifecycle statuses. Only active projects are visible publicly.
+const (
+	StatusDraft    = "draft"
+	StatusReview   = "review"
+	StatusActive   = "active"
+	StatusArchived = "archived"
+)
+
+// User roles, read from the "role" claim of the ID token
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+)
+
+// StatusChange records a single workflow transition of a project
+type StatusChange struct {
+	Action string    `json:"action" firestore:"action"`
+	From   string    `json:"from,omitempty" firestore:"from,omitempty"`
+	To     string    `json:"to" firestore:"to"`
+	By     string    `json:"by,omitempty" firestore:"by,omitempty"` // UID of the user who made the change
+	Note   string    `json:"note,omitempty" firestore:"note,omitempty"`
+	At     time.Time `json:"at" firestore:"at"`
+}
+
+// IsPublic reports whether the project may be shown on the public website
+func (p Project) IsPublic() bool {
+	return NormalizeStatus(p.Status) == StatusActive && p.DeletedAt == nil
+}
+
+// NormalizeStatus maps legacy status values onto the workflow statuses:
+// statuses are lower case and the old "inactive" (or no status) means draft
+func NormalizeStatus(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status == "" || status == "inactive" {
+		return StatusDraft
+	}
+	return status
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	trashedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		project Project
+		want    bool
+	}{
+		{"active", Project{Status: StatusActive}, true},
+		{"legacy capitalized", Project{Status: "Active"}, true},
+		{"draft", Project{Status: StatusDraft}, false},
+		{"legacy inactive", Project{Status: "inactive"}, false},
+		{"no status", Project{}, false},
+		{"trashed", Project{Status: StatusActive, DeletedAt: &trashedAt}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.project.IsPublic(); got != tt.want {
+			t.Errorf("%s: IsPublic = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeStatus(t *testing.T) {
+	tests := map[string]string{
+		"":          StatusDraft,
+		"inactive":  StatusDraft,
+		"Inactive":  StatusDraft,
+		" Active ":  StatusActive,
+		"review":    StatusReview,
+		"ARCHIVED":  StatusArchived,
+		"published": "published",
+	}
+	for in, want := range tests {
+		if got := NormalizeStatus(in); got != want {
+			t.Errorf("NormalizeStatus(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Content types that select the patch format
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch is returned when the patch document itself is malformed
+	ErrInvalidPatch = errors.New("invalid patch document")
+	// ErrInvalidPath is returned when an operation targets a location that does not exist
+	ErrInvalidPath = errors.New("invalid patch path")
+	// ErrTestFailed is returned when a JSON Patch "test" operation does not match
+	ErrTestFailed = errors.New("patch test operation failed")
+)
+
+// Merge applies an RFC 7396 JSON Merge Patch to doc: objects are merged
+// recursively, null removes a member and anything else replaces the target.
+func Merge(doc, mergePatch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mergePatch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+func mergeValue(target, p interface{}) interface{} {
+	patchObj, ok := p.(map[string]interface{})
+	if !ok {
+		return p
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergeValue(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// operation is a single RFC 6902 operation. Value is kept raw so an explicit
+// null can be told apart from a missing value.
+type operation struct {
+	Op       string
+	Path     []string
+	From     []string
+	Value    interface{}
+	HasValue bool
+}
+
+// Apply applies an RFC 6902 JSON Patch to doc. Operations are applied in
+// order and the whole patch fails if any one of them does.
+func Apply(doc, jsonPatch []byte) ([]byte, error) {
+	ops, err := parseOperations(jsonPatch)
+	if err != nil {
+		return nil, err
+	}
+
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		target, err = applyOperation(target, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+func parseOperations(jsonPatch []byte) ([]operation, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(jsonPatch, &raw); err != nil {
+		return nil, fmt.Errorf("%w: expected an array of operations", ErrInvalidPatch)
+	}
+
+	ops := make([]operation, 0, len(raw))
+	for i, fields := range raw {
+		var op operation
+		var path, from string
+		if err := json.Unmarshal(fields["op"], &op.Op); err != nil {
+			return nil, fmt.Errorf("%w: operation %d has no op", ErrInvalidPatch, i)
+		}
+		if err := json.Unmarshal(fields["path"], &path); err != nil {
+			return nil, fmt.Errorf("%w: operation %d has no path", ErrInvalidPatch, i)
+		}
+		var err error
+		if op.Path, err = parsePointer(path); err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case "add", "replace", "test":
+			rawValue, ok := fields["value"]
+			if !ok {
+				return nil, fmt.Errorf("%w: operation %d (%s) needs a value", ErrInvalidPatch, i, op.Op)
+			}
+			if err := json.Unmarshal(rawValue, &op.Value); err != nil {
+				return nil, fmt.Errorf("%w: operation %d has an invalid value", ErrInvalidPatch, i)
+			}
+			op.HasValue = true
+		case "move", "copy":
+			if err := json.Unmarshal(fields["from"], &from); err != nil {
+				return nil, fmt.Errorf("%w: operation %d (%s) needs from", ErrInvalidPatch, i, op.Op)
+			}
+			if op.From, err = parsePointer(from); err != nil {
+				return nil, err
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func applyOperation(doc interface{}, op operation) (interface{}, error) {
+	switch op.Op {
+	case "add":
+		return add(doc, op.Path, op.Value)
+	case "remove":
+		return remove(doc, op.Path)
+	case "replace":
+		if _, err := get(doc, op.Path); err != nil {
+			return nil, err
+		}
+		if len(op.Path) == 0 {
+			return op.Value, nil
+		}
+		return update(doc, op.Path, func(parent interface{}, key string) (interface{}, error) {
+			switch container := parent.(type) {
+			case map[string]interface{}:
+				container[key] = op.Value
+				return container, nil
+			case []interface{}:
+				i, _ := arrayIndex(key, len(container)-1)
+				container[i] = op.Value
+				return container, nil
+			}
+			return nil, ErrInvalidPath
+		})
+	case "move":
+		// A location cannot be moved into one of its own children
+		if isPrefix(op.From, op.Path) && len(op.From) < len(op.Path) {
+			return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalidPath)
+		}
+		value, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = remove(doc, op.From); err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, value)
+	case "copy":
+		value, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, deepCopy(value))
+	case "test":
+		value, err := get(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op.Value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+	return nil, ErrInvalidPatch
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			container[key] = value
+			return container, nil
+		case []interface{}:
+			if key == "-" {
+				return append(container, value), nil
+			}
+			i, err := arrayIndex(key, len(container))
+			if err != nil {
+				return nil, err
+			}
+			container = append(container, nil)
+			copy(container[i+1:], container[i:])
+			container[i] = value
+			return container, nil
+		}
+		return nil, fmt.Errorf("%w: parent is not an object or array", ErrInvalidPath)
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPath)
+	}
+	return update(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := container[key]; !ok {
+				return nil, fmt.Errorf("%w: %q does not exist", ErrInvalidPath, key)
+			}
+			delete(container, key)
+			return container, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(container[:i], container[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: parent is not an object or array", ErrInvalidPath)
+	})
+}
+
+// update walks to the parent of path and lets fn modify it, rebuilding
+// every container on the way since slices may be reallocated
+func update(node interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+
+	key := path[0]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, ok := container[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q does not exist", ErrInvalidPath, key)
+		}
+		newChild, err := update(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		container[key] = newChild
+		return container, nil
+	case []interface{}:
+		i, err := arrayIndex(key, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		newChild, err := update(container[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		container[i] = newChild
+		return container, nil
+	}
+	return nil, fmt.Errorf("%w: %q is not an object or array", ErrInvalidPath, key)
+}
+
+func get(node interface{}, path []string) (interface{}, error) {
+	for _, key := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			child, ok := container[key]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q does not exist", ErrInvalidPath, key)
+			}
+			node = child
+		case []interface{}:
+			i, err := arrayIndex(key, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[i]
+		default:
+			return nil, fmt.Errorf("%w: %q is not an object or array", ErrInvalidPath, key)
+		}
+	}
+	return node, nil
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array reference token, allowing indexes up to max
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPath, token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max {
+		return 0, fmt.Errorf("%w: array index %q out of range", ErrInvalidPath, token)
+	}
+	return i, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = deepCopy(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = deepCopy(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
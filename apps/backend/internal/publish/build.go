@@ -0,0 +1,185 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Build produces the files of a release from the active projects and the
+// website settings, without writing anything
+func (p *Publisher) Build(ctx context.Context) (*Bundle, error) {
+	// --- OPERATION 1: PROJECTS JSON ---
+
+	// 1. Fetch all 'active' projects
+	var projectsForJSON []map[string]interface{} // This will hold the transformed projects
+
+	activeProjects, _, err := p.Projects.List(ctx, repository.ListOptions{Status: models.StatusActive})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %w", err)
+	}
+
+	for _, project := range activeProjects {
+
+		// Create a map for quick lookup of image details by ID, each with its
+		// responsive renditions so the website can serve phones smaller files
+		imageDetailsMap := make(map[string]publicImage)
+		for _, img := range project.Images {
+			imageDetailsMap[img.ID] = newPublicImage(img)
+		}
+
+		// Convert the public view of the project to a generic map[string]interface{},
+		// leaving out the workflow history and storage internals.
+		// This allows us to modify the structure of imageGroups before marshaling to JSON.
+		projectBytes, err := json.Marshal(project.Public())
+		if err != nil {
+			log.Printf("Failed to marshal project %s to JSON: %v", project.ID, err)
+			continue
+		}
+		var projectMap map[string]interface{}
+		if err := json.Unmarshal(projectBytes, &projectMap); err != nil {
+			log.Printf("Failed to unmarshal project %s JSON to map: %v", project.ID, err)
+			continue
+		}
+
+		// Transform the 'imageGroups' field
+		if rawImageGroups, ok := projectMap["imageGroups"].([]interface{}); ok {
+			transformedImageGroups := make([]map[string]interface{}, 0, len(rawImageGroups))
+			for _, rawGroup := range rawImageGroups {
+				if groupMap, isMap := rawGroup.(map[string]interface{}); isMap {
+					if rawImages, hasImages := groupMap["images"].([]interface{}); hasImages {
+						var newImages []publicImage
+						for _, imgIDInterface := range rawImages {
+							if imgID, isString := imgIDInterface.(string); isString {
+								if imgDetail, found := imageDetailsMap[imgID]; found {
+									newImages = append(newImages, imgDetail)
+								}
+							}
+						}
+						groupMap["images"] = newImages // Replace the images array with the transformed one
+					}
+					transformedImageGroups = append(transformedImageGroups, groupMap)
+				}
+			}
+			projectMap["imageGroups"] = transformedImageGroups // Replace the project's imageGroups
+		}
+		if cover, found := coverImage(project); found {
+			projectMap["cover"] = newPublicImage(cover)
+		}
+		addSrcsets(projectMap["images"])
+		projectsForJSON = append(projectsForJSON, projectMap)
+	}
+
+	// --- OPERATION 2: SETTINGS JSON ---
+
+	// 1. Fetch website settings
+	settingsData, err := p.Settings.Get(ctx, repository.WebsiteDocument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch website settings: %w", err)
+	}
+
+	// 2. Resolve the hero and gallery projects to their cover images and add
+	// srcsets to every uploaded content image (logo, benefits, services, ...)
+	if content, ok := settingsData["content"].(map[string]interface{}); ok {
+		for _, section := range []string{"hero", "gallery"} {
+			if sectionMap, ok := content[section].(map[string]interface{}); ok {
+				sectionMap["images"] = projectCoverImages(sectionMap["projects"], activeProjects)
+			}
+		}
+	}
+	addSrcsets(settingsData)
+	for _, field := range PrivateFields {
+		delete(settingsData, field)
+	}
+
+	bundle := &Bundle{Files: make(map[string][]byte), Projects: len(projectsForJSON)}
+	for name, data := range map[string]interface{}{ProjectsFile: projectsForJSON, ConfigFile: settingsData} {
+		jsonData, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		bundle.Files[name] = jsonData
+	}
+
+	// --- OPERATION 3: SITEMAP, ROBOTS.TXT AND FEEDS ---
+	if err := p.addDiscoveryFiles(ctx, bundle, activeProjects, settingsData); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// addDiscoveryFiles adds the files for search engines and feed readers:
+// robots.txt always, the sitemap and the RSS and Atom feeds only once the
+// website URL is set, since they need absolute links
+func (p *Publisher) addDiscoveryFiles(ctx context.Context, bundle *Bundle, projects []models.Project, settings map[string]interface{}) error {
+	settingsUpdated, _ := settings["updatedAt"].(time.Time)
+	siteURL, err := websiteURL(settings)
+	if err != nil {
+		log.Printf("Publishing without a sitemap or feeds: %v", err)
+		bundle.Files[RobotsFile] = buildRobots(settings, "")
+		return nil
+	}
+	bundle.Files[RobotsFile] = buildRobots(settings, siteURL+"/"+SitemapFile)
+
+	// The feeds change only when the projects or settings do
+	updated := settingsUpdated
+	for _, project := range projects {
+		if project.UpdatedAt.After(updated) {
+			updated = project.UpdatedAt
+		}
+	}
+
+	info := newFeedInfo(siteURL, settings)
+	covers := p.feedCovers(ctx, projects)
+	builders := map[string]func() ([]byte, error){
+		SitemapFile: func() ([]byte, error) { return buildSitemap(siteURL, projects, settingsUpdated) },
+		RSSFile:     func() ([]byte, error) { return buildRSS(info, projects, covers, updated) },
+		AtomFile:    func() ([]byte, error) { return buildAtom(info, projects, updated) },
+	}
+	for name, build := range builders {
+		data, err := build()
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		bundle.Files[name] = data
+	}
+	return nil
+}
+
+// projectCoverImages returns the cover images of the listed project IDs, in
+// order, skipping projects that are not published
+func projectCoverImages(ids interface{}, projects []models.Project) []map[string]interface{} {
+	byID := make(map[string]models.Project, len(projects))
+	for _, p := range projects {
+		byID[p.ID] = p
+	}
+
+	list, _ := ids.([]interface{})
+	images := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		id, _ := item.(string)
+		p, found := byID[id]
+		if !found {
+			continue
+		}
+		image := publicImage{URL: p.CoverImage, Alt: p.Title}
+		if cover, found := coverImage(p); found {
+			image = newPublicImage(cover)
+		}
+		if image.URL == "" {
+			continue
+		}
+		images = append(images, map[string]interface{}{
+			"projectId": p.ID,
+			"slug":      p.Slug,
+			"title":     p.Title,
+			"image":     image,
+		})
+	}
+	return images
+}
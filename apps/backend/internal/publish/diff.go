@@ -0,0 +1,286 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// bookkeepingFields change on every save or publish without changing what
+// the website shows, so they are left out of diffs
+var bookkeepingFields = map[string]bool{
+	"version": true, "updatedAt": true, "publishedAt": true, "liveRelease": true, "projectUpdatedAt": true,
+	UpdatedByField: true, SectionUpdatesField: true, AutoPublishField: true,
+}
+
+// ProjectRef identifies a published project in a diff
+type ProjectRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug,omitempty"`
+}
+
+// ProjectChange is a published project whose fields differ
+type ProjectChange struct {
+	ProjectRef
+	Fields []string `json:"fields"`
+}
+
+// Diff describes what a publish would change on the public website
+type Diff struct {
+	LiveRelease string `json:"liveRelease,omitempty"` // Release compared against, empty before the first release
+	Unchanged   bool   `json:"unchanged"`
+	Projects    struct {
+		Added   []ProjectRef    `json:"added"`
+		Removed []ProjectRef    `json:"removed"`
+		Changed []ProjectChange `json:"changed"`
+	} `json:"projects"`
+	Sections []string `json:"sections"` // Settings that differ, e.g. "title" or "content.hero"
+	Images   struct {
+		Added   []string `json:"added"`
+		Removed []string `json:"removed"`
+	} `json:"images"` // Image URLs
+}
+
+// Preview builds the files a publish would write and compares them with the
+// live release, writing nothing
+func (p *Publisher) Preview(ctx context.Context) (*Diff, error) {
+	bundle, err := p.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live, releaseID, err := p.liveFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := decodeBundle(bundle.Files)
+	if err != nil {
+		return nil, err
+	}
+	current, err := decodeBundle(live)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the live release: %w", err)
+	}
+
+	diff := &Diff{LiveRelease: releaseID}
+	diff.Projects.Added, diff.Projects.Removed, diff.Projects.Changed = diffProjects(current[ProjectsFile], next[ProjectsFile])
+	diff.Sections = diffSections(current[ConfigFile], next[ConfigFile])
+	diff.Images.Added, diff.Images.Removed = diffImages(current, next)
+	diff.Unchanged = len(diff.Projects.Added) == 0 && len(diff.Projects.Removed) == 0 &&
+		len(diff.Projects.Changed) == 0 && len(diff.Sections) == 0 &&
+		len(diff.Images.Added) == 0 && len(diff.Images.Removed) == 0
+	return diff, nil
+}
+
+// liveFiles reads the files the website currently shows: those of the live
+// release, or the fixed copies when nothing was released yet
+func (p *Publisher) liveFiles(ctx context.Context) (map[string][]byte, string, error) {
+	paths := fixedPaths
+	releaseID := ""
+	manifest, err := p.Live(ctx)
+	switch {
+	case err == nil:
+		releaseID = manifest.ReleaseID
+		paths = make(map[string]string, len(manifest.Files))
+		release, err := p.Releases.Get(ctx, manifest.ReleaseID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, "", err
+		}
+		for name, url := range manifest.Files {
+			if release != nil && release.Files[name] != "" {
+				paths[name] = release.Files[name]
+			} else {
+				paths[name] = blob.PathFromURL(url)
+			}
+		}
+	case !errors.Is(err, ErrNotPublished):
+		return nil, "", err
+	}
+
+	files := make(map[string][]byte, len(paths))
+	for name, objectPath := range paths {
+		rc, err := p.Blobs.Get(ctx, objectPath)
+		if errors.Is(err, blob.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, "", err
+		}
+		files[name] = data
+	}
+	return files, releaseID, nil
+}
+
+// decodeBundle parses published JSON files, treating missing ones as empty.
+// The sitemap, robots.txt and feeds are built from the same data, so they
+// are not compared.
+func decodeBundle(files map[string][]byte) (map[string]interface{}, error) {
+	decoded := make(map[string]interface{}, len(files))
+	for name, data := range files {
+		if path.Ext(name) != ".json" {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		decoded[name] = value
+	}
+	return decoded, nil
+}
+
+// diffProjects compares two projects.json documents by project ID
+func diffProjects(current, next interface{}) (added, removed []ProjectRef, changed []ProjectChange) {
+	before, after := projectsByID(current), projectsByID(next)
+	added, removed, changed = []ProjectRef{}, []ProjectRef{}, []ProjectChange{}
+
+	for id, project := range after {
+		old, found := before[id]
+		if !found {
+			added = append(added, projectRef(project))
+			continue
+		}
+		var fields []string
+		for _, key := range unionKeys(old, project) {
+			if !bookkeepingFields[key] && !reflect.DeepEqual(old[key], project[key]) {
+				fields = append(fields, key)
+			}
+		}
+		if len(fields) > 0 {
+			changed = append(changed, ProjectChange{ProjectRef: projectRef(project), Fields: fields})
+		}
+	}
+	for id, project := range before {
+		if _, found := after[id]; !found {
+			removed = append(removed, projectRef(project))
+		}
+	}
+
+	sort.Slice(added, func(i, j int) bool { return added[i].Title < added[j].Title })
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Title < removed[j].Title })
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Title < changed[j].Title })
+	return added, removed, changed
+}
+
+// diffSections lists the top-level settings that differ, going one level
+// deeper into "content" so each website section is reported on its own
+func diffSections(current, next interface{}) []string {
+	before, _ := current.(map[string]interface{})
+	after, _ := next.(map[string]interface{})
+
+	sections := []string{}
+	for _, key := range unionKeys(before, after) {
+		if bookkeepingFields[key] || reflect.DeepEqual(before[key], after[key]) {
+			continue
+		}
+		oldContent, oldOK := before[key].(map[string]interface{})
+		newContent, newOK := after[key].(map[string]interface{})
+		if key != "content" || !oldOK || !newOK {
+			sections = append(sections, key)
+			continue
+		}
+		for _, section := range unionKeys(oldContent, newContent) {
+			if !reflect.DeepEqual(oldContent[section], newContent[section]) {
+				sections = append(sections, "content."+section)
+			}
+		}
+	}
+	return sections
+}
+
+// diffImages compares every image URL found in two sets of published files
+func diffImages(current, next map[string]interface{}) (added, removed []string) {
+	before, after := make(map[string]bool), make(map[string]bool)
+	for _, file := range current {
+		collectImageURLs(file, before)
+	}
+	for _, file := range next {
+		collectImageURLs(file, after)
+	}
+
+	added, removed = []string{}, []string{}
+	for url := range after {
+		if !before[url] {
+			added = append(added, url)
+		}
+	}
+	for url := range before {
+		if !after[url] {
+			removed = append(removed, url)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// collectImageURLs adds the "url" of every image nested in value. The
+// renditions of an image are not counted separately.
+func collectImageURLs(value interface{}, urls map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if url, ok := v["url"].(string); ok && url != "" {
+			urls[url] = true
+		}
+		for key, item := range v {
+			if key != "renditions" {
+				collectImageURLs(item, urls)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectImageURLs(item, urls)
+		}
+	}
+}
+
+// projectsByID indexes a decoded projects.json by project ID
+func projectsByID(value interface{}) map[string]map[string]interface{} {
+	list, _ := value.([]interface{})
+	projects := make(map[string]map[string]interface{}, len(list))
+	for _, item := range list {
+		if project, ok := item.(map[string]interface{}); ok {
+			if id, _ := project["id"].(string); id != "" {
+				projects[id] = project
+			}
+		}
+	}
+	return projects
+}
+
+func projectRef(project map[string]interface{}) ProjectRef {
+	id, _ := project["id"].(string)
+	title, _ := project["title"].(string)
+	slug, _ := project["slug"].(string)
+	return ProjectRef{ID: id, Title: title, Slug: slug}
+}
+
+// unionKeys returns the keys of both maps, sorted
+func unionKeys(a, b map[string]interface{}) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for _, m := range []map[string]interface{}{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,176 @@
+package publish
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+func TestPreview(t *testing.T) {
+	patio := ProjectRef{ID: "patio", Title: "Stone patio", Slug: "stone-patio"}
+	pond := ProjectRef{ID: "pond", Title: "Koi pond", Slug: "koi-pond"}
+	patioImage := "http://localhost/storage/blobs/projects/patio/a.jpg"
+	pondImage := "http://localhost/storage/blobs/projects/pond/b.jpg"
+
+	tests := []struct {
+		name          string
+		unpublished   bool
+		change        func(t *testing.T, p *testPublisher)
+		wantAdded     []ProjectRef
+		wantRemoved   []ProjectRef
+		wantChanged   []ProjectChange
+		wantSections  []string
+		wantImagesIn  []string
+		wantImagesOut []string
+	}{
+		{
+			name:         "before the first publish",
+			unpublished:  true,
+			wantAdded:    []ProjectRef{patio},
+			wantSections: []string{"content", "title", "websiteURL"},
+			wantImagesIn: []string{patioImage},
+		},
+		{
+			name: "nothing changed",
+		},
+		{
+			name: "project edited",
+			change: func(t *testing.T, p *testPublisher) {
+				p.editProject(t, "patio", func(project *models.Project) {
+					project.Description = "Sandstone"
+					project.Tags = []string{"paving"}
+				})
+			},
+			wantChanged: []ProjectChange{{ProjectRef: patio, Fields: []string{"description", "tags"}}},
+		},
+		{
+			name: "project published",
+			change: func(t *testing.T, p *testPublisher) {
+				p.editProject(t, "pond", func(project *models.Project) { project.Status = models.StatusActive })
+			},
+			wantAdded:    []ProjectRef{pond},
+			wantImagesIn: []string{pondImage},
+		},
+		{
+			name: "project unpublished",
+			change: func(t *testing.T, p *testPublisher) {
+				p.editProject(t, "patio", func(project *models.Project) { project.Status = models.StatusDraft })
+			},
+			wantRemoved:   []ProjectRef{patio},
+			wantSections:  []string{"content.hero"},
+			wantImagesOut: []string{patioImage},
+		},
+		{
+			name: "settings edited",
+			change: func(t *testing.T, p *testPublisher) {
+				if err := p.settings.Merge(context.Background(), repository.WebsiteDocument, map[string]interface{}{
+					"title":     "Greener Gardens",
+					"content":   map[string]interface{}{"about": map[string]interface{}{"text": "We build ponds"}},
+					"updatedBy": "editor",
+				}); err != nil {
+					t.Fatalf("Merge: %v", err)
+				}
+			},
+			wantSections: []string{"content.about", "title"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := newTestPublisher(t)
+			releaseID := ""
+			if !tt.unpublished {
+				release, err := p.Publish(ctx, "admin", models.ReleaseManual)
+				if err != nil {
+					t.Fatalf("Publish: %v", err)
+				}
+				releaseID = release.ID
+			}
+			if tt.change != nil {
+				tt.change(t, p)
+			}
+			before, err := p.blobs.List(ctx, "website/")
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+
+			diff, err := p.Preview(ctx)
+			if err != nil {
+				t.Fatalf("Preview: %v", err)
+			}
+
+			empty := func(refs []ProjectRef) []ProjectRef {
+				if refs == nil {
+					return []ProjectRef{}
+				}
+				return refs
+			}
+			if diff.LiveRelease != releaseID {
+				t.Errorf("LiveRelease = %q, want %q", diff.LiveRelease, releaseID)
+			}
+			if !reflect.DeepEqual(diff.Projects.Added, empty(tt.wantAdded)) {
+				t.Errorf("added = %v, want %v", diff.Projects.Added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(diff.Projects.Removed, empty(tt.wantRemoved)) {
+				t.Errorf("removed = %v, want %v", diff.Projects.Removed, tt.wantRemoved)
+			}
+			if len(diff.Projects.Changed) != 0 || len(tt.wantChanged) != 0 {
+				if !reflect.DeepEqual(diff.Projects.Changed, tt.wantChanged) {
+					t.Errorf("changed = %v, want %v", diff.Projects.Changed, tt.wantChanged)
+				}
+			}
+			if len(diff.Sections) != 0 || len(tt.wantSections) != 0 {
+				if !reflect.DeepEqual(diff.Sections, tt.wantSections) {
+					t.Errorf("sections = %v, want %v", diff.Sections, tt.wantSections)
+				}
+			}
+			if len(diff.Images.Added) != 0 || len(tt.wantImagesIn) != 0 {
+				if !reflect.DeepEqual(diff.Images.Added, tt.wantImagesIn) {
+					t.Errorf("images added = %v, want %v", diff.Images.Added, tt.wantImagesIn)
+				}
+			}
+			if len(diff.Images.Removed) != 0 || len(tt.wantImagesOut) != 0 {
+				if !reflect.DeepEqual(diff.Images.Removed, tt.wantImagesOut) {
+					t.Errorf("images removed = %v, want %v", diff.Images.Removed, tt.wantImagesOut)
+				}
+			}
+			wantUnchanged := len(tt.wantAdded)+len(tt.wantRemoved)+len(tt.wantChanged)+len(tt.wantSections)+len(tt.wantImagesIn)+len(tt.wantImagesOut) == 0
+			if diff.Unchanged != wantUnchanged {
+				t.Errorf("Unchanged = %v, want %v", diff.Unchanged, wantUnchanged)
+			}
+
+			after, err := p.blobs.List(ctx, "website/")
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(after) != len(before) {
+				t.Errorf("Preview wrote files: %d stored before, %d after", len(before), len(after))
+			}
+		})
+	}
+}
+
+func TestDiffSections(t *testing.T) {
+	tests := []struct {
+		name          string
+		current, next map[string]interface{}
+		want          []string
+	}{
+		{"equal", map[string]interface{}{"title": "A"}, map[string]interface{}{"title": "A"}, []string{}},
+		{"bookkeeping only", map[string]interface{}{"updatedAt": "1", "version": 1.0}, map[string]interface{}{"updatedAt": "2", "version": 2.0}, []string{}},
+		{"field added", map[string]interface{}{}, map[string]interface{}{"phone": "123"}, []string{"phone"}},
+		{"content section", map[string]interface{}{"content": map[string]interface{}{"hero": 1.0, "about": 1.0}},
+			map[string]interface{}{"content": map[string]interface{}{"hero": 2.0, "about": 1.0}}, []string{"content.hero"}},
+		{"content replaced", map[string]interface{}{"content": "old"},
+			map[string]interface{}{"content": map[string]interface{}{"hero": 1.0}}, []string{"content"}},
+	}
+	for _, tt := range tests {
+		if got := diffSections(tt.current, tt.next); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: diffSections = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package publish
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"log"
+	"mime"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// feedLimit caps the projects listed in the RSS and Atom feeds
+const feedLimit = 50
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	XmlnsAtom string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description,omitempty"`
+	Category    string   `xml:"category,omitempty"`
+	PubDate     string   `xml:"pubDate"`
+	Enclosure   *rssFile `xml:"enclosure"`
+}
+
+type rssFile struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"` // Bytes, required by RSS 2.0
+	Type   string `xml:"type,attr"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Link      atomLink      `xml:"link"`
+	Published string        `xml:"published"`
+	Updated   string        `xml:"updated"`
+	Summary   string        `xml:"summary,omitempty"`
+	Category  *atomCategory `xml:"category"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// feedInfo describes the website a feed belongs to
+type feedInfo struct {
+	URL         string
+	Title       string
+	Description string
+}
+
+// newFeedInfo reads the feed title and description from the website settings
+func newFeedInfo(siteURL string, settings map[string]interface{}) feedInfo {
+	info := feedInfo{URL: siteURL}
+	info.Title, _ = settings["title"].(string)
+	if info.Title == "" {
+		info.Title = siteURL
+	}
+	for _, field := range []string{"description", "tagline"} {
+		if text, _ := settings[field].(string); text != "" {
+			info.Description = text
+			break
+		}
+	}
+	return info
+}
+
+// feedProjects returns up to feedLimit projects, most recently published first
+func feedProjects(projects []models.Project) []models.Project {
+	sorted := append([]models.Project(nil), projects...)
+	sort.SliceStable(sorted, func(i, j int) bool { return publishedAt(sorted[i]).After(publishedAt(sorted[j])) })
+	if len(sorted) > feedLimit {
+		sorted = sorted[:feedLimit]
+	}
+	return sorted
+}
+
+// publishedAt is when a project last went live, or when it was created for
+// projects older than the publish workflow
+func publishedAt(p models.Project) time.Time {
+	if p.PublishedAt != nil {
+		return *p.PublishedAt
+	}
+	return p.CreatedAt
+}
+
+// feedCovers describes each project's cover image as an RSS enclosure, by
+// project ID. The size and type come from the stored file; covers that
+// cannot be read are left out, as an enclosure must state its length.
+func (p *Publisher) feedCovers(ctx context.Context, projects []models.Project) map[string]rssFile {
+	covers := make(map[string]rssFile)
+	for _, project := range feedProjects(projects) {
+		cover, found := coverImage(project)
+		if !found || cover.URL == "" {
+			continue
+		}
+		objectPath := cover.StoragePath
+		if objectPath == "" {
+			objectPath = blob.PathFromURL(cover.URL)
+		}
+		if objectPath == "" {
+			continue
+		}
+		info, err := p.Blobs.Stat(ctx, objectPath)
+		if err != nil {
+			if !errors.Is(err, blob.ErrNotFound) {
+				log.Printf("Failed to read the cover of project %s for the feed: %v", project.ID, err)
+			}
+			continue
+		}
+		mediaType := info.ContentType
+		if mediaType == "" {
+			mediaType = mime.TypeByExtension(path.Ext(objectPath))
+		}
+		if info.Size > 0 && mediaType != "" {
+			covers[project.ID] = rssFile{URL: cover.URL, Length: info.Size, Type: mediaType}
+		}
+	}
+	return covers
+}
+
+// buildRSS writes an RSS 2.0 feed of the active projects, with the covers
+// from feedCovers as enclosures. updated is the last change to the website,
+// so the feed only changes with its content.
+func buildRSS(info feedInfo, projects []models.Project, covers map[string]rssFile, updated time.Time) ([]byte, error) {
+	feed := rssFeed{
+		Version:   "2.0",
+		XmlnsAtom: "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         info.Title,
+			Link:          info.URL + "/",
+			Description:   info.Description,
+			LastBuildDate: updated.UTC().Format(time.RFC1123Z),
+			Self:          atomLink{Href: info.URL + "/" + RSSFile, Rel: "self", Type: "application/rss+xml"},
+			Items:         []rssItem{},
+		},
+	}
+	for _, p := range feedProjects(projects) {
+		link := projectURL(info.URL, p)
+		item := rssItem{
+			Title:       p.Title,
+			Link:        link,
+			GUID:        link,
+			Description: p.Description,
+			Category:    p.Category,
+			PubDate:     publishedAt(p).UTC().Format(time.RFC1123Z),
+		}
+		// Readers show the enclosure as the item's picture
+		if cover, found := covers[p.ID]; found {
+			item.Enclosure = &cover
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	return marshalXML(feed)
+}
+
+// buildAtom writes an Atom feed of the active projects
+func buildAtom(info feedInfo, projects []models.Project, updated time.Time) ([]byte, error) {
+	feed := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		ID:       info.URL + "/",
+		Title:    info.Title,
+		Subtitle: info.Description,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Author:   atomAuthor{Name: info.Title},
+		Links: []atomLink{
+			{Href: info.URL + "/"},
+			{Href: info.URL + "/" + AtomFile, Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: []atomEntry{},
+	}
+	for _, p := range feedProjects(projects) {
+		link := projectURL(info.URL, p)
+		entry := atomEntry{
+			ID:        link,
+			Title:     p.Title,
+			Link:      atomLink{Href: link},
+			Published: publishedAt(p).UTC().Format(time.RFC3339),
+			Updated:   p.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary:   p.Description,
+		}
+		if p.Category != "" {
+			entry.Category = &atomCategory{Term: p.Category}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalXML(feed)
+}
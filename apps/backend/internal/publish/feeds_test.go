@@ -0,0 +1,145 @@
+package publish
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+func TestBuildFeeds(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPublisher(t)
+	if err := p.blobs.Put(ctx, "projects/patio/a.jpg", strings.NewReader("jpeg"), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	// The pond went live after the patio; its cover file is gone
+	live := time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC)
+	p.editProject(t, "pond", func(project *models.Project) {
+		project.Status = models.StatusActive
+		project.PublishedAt = &live
+		project.Description = "Fish & lilies"
+		project.UpdatedAt = live
+	})
+
+	bundle, err := p.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	var rss struct {
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Link        string `xml:"link"`
+				Description string `xml:"description"`
+				PubDate     string `xml:"pubDate"`
+				Enclosure   *struct {
+					URL    string `xml:"url,attr"`
+					Length int64  `xml:"length,attr"`
+					Type   string `xml:"type,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(bundle.Files[RSSFile], &rss); err != nil {
+		t.Fatalf("invalid RSS: %v\n%s", err, bundle.Files[RSSFile])
+	}
+	if rss.Channel.Title != "Green Gardens" || rss.Channel.LastBuildDate != live.Format(time.RFC1123Z) {
+		t.Errorf("channel = %q built %q", rss.Channel.Title, rss.Channel.LastBuildDate)
+	}
+	if len(rss.Channel.Items) != 2 {
+		t.Fatalf("RSS has %d items, want 2", len(rss.Channel.Items))
+	}
+	pond, patio := rss.Channel.Items[0], rss.Channel.Items[1]
+	if pond.Link != "https://example.com/projects/pond" || pond.Description != "Fish & lilies" || pond.PubDate != live.Format(time.RFC1123Z) {
+		t.Errorf("newest item = %+v, want the pond", pond)
+	}
+	if pond.Enclosure != nil {
+		t.Errorf("pond enclosure = %+v, want none for a missing file", pond.Enclosure)
+	}
+	if e := patio.Enclosure; e == nil || e.URL != "http://localhost/storage/blobs/projects/patio/a.jpg" || e.Length != 4 || e.Type != "image/jpeg" {
+		t.Errorf("patio enclosure = %+v", e)
+	}
+
+	var atom struct {
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID       string `xml:"id"`
+			Updated  string `xml:"updated"`
+			Category *struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(bundle.Files[AtomFile], &atom); err != nil {
+		t.Fatalf("invalid Atom: %v\n%s", err, bundle.Files[AtomFile])
+	}
+	var entries []string
+	for _, entry := range atom.Entries {
+		term := ""
+		if entry.Category != nil {
+			term = entry.Category.Term
+		}
+		entries = append(entries, entry.ID+" "+term+" "+entry.Updated)
+	}
+	want := []string{
+		"https://example.com/projects/pond water 2026-05-02T09:00:00Z",
+		"https://example.com/projects/patio hardscape 2026-05-01T09:00:00Z",
+	}
+	if atom.Title != "Green Gardens" || atom.Updated != "2026-05-02T09:00:00Z" || !reflect.DeepEqual(entries, want) {
+		t.Errorf("atom %q updated %s with entries %v, want %v", atom.Title, atom.Updated, entries, want)
+	}
+
+	robots := string(bundle.Files[RobotsFile])
+	if !strings.Contains(robots, "Sitemap: https://example.com/sitemap.xml") {
+		t.Errorf("robots.txt does not announce the sitemap:\n%s", robots)
+	}
+}
+
+func TestBuildWithoutWebsiteURL(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPublisher(t)
+	if err := p.settings.Merge(ctx, repository.WebsiteDocument, map[string]interface{}{"websiteURL": "example.com"}); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	bundle, err := p.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	for _, name := range []string{SitemapFile, RSSFile, AtomFile} {
+		if _, found := bundle.Files[name]; found {
+			t.Errorf("built %s without a valid website URL", name)
+		}
+	}
+	if robots := string(bundle.Files[RobotsFile]); robots != "User-agent: *\nAllow: /\n" {
+		t.Errorf("robots.txt = %q", robots)
+	}
+}
+
+func TestFeedProjects(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var projects []models.Project
+	for i := 0; i < feedLimit+5; i++ {
+		projects = append(projects, models.Project{ID: fmt.Sprintf("p%d", i), CreatedAt: created.Add(time.Duration(i) * time.Hour)})
+	}
+	published := created.Add(-time.Hour)
+	projects[feedLimit+4].PublishedAt = &published // Newest created, but went live first
+
+	got := feedProjects(projects)
+	if len(got) != feedLimit {
+		t.Fatalf("feed lists %d projects, want %d", len(got), feedLimit)
+	}
+	if got[0].ID != fmt.Sprintf("p%d", feedLimit+3) || got[feedLimit-1].ID != "p4" {
+		t.Errorf("feed runs from %s to %s", got[0].ID, got[feedLimit-1].ID)
+	}
+}
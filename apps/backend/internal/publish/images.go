@@ -0,0 +1,105 @@
+package publish
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// publicImage is an image as the website receives it, ready for <img srcset>
+type publicImage struct {
+	ID            string                   `json:"id"`
+	URL           string                   `json:"url"`
+	Caption       string                   `json:"caption"`
+	Alt           string                   `json:"alt"`
+	Width         int                      `json:"width,omitempty"`
+	Height        int                      `json:"height,omitempty"`
+	Srcset        string                   `json:"srcset,omitempty"`
+	Renditions    []models.PublicRendition `json:"renditions,omitempty"`
+	BlurHash      string                   `json:"blurHash,omitempty"`
+	LQIP          string                   `json:"lqip,omitempty"`
+	DominantColor string                   `json:"dominantColor,omitempty"`
+}
+
+// newPublicImage describes a project image for the published JSON
+func newPublicImage(img models.ProjectImage) publicImage {
+	return publicImage{
+		ID:            img.ID,
+		URL:           img.URL,
+		Caption:       img.Caption,
+		Alt:           img.Alt,
+		Width:         img.Width,
+		Height:        img.Height,
+		Srcset:        srcset(img.Renditions),
+		Renditions:    models.PublicRenditions(img.Renditions),
+		BlurHash:      img.BlurHash,
+		LQIP:          img.LQIP,
+		DominantColor: img.DominantColor,
+	}
+}
+
+// srcset formats renditions as an HTML srcset value ("<url> 320w, <url> 640w")
+func srcset(renditions []models.ImageRendition) string {
+	candidates := make([]string, 0, len(renditions))
+	for _, r := range renditions {
+		if r.URL != "" && r.Width > 0 {
+			candidates = append(candidates, r.URL+" "+strconv.Itoa(r.Width)+"w")
+		}
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// addSrcsets walks a generic JSON document and adds a "srcset" next to
+// every "renditions" list, covering images stored in the settings tree
+func addSrcsets(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if list, ok := v["renditions"].([]interface{}); ok {
+			var candidates []string
+			for _, item := range list {
+				r, _ := item.(map[string]interface{})
+				url, _ := r["url"].(string)
+				if width := toInt(r["width"]); url != "" && width > 0 {
+					candidates = append(candidates, url+" "+strconv.Itoa(width)+"w")
+				}
+			}
+			if len(candidates) > 0 {
+				v["srcset"] = strings.Join(candidates, ", ")
+			}
+		}
+		for _, item := range v {
+			addSrcsets(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			addSrcsets(item)
+		}
+	}
+}
+
+// toInt reads a number decoded from JSON (float64) or Firestore (int64)
+func toInt(value interface{}) int {
+	switch n := value.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// coverImage returns the project image used as the cover, or the first one
+func coverImage(p models.Project) (models.ProjectImage, bool) {
+	for _, img := range p.Images {
+		if img.URL == p.CoverImage {
+			return img, true
+		}
+	}
+	if p.CoverImage == "" && len(p.Images) > 0 {
+		return p.Images[0], true
+	}
+	return models.ProjectImage{}, false
+}
@@ -0,0 +1,294 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Where the published website lives in the blob store
+const (
+	ReleasesPrefix = "website/releases/"
+	ManifestPath   = "website/manifest.json"
+)
+
+// Files every release contains
+const (
+	ProjectsFile = "projects.json"
+	ConfigFile   = "websiteConfig.json"
+	RobotsFile   = "robots.txt"
+)
+
+// Files a release contains once the website URL is set
+const (
+	SitemapFile = "sitemap.xml"
+	RSSFile     = "rss.xml"
+	AtomFile    = "atom.xml"
+)
+
+// contentTypes are the content types of the release files
+var contentTypes = map[string]string{
+	ProjectsFile: "application/json",
+	ConfigFile:   "application/json",
+	RobotsFile:   "text/plain; charset=utf-8",
+	SitemapFile:  "application/xml",
+	RSSFile:      "application/rss+xml",
+	AtomFile:     "application/atom+xml",
+}
+
+// fixedPaths are copies of the live files at paths that never change: the
+// JSON files where the website read them before releases existed, for sites
+// not reading the manifest yet, and the files crawlers and feed readers
+// expect at one URL. They are refreshed after every switch, but are not
+// part of the atomic switch.
+var fixedPaths = map[string]string{
+	ProjectsFile: "website/projects.json",
+	ConfigFile:   "website/websiteConfig.json",
+	RobotsFile:   "website/robots.txt",
+	SitemapFile:  "website/sitemap.xml",
+	RSSFile:      "website/rss.xml",
+	AtomFile:     "website/atom.xml",
+}
+
+// ErrNotPublished is returned when no release has gone live yet
+var ErrNotPublished = errors.New("website has not been published")
+
+// ErrIncompleteRelease is returned when rolling back to a release whose files are gone
+var ErrIncompleteRelease = errors.New("release files are missing")
+
+// Manifest is the small pointer the website reads first to find the live release
+type Manifest struct {
+	ReleaseID   string            `json:"releaseId"`
+	PublishedAt time.Time         `json:"publishedAt"`
+	Files       map[string]string `json:"files"` // File name to public URL
+}
+
+// Bundle holds the files of a release before they are written
+type Bundle struct {
+	Files    map[string][]byte
+	Projects int // Active projects included
+}
+
+// Publisher builds the public website files from the projects and settings
+// and writes them as immutable releases
+type Publisher struct {
+	Projects  repository.ProjectRepository
+	Revisions repository.RevisionRepository
+	Settings  repository.SettingsRepository
+	Releases  repository.ReleaseRepository
+	Blobs     blob.Store
+}
+
+// NewPublisher creates a publisher
+func NewPublisher(projects repository.ProjectRepository, revisions repository.RevisionRepository, settings repository.SettingsRepository, releases repository.ReleaseRepository, blobs blob.Store) *Publisher {
+	return &Publisher{Projects: projects, Revisions: revisions, Settings: settings, Releases: releases, Blobs: blobs}
+}
+
+// Publish builds and writes a new release and makes it live. The manifest
+// only moves once every file of the release is stored, so the website
+// never sees new projects with old settings. A failed publish is recorded
+// in the history with its error.
+func (p *Publisher) Publish(ctx context.Context, by, trigger string) (*models.Release, error) {
+	release := &models.Release{
+		ID:          newReleaseID(),
+		Files:       make(map[string]string),
+		PublishedBy: by,
+		PublishedAt: time.Now(),
+		Trigger:     trigger,
+	}
+	if err := p.publish(ctx, release); err != nil {
+		p.removeFiles(ctx, release)
+		failed := &models.Release{
+			ID:          release.ID,
+			PublishedBy: by,
+			PublishedAt: release.PublishedAt,
+			Trigger:     trigger,
+			Error:       err.Error(),
+		}
+		if err := p.Releases.Save(ctx, failed); err != nil {
+			log.Printf("Failed to record failed release %s: %v", release.ID, err)
+		}
+		return nil, err
+	}
+
+	if err := p.Settings.Merge(ctx, repository.WebsiteDocument, map[string]interface{}{
+		"publishedAt": release.PublishedAt,
+		"liveRelease": release.ID,
+	}); err != nil {
+		log.Printf("Failed to record publish of release %s: %v", release.ID, err)
+	}
+	release.Live = true
+	return release, nil
+}
+
+// publish builds and writes release, then switches the website to it
+func (p *Publisher) publish(ctx context.Context, release *models.Release) error {
+	// 1. Build every file first
+	bundle, err := p.Build(ctx)
+	if err != nil {
+		return err
+	}
+	release.Projects = bundle.Projects
+
+	// 2. Write the release under its own folder
+	for name, data := range bundle.Files {
+		objectPath := ReleasesPrefix + release.ID + "/" + name
+		if err := p.Blobs.Put(ctx, objectPath, bytes.NewReader(data), contentType(name)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", objectPath, err)
+		}
+		release.Files[name] = objectPath
+	}
+
+	// 3. Record it in the history, then switch the website over
+	if err := p.Releases.Create(ctx, release); err != nil {
+		return fmt.Errorf("failed to record release %s: %w", release.ID, err)
+	}
+	return p.switchTo(ctx, release)
+}
+
+// Rollback makes an earlier release live again
+func (p *Publisher) Rollback(ctx context.Context, id, by string) (*models.Release, error) {
+	release, err := p.Releases.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if release.Error != "" || len(release.Files) == 0 {
+		return nil, ErrIncompleteRelease
+	}
+	for _, objectPath := range release.Files {
+		if _, err := p.Blobs.Stat(ctx, objectPath); errors.Is(err, blob.ErrNotFound) {
+			return nil, ErrIncompleteRelease
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.switchTo(ctx, release); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	release.RestoredAt = &now
+	release.RestoredBy = by
+	if err := p.Releases.Save(ctx, release); err != nil {
+		log.Printf("Failed to record rollback to release %s: %v", release.ID, err)
+	}
+	if err := p.Settings.Merge(ctx, repository.WebsiteDocument, map[string]interface{}{
+		"liveRelease": release.ID,
+	}); err != nil {
+		log.Printf("Failed to record rollback to release %s: %v", release.ID, err)
+	}
+
+	release.Live = true
+	return release, nil
+}
+
+// Live reads the manifest of the release the website currently shows
+func (p *Publisher) Live(ctx context.Context) (*Manifest, error) {
+	rc, err := p.Blobs.Get(ctx, ManifestPath)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, ErrNotPublished
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ManifestPath, err)
+	}
+	return &manifest, nil
+}
+
+// History returns up to limit releases, newest first, marking the live one
+func (p *Publisher) History(ctx context.Context, before string, limit int) ([]models.Release, error) {
+	releases, err := p.Releases.List(ctx, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := p.Live(ctx)
+	if err != nil && !errors.Is(err, ErrNotPublished) {
+		return nil, err
+	}
+	for i := range releases {
+		releases[i].Live = manifest != nil && releases[i].ID == manifest.ReleaseID
+	}
+	return releases, nil
+}
+
+// switchTo points the manifest at release, then refreshes the fixed copies
+func (p *Publisher) switchTo(ctx context.Context, release *models.Release) error {
+	manifest := Manifest{
+		ReleaseID:   release.ID,
+		PublishedAt: release.PublishedAt,
+		Files:       make(map[string]string, len(release.Files)),
+	}
+	for name, objectPath := range release.Files {
+		manifest.Files[name] = p.Blobs.PublicURL(objectPath)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := p.Blobs.Put(ctx, ManifestPath, bytes.NewReader(data), "application/json"); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ManifestPath, err)
+	}
+
+	for name, objectPath := range release.Files {
+		if fixedPath, ok := fixedPaths[name]; ok {
+			if err := p.copyFile(ctx, objectPath, fixedPath, contentType(name)); err != nil {
+				log.Printf("Failed to refresh %s from release %s: %v", fixedPath, release.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// copyFile copies a stored object to another path
+func (p *Publisher) copyFile(ctx context.Context, from, to, contentType string) error {
+	rc, err := p.Blobs.Get(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	return p.Blobs.Put(ctx, to, bytes.NewReader(data), contentType)
+}
+
+// contentType returns the content type of a release file
+func contentType(name string) string {
+	if t, ok := contentTypes[name]; ok {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// removeFiles deletes the files of a release that never went live
+func (p *Publisher) removeFiles(ctx context.Context, release *models.Release) {
+	for _, objectPath := range release.Files {
+		if err := p.Blobs.Delete(ctx, objectPath); err != nil {
+			log.Printf("Failed to remove %s of failed release %s: %v", objectPath, release.ID, err)
+		}
+	}
+}
+
+// newReleaseID returns an ID that sorts by publish time, e.g. "20260517-093012-4f1c"
+func newReleaseID() string {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
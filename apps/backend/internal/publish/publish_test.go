@@ -0,0 +1,297 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/blob"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+type testPublisher struct {
+	*Publisher
+	projects  *repository.MemoryProjectRepository
+	revisions *repository.MemoryRevisionRepository
+	settings  *repository.MemorySettingsRepository
+	blobs     *failingStore
+}
+
+// failingStore refuses to write objects whose path ends with failSuffix
+type failingStore struct {
+	*blob.LocalStore
+	failSuffix string
+}
+
+func (s *failingStore) Put(ctx context.Context, path string, r io.Reader, contentType string) error {
+	if s.failSuffix != "" && strings.HasSuffix(path, s.failSuffix) {
+		return fmt.Errorf("disk full")
+	}
+	return s.LocalStore.Put(ctx, path, r, contentType)
+}
+
+// newTestPublisher sets up a website with one active and one draft project,
+// the active one shown in the hero section
+func newTestPublisher(t *testing.T) *testPublisher {
+	t.Helper()
+	ctx := context.Background()
+	local, err := blob.NewLocalStore(t.TempDir(), "http://localhost/storage")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	p := &testPublisher{
+		projects:  repository.NewMemoryProjectRepository(),
+		revisions: repository.NewMemoryRevisionRepository(),
+		settings:  repository.NewMemorySettingsRepository(),
+		blobs:     &failingStore{LocalStore: local},
+	}
+	p.Publisher = NewPublisher(p.projects, p.revisions, p.settings, repository.NewMemoryReleaseRepository(), p.blobs)
+
+	updated := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	for _, project := range []models.Project{
+		{ID: "patio", Title: "Stone patio", Slug: "stone-patio", Category: "hardscape", Status: models.StatusActive, CreatedAt: updated, UpdatedAt: updated,
+			Images: []models.ProjectImage{{ID: "a", URL: "http://localhost/storage/blobs/projects/patio/a.jpg", StoragePath: "projects/patio/a.jpg", Caption: "New patio"}}},
+		{ID: "pond", Title: "Koi pond", Slug: "koi-pond", Category: "water", Status: models.StatusDraft, CreatedAt: updated, UpdatedAt: updated,
+			Images: []models.ProjectImage{{ID: "b", URL: "http://localhost/storage/blobs/projects/pond/b.jpg", StoragePath: "projects/pond/b.jpg"}}},
+	} {
+		project := project
+		if err := p.projects.Create(ctx, &project); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if err := p.settings.Merge(ctx, repository.WebsiteDocument, map[string]interface{}{
+		"title":      "Green Gardens",
+		"websiteURL": "https://example.com",
+		"updatedAt":  updated,
+		"content": map[string]interface{}{
+			"hero":  map[string]interface{}{"projects": []interface{}{"patio"}},
+			"about": map[string]interface{}{"text": "We build gardens"},
+		},
+	}); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	return p
+}
+
+// read returns the stored object at path
+func (p *testPublisher) read(t *testing.T, path string) []byte {
+	t.Helper()
+	rc, err := p.blobs.Get(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", path, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll(%s): %v", path, err)
+	}
+	return data
+}
+
+// editProject applies edit to a stored project
+func (p *testPublisher) editProject(t *testing.T, id string, edit func(*models.Project)) {
+	t.Helper()
+	project, err := p.projects.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", id, err)
+	}
+	edit(project)
+	if err := p.projects.Update(context.Background(), project); err != nil {
+		t.Fatalf("Update(%s): %v", id, err)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPublisher(t)
+
+	release, err := p.Publish(ctx, "admin", models.ReleaseManual)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if !release.Live || release.Projects != 1 || release.PublishedBy != "admin" || release.Trigger != models.ReleaseManual {
+		t.Errorf("release = %+v", release)
+	}
+	for _, name := range []string{ProjectsFile, ConfigFile, RobotsFile, SitemapFile, RSSFile, AtomFile} {
+		objectPath := release.Files[name]
+		if objectPath != ReleasesPrefix+release.ID+"/"+name {
+			t.Errorf("%s stored at %q", name, objectPath)
+			continue
+		}
+		if !bytes.Equal(p.read(t, objectPath), p.read(t, fixedPaths[name])) {
+			t.Errorf("%s differs from the release copy", fixedPaths[name])
+		}
+	}
+
+	manifest, err := p.Live(ctx)
+	if err != nil {
+		t.Fatalf("Live: %v", err)
+	}
+	if manifest.ReleaseID != release.ID || manifest.Files[ProjectsFile] != p.blobs.PublicURL(release.Files[ProjectsFile]) {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	settings, err := p.settings.Get(ctx, repository.WebsiteDocument)
+	if err != nil {
+		t.Fatalf("Get settings: %v", err)
+	}
+	if settings["liveRelease"] != release.ID {
+		t.Errorf("liveRelease = %v, want %s", settings["liveRelease"], release.ID)
+	}
+}
+
+func TestPublishFailure(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPublisher(t)
+	first, err := p.Publish(ctx, "admin", models.ReleaseManual)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	p.blobs.failSuffix = "/" + ConfigFile
+	if _, err := p.Publish(ctx, "admin", models.ReleaseManual); err == nil {
+		t.Fatal("Publish succeeded with a failing store")
+	}
+
+	manifest, err := p.Live(ctx)
+	if err != nil {
+		t.Fatalf("Live: %v", err)
+	}
+	if manifest.ReleaseID != first.ID {
+		t.Errorf("failed publish went live as %s", manifest.ReleaseID)
+	}
+	history, err := p.History(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history has %d releases, want 2", len(history))
+	}
+	for _, release := range history {
+		failed := release.ID != first.ID
+		if failed != (release.Error != "") || release.Live == failed {
+			t.Errorf("release %s: error %q, live %v", release.ID, release.Error, release.Live)
+		}
+		if !failed {
+			continue
+		}
+		left, err := p.blobs.List(ctx, ReleasesPrefix+release.ID+"/")
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(left) != 0 {
+			t.Errorf("failed release left %d files behind", len(left))
+		}
+	}
+}
+
+func TestRollback(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  func(p *testPublisher, first, second *models.Release) string
+		wantErr error
+	}{
+		{
+			name:   "earlier release",
+			target: func(p *testPublisher, first, second *models.Release) string { return first.ID },
+		},
+		{
+			name:   "live release",
+			target: func(p *testPublisher, first, second *models.Release) string { return second.ID },
+		},
+		{
+			name:    "unknown release",
+			target:  func(p *testPublisher, first, second *models.Release) string { return "20200101-000000-0000" },
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name: "failed release",
+			target: func(p *testPublisher, first, second *models.Release) string {
+				failed := &models.Release{ID: "20200101-000000-0001", Error: "disk full"}
+				if err := p.Releases.Save(context.Background(), failed); err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+				return failed.ID
+			},
+			wantErr: ErrIncompleteRelease,
+		},
+		{
+			name: "release with missing files",
+			target: func(p *testPublisher, first, second *models.Release) string {
+				if err := p.blobs.Delete(context.Background(), first.Files[ConfigFile]); err != nil {
+					t.Fatalf("Delete: %v", err)
+				}
+				return first.ID
+			},
+			wantErr: ErrIncompleteRelease,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := newTestPublisher(t)
+			first, err := p.Publish(ctx, "admin", models.ReleaseManual)
+			if err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+			p.editProject(t, "patio", func(project *models.Project) { project.Title = "Sandstone patio" })
+			second, err := p.Publish(ctx, "admin", models.ReleaseManual)
+			if err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+			id := tt.target(p, first, second)
+
+			release, err := p.Rollback(ctx, id, "editor")
+			manifest, liveErr := p.Live(ctx)
+			if liveErr != nil {
+				t.Fatalf("Live: %v", liveErr)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Rollback = %v, want %v", err, tt.wantErr)
+				}
+				if manifest.ReleaseID != second.ID {
+					t.Errorf("manifest moved to %s after a refused rollback", manifest.ReleaseID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Rollback: %v", err)
+			}
+
+			if manifest.ReleaseID != id || !release.Live || release.RestoredBy != "editor" || release.RestoredAt == nil {
+				t.Errorf("rolled back to %+v, manifest at %s", release, manifest.ReleaseID)
+			}
+			target, err := p.Releases.Get(ctx, id)
+			if err != nil {
+				t.Fatalf("Get release: %v", err)
+			}
+			if target.RestoredBy != "editor" {
+				t.Errorf("rollback not recorded in the history: %+v", target)
+			}
+			if !bytes.Equal(p.read(t, fixedPaths[ProjectsFile]), p.read(t, target.Files[ProjectsFile])) {
+				t.Errorf("%s not refreshed from release %s", fixedPaths[ProjectsFile], id)
+			}
+			settings, err := p.settings.Get(ctx, repository.WebsiteDocument)
+			if err != nil {
+				t.Fatalf("Get settings: %v", err)
+			}
+			if settings["liveRelease"] != id {
+				t.Errorf("liveRelease = %v, want %s", settings["liveRelease"], id)
+			}
+		})
+	}
+}
+
+func TestLiveBeforeFirstPublish(t *testing.T) {
+	p := newTestPublisher(t)
+	if _, err := p.Live(context.Background()); !errors.Is(err, ErrNotPublished) {
+		t.Errorf("Live = %v, want ErrNotPublished", err)
+	}
+}
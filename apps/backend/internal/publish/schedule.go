@@ -0,0 +1,132 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Job types the scheduler runs on the job queue
+const (
+	TypeScheduledPublish = "website.publish"     // Publishes at the job's RunAt, "by" in the payload
+	TypeAutoPublish      = "website.autopublish" // Publishes unless a later change queued another one
+)
+
+// AutoPublishField holds the ID of the latest auto-publish job in the website
+// settings; earlier jobs find it changed and stand down
+const AutoPublishField = "autoPublishJob"
+
+// scheduledJobsLimit caps the jobs read per type when listing scheduled publishes
+const scheduledJobsLimit = 500
+
+// ErrNotScheduled is returned for job IDs that are not publishes
+var ErrNotScheduled = errors.New("job is not a scheduled publish")
+
+// Scheduler publishes the website from the job queue, at set times or a
+// while after the last change to an active project. The queue persists the
+// jobs and leases each to one worker, so several instances never publish
+// the same job twice.
+type Scheduler struct {
+	Publisher        *Publisher
+	Jobs             *jobs.Queue
+	AutoPublishDelay time.Duration // 0 disables auto-publish
+}
+
+// NewScheduler creates a scheduler and registers its job handlers on queue.
+// Call before the queue starts.
+func NewScheduler(publisher *Publisher, queue *jobs.Queue, autoPublishDelay time.Duration) *Scheduler {
+	s := &Scheduler{Publisher: publisher, Jobs: queue, AutoPublishDelay: autoPublishDelay}
+	queue.Register(TypeScheduledPublish, s.runScheduled)
+	queue.Register(TypeAutoPublish, s.runAuto)
+	return s
+}
+
+// Schedule queues a publish for at
+func (s *Scheduler) Schedule(ctx context.Context, at time.Time, by string) (*models.Job, error) {
+	return s.Jobs.EnqueueAt(ctx, TypeScheduledPublish, map[string]string{"by": by}, at)
+}
+
+// Scheduled lists the publishes waiting to run, soonest first, including
+// failed ones waiting for a retry
+func (s *Scheduler) Scheduled(ctx context.Context) ([]models.Job, error) {
+	scheduled := []models.Job{}
+	for _, jobType := range []string{TypeScheduledPublish, TypeAutoPublish} {
+		pending, err := s.Jobs.List(ctx, repository.JobFilter{Type: jobType, Status: models.JobPending}, scheduledJobsLimit)
+		if err != nil {
+			return nil, err
+		}
+		scheduled = append(scheduled, pending...)
+	}
+	sort.Slice(scheduled, func(i, j int) bool { return scheduled[i].RunAt.Before(scheduled[j].RunAt) })
+	return scheduled, nil
+}
+
+// Cancel drops a scheduled publish before it runs
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	job, err := s.Jobs.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job.Type != TypeScheduledPublish && job.Type != TypeAutoPublish {
+		return ErrNotScheduled
+	}
+	return s.Jobs.Cancel(ctx, id)
+}
+
+// ProjectsChanged debounces auto-publish: it queues a publish
+// AutoPublishDelay from now and supersedes any earlier one still waiting
+func (s *Scheduler) ProjectsChanged(ctx context.Context) error {
+	if s.AutoPublishDelay <= 0 {
+		return nil
+	}
+	job, err := s.Jobs.EnqueueAt(ctx, TypeAutoPublish, map[string]string{}, time.Now().Add(s.AutoPublishDelay))
+	if err != nil {
+		return err
+	}
+	return s.Publisher.Settings.Merge(ctx, repository.WebsiteDocument, map[string]interface{}{
+		AutoPublishField: job.ID,
+	})
+}
+
+// runScheduled handles TypeScheduledPublish jobs
+func (s *Scheduler) runScheduled(ctx context.Context, job models.Job) error {
+	release, err := s.Publisher.Publish(ctx, job.Payload["by"], models.ReleaseScheduled)
+	if err != nil {
+		return err
+	}
+	log.Printf("Published scheduled website release %s", release.ID)
+	return nil
+}
+
+// runAuto handles TypeAutoPublish jobs, publishing only for the latest
+// change and only when the website would actually change
+func (s *Scheduler) runAuto(ctx context.Context, job models.Job) error {
+	settings, err := s.Publisher.Settings.Get(ctx, repository.WebsiteDocument)
+	if err != nil {
+		return fmt.Errorf("failed to read website settings: %w", err)
+	}
+	if latest, _ := settings[AutoPublishField].(string); latest != job.ID {
+		return nil
+	}
+
+	diff, err := s.Publisher.Preview(ctx)
+	if err != nil {
+		return err
+	}
+	if diff.Unchanged {
+		return nil
+	}
+	release, err := s.Publisher.Publish(ctx, "", models.ReleaseAuto)
+	if err != nil {
+		return err
+	}
+	log.Printf("Auto-published website release %s", release.ID)
+	return nil
+}
@@ -0,0 +1,141 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/jobs"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+func newTestScheduler(t *testing.T, autoPublishDelay time.Duration) (*Scheduler, *testPublisher) {
+	t.Helper()
+	p := newTestPublisher(t)
+	return NewScheduler(p.Publisher, jobs.NewQueue(repository.NewMemoryJobRepository()), autoPublishDelay), p
+}
+
+// releases returns the publish history, newest first
+func releases(t *testing.T, p *testPublisher) []models.Release {
+	t.Helper()
+	history, err := p.History(context.Background(), "", 0)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	return history
+}
+
+func TestSchedule(t *testing.T) {
+	ctx := context.Background()
+	s, p := newTestScheduler(t, 0)
+
+	later, err := s.Schedule(ctx, time.Now().Add(time.Hour), "admin")
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	now, err := s.Schedule(ctx, time.Now(), "editor")
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	scheduled, err := s.Scheduled(ctx)
+	if err != nil {
+		t.Fatalf("Scheduled: %v", err)
+	}
+	if len(scheduled) != 2 || scheduled[0].ID != now.ID || scheduled[1].ID != later.ID {
+		t.Errorf("scheduled = %+v, want the due publish first", scheduled)
+	}
+
+	if ran, err := s.Jobs.RunDue(ctx); err != nil || ran != 1 {
+		t.Fatalf("RunDue = %d, %v, want only the due publish run", ran, err)
+	}
+	history := releases(t, p)
+	if len(history) != 1 || history[0].Trigger != models.ReleaseScheduled || history[0].PublishedBy != "editor" || !history[0].Live {
+		t.Errorf("history = %+v", history)
+	}
+	if scheduled, _ := s.Scheduled(ctx); len(scheduled) != 1 || scheduled[0].ID != later.ID {
+		t.Errorf("scheduled after running = %+v", scheduled)
+	}
+}
+
+func TestCancelScheduled(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestScheduler(t, 0)
+	scheduled, err := s.Schedule(ctx, time.Now().Add(time.Hour), "admin")
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	other, err := s.Jobs.Enqueue(ctx, jobs.TypeDeleteBlob, map[string]string{"path": "a.jpg"})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{"scheduled publish", scheduled.ID, nil},
+		{"already cancelled", scheduled.ID, repository.ErrNotFound},
+		{"other job", other.ID, ErrNotScheduled},
+	}
+	for _, tt := range tests {
+		if err := s.Cancel(ctx, tt.id); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: Cancel = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if _, err := s.Jobs.Get(ctx, other.ID); err != nil {
+		t.Errorf("other job was cancelled: %v", err)
+	}
+}
+
+func TestAutoPublish(t *testing.T) {
+	ctx := context.Background()
+
+	disabled, _ := newTestScheduler(t, 0)
+	if err := disabled.ProjectsChanged(ctx); err != nil {
+		t.Fatalf("ProjectsChanged: %v", err)
+	}
+	if scheduled, _ := disabled.Scheduled(ctx); len(scheduled) != 0 {
+		t.Errorf("auto-publish disabled but queued %+v", scheduled)
+	}
+
+	// Two quick changes publish once, for the latest
+	s, p := newTestScheduler(t, time.Nanosecond)
+	for i := 0; i < 2; i++ {
+		if err := s.ProjectsChanged(ctx); err != nil {
+			t.Fatalf("ProjectsChanged: %v", err)
+		}
+	}
+	if ran, err := s.Jobs.RunDue(ctx); err != nil || ran != 2 {
+		t.Fatalf("RunDue = %d, %v", ran, err)
+	}
+	history := releases(t, p)
+	if len(history) != 1 || history[0].Trigger != models.ReleaseAuto {
+		t.Fatalf("history after two changes = %+v, want one auto-publish", history)
+	}
+
+	// A change that leaves the website as it is publishes nothing
+	if err := s.ProjectsChanged(ctx); err != nil {
+		t.Fatalf("ProjectsChanged: %v", err)
+	}
+	if _, err := s.Jobs.RunDue(ctx); err != nil {
+		t.Fatalf("RunDue: %v", err)
+	}
+	if history := releases(t, p); len(history) != 1 {
+		t.Errorf("unchanged website published again: %+v", history)
+	}
+
+	// An edit to an active project does
+	p.editProject(t, "patio", func(project *models.Project) { project.Description = "Sandstone" })
+	if err := s.ProjectsChanged(ctx); err != nil {
+		t.Fatalf("ProjectsChanged: %v", err)
+	}
+	if _, err := s.Jobs.RunDue(ctx); err != nil {
+		t.Fatalf("RunDue: %v", err)
+	}
+	if history := releases(t, p); len(history) != 2 {
+		t.Errorf("history after an edit has %d releases, want 2", len(history))
+	}
+}
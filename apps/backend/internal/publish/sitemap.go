@@ -0,0 +1,133 @@
+package publish
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// sitemapURLSet is the root of sitemap.xml, with Google's image extension
+type sitemapURLSet struct {
+	XMLName    xml.Name     `xml:"urlset"`
+	Xmlns      string       `xml:"xmlns,attr"`
+	XmlnsImage string       `xml:"xmlns:image,attr"`
+	URLs       []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string         `xml:"loc"`
+	LastMod string         `xml:"lastmod,omitempty"`
+	Images  []sitemapImage `xml:"image:image"`
+}
+
+type sitemapImage struct {
+	Loc     string `xml:"image:loc"`
+	Caption string `xml:"image:caption,omitempty"`
+}
+
+// websiteURL returns the website address from the settings without a
+// trailing slash, or an error when it is not an absolute http(s) URL
+func websiteURL(settings map[string]interface{}) (string, error) {
+	raw, _ := settings["websiteURL"].(string)
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	if raw == "" {
+		return "", fmt.Errorf("websiteURL is not set")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("websiteURL %q is not an absolute http(s) URL", raw)
+	}
+	return raw, nil
+}
+
+// projectURL is the public page of a project
+func projectURL(siteURL string, p models.Project) string {
+	return siteURL + "/projects/" + url.PathEscape(p.ID)
+}
+
+// buildSitemap lists the home page, the project list and every project page
+// with its images. Project pages are last modified when the project was.
+func buildSitemap(siteURL string, projects []models.Project, settingsUpdated time.Time) ([]byte, error) {
+	latest := time.Time{}
+	for _, p := range projects {
+		if p.UpdatedAt.After(latest) {
+			latest = p.UpdatedAt
+		}
+	}
+	home := latest
+	if settingsUpdated.After(home) {
+		home = settingsUpdated
+	}
+
+	set := sitemapURLSet{
+		Xmlns:      "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XmlnsImage: "http://www.google.com/schemas/sitemap-image/1.1",
+		URLs: []sitemapURL{
+			{Loc: siteURL + "/", LastMod: lastMod(home)},
+			{Loc: siteURL + "/projects", LastMod: lastMod(latest)},
+		},
+	}
+	for _, p := range projects {
+		entry := sitemapURL{Loc: projectURL(siteURL, p), LastMod: lastMod(p.UpdatedAt)}
+		for _, img := range p.Images {
+			if img.URL != "" {
+				entry.Images = append(entry.Images, sitemapImage{Loc: img.URL, Caption: img.Caption})
+			}
+		}
+		set.URLs = append(set.URLs, entry)
+	}
+	return marshalXML(set)
+}
+
+// lastMod formats t for a sitemap, empty when unknown
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// buildRobots writes robots.txt from the "robots" website settings: noIndex
+// keeps crawlers off the whole site, otherwise the disallow paths are
+// excluded. The sitemap is announced when there is one.
+func buildRobots(settings map[string]interface{}, sitemapURL string) []byte {
+	robots, _ := normalizeJSON(settings["robots"]).(map[string]interface{})
+	noIndex, _ := robots["noIndex"].(bool)
+	list, _ := robots["disallow"].([]interface{})
+	var disallow []string
+	for _, item := range list {
+		if path, _ := item.(string); strings.TrimSpace(path) != "" {
+			disallow = append(disallow, strings.TrimSpace(path))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	switch {
+	case noIndex:
+		b.WriteString("Disallow: /\n")
+	case len(disallow) == 0:
+		b.WriteString("Allow: /\n")
+	default:
+		for _, path := range disallow {
+			b.WriteString("Disallow: " + path + "\n")
+		}
+	}
+	if sitemapURL != "" && !noIndex {
+		b.WriteString("\nSitemap: " + sitemapURL + "\n")
+	}
+	return []byte(b.String())
+}
+
+// marshalXML encodes value as an indented XML document
+func marshalXML(value interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,111 @@
+package publish
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+func TestWebsiteURL(t *testing.T) {
+	tests := []struct {
+		raw     interface{}
+		want    string
+		wantErr bool
+	}{
+		{"https://example.com", "https://example.com", false},
+		{" https://example.com/ ", "https://example.com", false},
+		{"http://example.com/garden", "http://example.com/garden", false},
+		{nil, "", true},
+		{"", "", true},
+		{"example.com", "", true},
+		{"ftp://example.com", "", true},
+		{"https://", "", true},
+	}
+	for _, tt := range tests {
+		got, err := websiteURL(map[string]interface{}{"websiteURL": tt.raw})
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("websiteURL(%v) = %q, %v, want %q (error %v)", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildRobots(t *testing.T) {
+	sitemap := "https://example.com/sitemap.xml"
+	tests := []struct {
+		name       string
+		robots     interface{}
+		sitemapURL string
+		want       string
+	}{
+		{"no settings", nil, sitemap, "User-agent: *\nAllow: /\n\nSitemap: " + sitemap + "\n"},
+		{"no website URL", nil, "", "User-agent: *\nAllow: /\n"},
+		{"disallowed paths", map[string]interface{}{"disallow": []interface{}{"/admin", " ", " /drafts "}}, sitemap,
+			"User-agent: *\nDisallow: /admin\nDisallow: /drafts\n\nSitemap: " + sitemap + "\n"},
+		{"typed disallow list", map[string]interface{}{"disallow": []string{"/admin"}}, "", "User-agent: *\nDisallow: /admin\n"},
+		{"no index", map[string]interface{}{"noIndex": true, "disallow": []interface{}{"/admin"}}, sitemap, "User-agent: *\nDisallow: /\n"},
+	}
+	for _, tt := range tests {
+		got := string(buildRobots(map[string]interface{}{"robots": tt.robots}, tt.sitemapURL))
+		if got != tt.want {
+			t.Errorf("%s: robots.txt = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildSitemap(t *testing.T) {
+	created := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	projects := []models.Project{
+		{ID: "patio", UpdatedAt: created.Add(time.Hour), Images: []models.ProjectImage{
+			{URL: "https://cdn.example.com/a.jpg", Caption: "Patio & steps"},
+			{URL: ""},
+		}},
+		{ID: "koi pond", UpdatedAt: created},
+	}
+	settingsUpdated := created.Add(2 * time.Hour)
+
+	data, err := buildSitemap("https://example.com", projects, settingsUpdated)
+	if err != nil {
+		t.Fatalf("buildSitemap: %v", err)
+	}
+	var set struct {
+		URLs []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+			Images  []struct {
+				Loc     string `xml:"loc"`
+				Caption string `xml:"caption"`
+			} `xml:"image"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(data, &set); err != nil {
+		t.Fatalf("invalid sitemap: %v\n%s", err, data)
+	}
+
+	type entry struct{ loc, lastMod string }
+	var got []entry
+	for _, u := range set.URLs {
+		got = append(got, entry{u.Loc, u.LastMod})
+	}
+	want := []entry{
+		{"https://example.com/", "2026-05-01T11:00:00Z"},
+		{"https://example.com/projects", "2026-05-01T10:00:00Z"},
+		{"https://example.com/projects/patio", "2026-05-01T10:00:00Z"},
+		{"https://example.com/projects/koi%20pond", "2026-05-01T09:00:00Z"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sitemap URLs = %v, want %v", got, want)
+	}
+	if images := set.URLs[2].Images; len(images) != 1 || images[0].Loc != "https://cdn.example.com/a.jpg" || images[0].Caption != "Patio & steps" {
+		t.Errorf("patio images = %+v", images)
+	}
+	if len(set.URLs[3].Images) != 0 {
+		t.Errorf("koi pond images = %+v, want none", set.URLs[3].Images)
+	}
+	if !strings.Contains(string(data), `xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"`) {
+		t.Errorf("sitemap lacks the image namespace:\n%s", data)
+	}
+}
@@ -0,0 +1,184 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+// Settings fields recording who changed which section
+const (
+	UpdatedByField      = "updatedBy"
+	SectionUpdatesField = "sectionUpdates" // Section name, e.g. "content.hero", to {"at", "by"}
+)
+
+// PrivateFields are website settings fields kept off the public site
+var PrivateFields = []string{UpdatedByField, SectionUpdatesField, AutoPublishField}
+
+// revisionLookback caps the revisions read per project to find its editors
+const revisionLookback = 50
+
+// ProjectStatus is a project whose published copy is out of date
+type ProjectStatus struct {
+	ProjectRef
+	Change    string     `json:"change"`           // added, removed or changed
+	Fields    []string   `json:"fields,omitempty"` // For changed projects
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy []string   `json:"updatedBy"` // Everyone who saved it since the live release
+}
+
+// SectionStatus is a website settings section that differs from the live site
+type SectionStatus struct {
+	Section   string     `json:"section"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string     `json:"updatedBy,omitempty"`
+}
+
+// Status reports whether the live website lags behind the admin data
+type Status struct {
+	Stale       bool            `json:"stale"`
+	Changes     int             `json:"changes"` // Projects plus settings sections to publish
+	LiveRelease string          `json:"liveRelease,omitempty"`
+	PublishedAt *time.Time      `json:"publishedAt,omitempty"`
+	Projects    []ProjectStatus `json:"projects"`
+	Sections    []SectionStatus `json:"sections"`
+	Images      struct {
+		Added   int `json:"added"`
+		Removed int `json:"removed"`
+	} `json:"images"`
+}
+
+// Status compares what a publish would write with the live release and
+// explains each difference with when and by whom it was made
+func (p *Publisher) Status(ctx context.Context) (*Status, error) {
+	diff, err := p.Preview(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := p.Settings.Get(ctx, repository.WebsiteDocument)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	status := &Status{
+		Stale:       !diff.Unchanged,
+		LiveRelease: diff.LiveRelease,
+		Projects:    []ProjectStatus{},
+		Sections:    []SectionStatus{},
+	}
+	status.Images.Added, status.Images.Removed = len(diff.Images.Added), len(diff.Images.Removed)
+
+	// The live release's publish time, or the last legacy publish
+	since := time.Time{}
+	if manifest, err := p.Live(ctx); err == nil {
+		since = manifest.PublishedAt
+	} else if at, ok := settings["publishedAt"].(time.Time); ok {
+		since = at
+	}
+	if !since.IsZero() {
+		status.PublishedAt = &since
+	}
+
+	// 1. Projects, with everyone who saved them since
+	add := func(ref ProjectRef, change string, fields []string) error {
+		entry := ProjectStatus{ProjectRef: ref, Change: change, Fields: fields, UpdatedBy: []string{}}
+		if project, err := p.Projects.Get(ctx, ref.ID); err == nil {
+			entry.UpdatedAt = &project.UpdatedAt
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		revisions, err := p.Revisions.List(ctx, ref.ID, 0, revisionLookback)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for _, rev := range revisions {
+			if !rev.At.After(since) {
+				break
+			}
+			if rev.By != "" && !seen[rev.By] {
+				seen[rev.By] = true
+				entry.UpdatedBy = append(entry.UpdatedBy, rev.By)
+			}
+		}
+		status.Projects = append(status.Projects, entry)
+		return nil
+	}
+	for _, ref := range diff.Projects.Added {
+		if err := add(ref, "added", nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, ref := range diff.Projects.Removed {
+		if err := add(ref, "removed", nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, change := range diff.Projects.Changed {
+		if err := add(change.ProjectRef, "changed", change.Fields); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. Settings sections, with their last editor
+	updates, _ := settings[SectionUpdatesField].(map[string]interface{})
+	for _, section := range diff.Sections {
+		entry := SectionStatus{Section: section}
+		if update, ok := updates[section].(map[string]interface{}); ok {
+			if at, ok := update["at"].(time.Time); ok {
+				entry.UpdatedAt = &at
+			}
+			entry.UpdatedBy, _ = update["by"].(string)
+		}
+		status.Sections = append(status.Sections, entry)
+	}
+
+	status.Changes = len(status.Projects) + len(status.Sections)
+	return status, nil
+}
+
+// ChangedSections lists the settings sections a save changes, named as
+// Preview reports them. after is the data being merged, so settings and
+// content sections it leaves out are not compared.
+func ChangedSections(before, after map[string]interface{}) []string {
+	compared := make(map[string]interface{}, len(after))
+	for key := range after {
+		compared[key] = before[key]
+	}
+	oldContent, _ := before["content"].(map[string]interface{})
+	if newContent, ok := after["content"].(map[string]interface{}); ok && oldContent != nil {
+		content := make(map[string]interface{}, len(newContent))
+		for section := range newContent {
+			content[section] = oldContent[section]
+		}
+		compared["content"] = content
+	}
+	return diffSections(normalizeJSON(compared), normalizeJSON(after))
+}
+
+// SectionUpdates records who changed each of sections and when, in the
+// shape stored under SectionUpdatesField
+func SectionUpdates(sections []string, by string, at time.Time) map[string]interface{} {
+	updates := make(map[string]interface{}, len(sections))
+	for _, section := range sections {
+		updates[section] = map[string]interface{}{"at": at, "by": by}
+	}
+	return updates
+}
+
+// normalizeJSON round-trips value through JSON, so documents read from the
+// datastore and built in Go compare equal when they publish the same
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
@@ -0,0 +1,108 @@
+package publish
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"github.com/networkcaretaker/garden_app/backend/internal/repository"
+)
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPublisher(t)
+
+	status, err := p.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !status.Stale || status.PublishedAt != nil || status.LiveRelease != "" {
+		t.Errorf("status before the first publish = %+v", status)
+	}
+
+	release, err := p.Publish(ctx, "admin", models.ReleaseManual)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	status, err = p.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Stale || status.Changes != 0 || status.LiveRelease != release.ID || status.PublishedAt == nil || !status.PublishedAt.Equal(release.PublishedAt) {
+		t.Errorf("status after publishing = %+v", status)
+	}
+
+	// Edit a project twice and a settings section after the publish
+	p.editProject(t, "patio", func(project *models.Project) { project.Description = "Sandstone" })
+	for i, rev := range []models.Revision{
+		{Number: 1, By: "admin", At: release.PublishedAt.Add(-time.Hour)},
+		{Number: 2, By: "alice", At: release.PublishedAt.Add(time.Minute)},
+		{Number: 3, By: "bob", At: release.PublishedAt.Add(2 * time.Minute)},
+		{Number: 4, By: "alice", At: release.PublishedAt.Add(3 * time.Minute)},
+	} {
+		rev.ProjectID, rev.Action = "patio", "update"
+		if err := p.revisions.Add(ctx, &rev); err != nil {
+			t.Fatalf("Add revision %d: %v", i, err)
+		}
+	}
+	editedAt := release.PublishedAt.Add(5 * time.Minute)
+	if err := p.settings.Merge(ctx, repository.WebsiteDocument, map[string]interface{}{
+		"content":           map[string]interface{}{"about": map[string]interface{}{"text": "We build ponds"}},
+		SectionUpdatesField: SectionUpdates([]string{"content.about"}, "carol", editedAt),
+	}); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	status, err = p.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !status.Stale || status.Changes != 2 {
+		t.Errorf("stale = %v with %d changes, want 2", status.Stale, status.Changes)
+	}
+	if len(status.Projects) != 1 {
+		t.Fatalf("projects = %+v", status.Projects)
+	}
+	project := status.Projects[0]
+	if project.ID != "patio" || project.Change != "changed" || !reflect.DeepEqual(project.Fields, []string{"description"}) ||
+		!reflect.DeepEqual(project.UpdatedBy, []string{"alice", "bob"}) || project.UpdatedAt == nil {
+		t.Errorf("project status = %+v", project)
+	}
+	if len(status.Sections) != 1 {
+		t.Fatalf("sections = %+v", status.Sections)
+	}
+	section := status.Sections[0]
+	if section.Section != "content.about" || section.UpdatedBy != "carol" || section.UpdatedAt == nil || !section.UpdatedAt.Equal(editedAt) {
+		t.Errorf("section status = %+v", section)
+	}
+}
+
+func TestChangedSections(t *testing.T) {
+	before := map[string]interface{}{
+		"title": "Green Gardens",
+		"content": map[string]interface{}{
+			"hero":  map[string]interface{}{"projects": []interface{}{"patio"}},
+			"about": map[string]interface{}{"text": "We build gardens"},
+		},
+	}
+	tests := []struct {
+		name  string
+		after map[string]interface{}
+		want  []string
+	}{
+		{"same title", map[string]interface{}{"title": "Green Gardens"}, []string{}},
+		{"new title", map[string]interface{}{"title": "Greener Gardens"}, []string{"title"}},
+		{"one content section", map[string]interface{}{"content": map[string]interface{}{"about": map[string]interface{}{"text": "We build ponds"}}},
+			[]string{"content.about"}},
+		{"section left out", map[string]interface{}{"content": map[string]interface{}{"hero": map[string]interface{}{"projects": []string{"patio"}}}},
+			[]string{}},
+		{"bookkeeping", map[string]interface{}{"updatedAt": time.Now()}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := ChangedSections(before, tt.after); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ChangedSections = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
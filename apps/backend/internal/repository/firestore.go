@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	projectsCollection = "projects"
+	settingsCollection = "settings"
+)
+
+// FirestoreProjectRepository stores projects in the "projects" collection
+type FirestoreProjectRepository struct {
+	client *firestore.Client
+}
+
+// NewFirestoreProjectRepository creates a Firestore backed project repository
+func NewFirestoreProjectRepository(client *firestore.Client) *FirestoreProjectRepository {
+	return &FirestoreProjectRepository{client: client}
+}
+
+// List pushes equality filters, ordering and the page size down to Firestore
+// and applies the remaining filters (all-tags, completedDate range, trash)
+// while iterating, reading further pages only when they drop documents.
+// Queries combining filters with a sort field need composite indexes.
+// Firestore leaves out documents missing a filtered or sorted field, which
+// the in-memory backend does not; BackfillListFields adds them to legacy
+// documents and normalizes their status so both return the same projects.
+func (r *FirestoreProjectRepository) List(ctx context.Context, opts ListOptions) ([]models.Project, string, error) {
+	cur, err := opts.decodeCursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := r.client.Collection(projectsCollection).Query
+	if opts.Status != "" {
+		query = query.Where("status", "==", models.NormalizeStatus(opts.Status))
+	}
+	if opts.Category != "" {
+		query = query.Where("category", "==", opts.Category)
+	}
+	if opts.Featured != nil {
+		query = query.Where("featured", "==", *opts.Featured)
+	}
+	if opts.Location != "" {
+		query = query.Where("location", "==", opts.Location)
+	}
+	if len(opts.Tags) == 1 || (opts.MatchAllTags && len(opts.Tags) > 0) {
+		query = query.Where("tags", "array-contains", opts.Tags[0])
+	} else if len(opts.Tags) > 1 {
+		query = query.Where("tags", "array-contains-any", opts.Tags)
+	}
+
+	direction := firestore.Desc
+	if opts.Ascending {
+		direction = firestore.Asc
+	}
+	query = query.OrderBy(opts.sortField(), direction).OrderBy(firestore.DocumentID, direction)
+	if cur != nil {
+		query = query.StartAfter(cur.sortValue(), cur.ID)
+	}
+
+	// One extra result tells us whether another page exists
+	batchSize := 0
+	if opts.Limit > 0 {
+		batchSize = opts.Limit + 1
+	}
+
+	var projects []models.Project
+	var last *firestore.DocumentSnapshot
+	for {
+		page := query
+		if batchSize > 0 {
+			page = page.Limit(batchSize)
+		}
+		if last != nil {
+			page = page.StartAfter(last)
+		}
+		docs, err := page.Documents(ctx).GetAll()
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, doc := range docs {
+			var p models.Project
+			if err := doc.DataTo(&p); err != nil {
+				continue
+			}
+			p.ID = doc.Ref.ID // Ensure ID is set from Firestore document ID
+			if opts.Matches(p) {
+				projects = append(projects, p)
+			}
+			if batchSize > 0 && len(projects) == batchSize {
+				break
+			}
+		}
+
+		// Stop once the page is full or Firestore has nothing more
+		if batchSize == 0 || len(projects) == batchSize || len(docs) < batchSize {
+			break
+		}
+		last = docs[len(docs)-1]
+	}
+
+	nextCursor := ""
+	if opts.Limit > 0 && len(projects) > opts.Limit {
+		projects = projects[:opts.Limit]
+		nextCursor = opts.cursorFor(projects[len(projects)-1])
+	}
+	return projects, nextCursor, nil
+}
+
+// listFieldDefaults are the values written to legacy documents missing a
+// field List filters or sorts on. createdAt and updatedAt default to the
+// document's own create and update times.
+var listFieldDefaults = map[string]interface{}{
+	"title":         "",
+	"featured":      false,
+	"completedDate": "",
+}
+
+// BackfillListFields adds the fields List filters and sorts on to projects
+// saved without them (by the app before the API existed) and rewrites legacy
+// statuses ("Active", "inactive", none) as workflow statuses, which the status
+// filter compares exactly. It returns how many documents were, or with dryRun
+// would be, updated.
+func (r *FirestoreProjectRepository) BackfillListFields(ctx context.Context, dryRun bool) (int, error) {
+	iter := r.client.Collection(projectsCollection).Documents(ctx)
+	defer iter.Stop()
+
+	updated := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return updated, nil
+		}
+		if err != nil {
+			return updated, err
+		}
+
+		data := doc.Data()
+		var updates []firestore.Update
+		for field, value := range listFieldDefaults {
+			if _, ok := data[field]; !ok {
+				updates = append(updates, firestore.Update{Path: field, Value: value})
+			}
+		}
+		status, _ := data["status"].(string)
+		if normalized := models.NormalizeStatus(status); status != normalized {
+			updates = append(updates, firestore.Update{Path: "status", Value: normalized})
+		}
+		if _, ok := data["createdAt"]; !ok {
+			updates = append(updates, firestore.Update{Path: "createdAt", Value: doc.CreateTime})
+		}
+		if _, ok := data["updatedAt"]; !ok {
+			updates = append(updates, firestore.Update{Path: "updatedAt", Value: doc.UpdateTime})
+		}
+		if len(updates) == 0 {
+			continue
+		}
+
+		updated++
+		if dryRun {
+			continue
+		}
+		// Guard against overwriting a save made since the document was read
+		if _, err := doc.Ref.Update(ctx, updates, firestore.LastUpdateTime(doc.UpdateTime)); err != nil {
+			return updated, fmt.Errorf("failed to backfill project %s: %w", doc.Ref.ID, err)
+		}
+	}
+}
+
+func (r *FirestoreProjectRepository) Get(ctx context.Context, id string) (*models.Project, error) {
+	doc, err := r.client.Collection(projectsCollection).Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var p models.Project
+	if err := doc.DataTo(&p); err != nil {
+		return nil, err
+	}
+	p.ID = doc.Ref.ID
+	return &p, nil
+}
+
+func (r *FirestoreProjectRepository) FindBySlug(ctx context.Context, slug string) (*models.Project, error) {
+	projects := r.client.Collection(projectsCollection)
+	queries := []firestore.Query{
+		projects.Where("slug", "==", slug).Limit(1),
+		projects.Where("previousSlugs", "array-contains", slug).Limit(1),
+	}
+
+	for _, query := range queries {
+		docs, err := query.Documents(ctx).GetAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) == 0 {
+			continue
+		}
+
+		var p models.Project
+		if err := docs[0].DataTo(&p); err != nil {
+			return nil, err
+		}
+		p.ID = docs[0].Ref.ID
+		return &p, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *FirestoreProjectRepository) Create(ctx context.Context, p *models.Project) error {
+	p.Version = 1
+	if p.ID != "" {
+		_, err := r.client.Collection(projectsCollection).Doc(p.ID).Create(ctx, p)
+		if status.Code(err) == codes.AlreadyExists {
+			return ErrConflict
+		}
+		return err
+	}
+
+	ref, _, err := r.client.Collection(projectsCollection).Add(ctx, p)
+	if err != nil {
+		return err
+	}
+	p.ID = ref.ID
+	return nil
+}
+
+func (r *FirestoreProjectRepository) Update(ctx context.Context, p *models.Project) error {
+	docRef := r.client.Collection(projectsCollection).Doc(p.ID)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(docRef)
+		if err != nil {
+			return translateError(err)
+		}
+		var current models.Project
+		if err := snap.DataTo(&current); err != nil {
+			return err
+		}
+		if current.Version != p.Version {
+			return ErrConflict
+		}
+
+		next := *p
+		next.Version++
+		return tx.Set(docRef, &next)
+	})
+	if err != nil {
+		return err
+	}
+	p.Version++
+	return nil
+}
+
+func (r *FirestoreProjectRepository) Delete(ctx context.Context, id string) error {
+	docRef := r.client.Collection(projectsCollection).Doc(id)
+	_, err := docRef.Delete(ctx, firestore.Exists)
+	return translateError(err)
+}
+
+// FirestoreSettingsRepository stores settings documents in the "settings" collection
+type FirestoreSettingsRepository struct {
+	client *firestore.Client
+}
+
+// NewFirestoreSettingsRepository creates a Firestore backed settings repository
+func NewFirestoreSettingsRepository(client *firestore.Client) *FirestoreSettingsRepository {
+	return &FirestoreSettingsRepository{client: client}
+}
+
+func (r *FirestoreSettingsRepository) Get(ctx context.Context, doc string) (map[string]interface{}, error) {
+	snap, err := r.client.Collection(settingsCollection).Doc(doc).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var settings map[string]interface{}
+	if err := snap.DataTo(&settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *FirestoreSettingsRepository) Merge(ctx context.Context, doc string, data map[string]interface{}) error {
+	_, err := r.client.Collection(settingsCollection).Doc(doc).Set(ctx, data, firestore.MergeAll)
+	return err
+}
+
+func (r *FirestoreSettingsRepository) MergeVersioned(ctx context.Context, doc string, data map[string]interface{}, expectedVersion int64) (int64, error) {
+	docRef := r.client.Collection(settingsCollection).Doc(doc)
+
+	var newVersion int64
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var current int64
+		snap, err := tx.Get(docRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			current = SettingsVersion(snap.Data())
+		}
+		if expectedVersion >= 0 && current != expectedVersion {
+			return ErrConflict
+		}
+
+		newVersion = current + 1
+		versioned := make(map[string]interface{}, len(data)+1)
+		for key, value := range data {
+			versioned[key] = value
+		}
+		versioned[VersionField] = newVersion
+		return tx.Set(docRef, versioned, firestore.MergeAll)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return newVersion, nil
+}
+
+// translateError maps Firestore NotFound errors to ErrNotFound
+func translateError(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return ErrNotFound
+	}
+	return err
+}
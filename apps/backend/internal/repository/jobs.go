@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+const jobsCollection = "jobs"
+
+// FirestoreJobRepository stores the job queue in the "jobs" collection
+type FirestoreJobRepository struct {
+	client *firestore.Client
+}
+
+// NewFirestoreJobRepository creates a Firestore backed job repository
+func NewFirestoreJobRepository(client *firestore.Client) *FirestoreJobRepository {
+	return &FirestoreJobRepository{client: client}
+}
+
+func (r *FirestoreJobRepository) Enqueue(ctx context.Context, job *models.Job) error {
+	jobs := r.client.Collection(jobsCollection)
+	ref := jobs.NewDoc()
+	if job.ID != "" {
+		ref = jobs.Doc(job.ID)
+	}
+	job.ID = ref.ID
+	_, err := ref.Create(ctx, job)
+	return err
+}
+
+// ClaimDue needs a composite index on status + runAt
+func (r *FirestoreJobRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.Job, error) {
+	var claimed []models.Job
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		claimed = nil
+		query := r.client.Collection(jobsCollection).
+			Where("status", "in", []string{models.JobPending, models.JobRunning}).
+			Where("runAt", "<=", now).
+			OrderBy("runAt", firestore.Asc).
+			Limit(limit)
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return err
+		}
+
+		for _, doc := range docs {
+			var job models.Job
+			if err := doc.DataTo(&job); err != nil {
+				continue
+			}
+			job.ID = doc.Ref.ID
+			job.Status = models.JobRunning
+			job.Attempts++
+			job.RunAt = now.Add(lease)
+			job.UpdatedAt = now
+			if err := tx.Set(doc.Ref, &job); err != nil {
+				return err
+			}
+			claimed = append(claimed, job)
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func (r *FirestoreJobRepository) ExtendLease(ctx context.Context, id string, attempt int, until time.Time) error {
+	return r.whileClaimed(ctx, id, attempt, func(tx *firestore.Transaction, ref *firestore.DocumentRef) error {
+		return tx.Update(ref, []firestore.Update{
+			{Path: "runAt", Value: until},
+			{Path: "updatedAt", Value: time.Now()},
+		})
+	})
+}
+
+func (r *FirestoreJobRepository) SaveClaimed(ctx context.Context, job *models.Job, attempt int) error {
+	return r.whileClaimed(ctx, job.ID, attempt, func(tx *firestore.Transaction, ref *firestore.DocumentRef) error {
+		return tx.Set(ref, job)
+	})
+}
+
+func (r *FirestoreJobRepository) DeleteClaimed(ctx context.Context, id string, attempt int) error {
+	return r.whileClaimed(ctx, id, attempt, func(tx *firestore.Transaction, ref *firestore.DocumentRef) error {
+		return tx.Delete(ref)
+	})
+}
+
+func (r *FirestoreJobRepository) DeleteUnlessRunning(ctx context.Context, id string) error {
+	ref := r.client.Collection(jobsCollection).Doc(id)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return translateError(err)
+		}
+		var job models.Job
+		if err := doc.DataTo(&job); err != nil {
+			return err
+		}
+		if job.Status == models.JobRunning {
+			return ErrConflict
+		}
+		return tx.Delete(ref)
+	})
+}
+
+// whileClaimed runs write in a transaction as long as the job is still
+// running under the claim made for attempt
+func (r *FirestoreJobRepository) whileClaimed(ctx context.Context, id string, attempt int, write func(tx *firestore.Transaction, ref *firestore.DocumentRef) error) error {
+	ref := r.client.Collection(jobsCollection).Doc(id)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return translateError(err)
+		}
+		var job models.Job
+		if err := doc.DataTo(&job); err != nil {
+			return err
+		}
+		if job.Status != models.JobRunning || job.Attempts != attempt {
+			return ErrConflict
+		}
+		return write(tx, ref)
+	})
+}
+
+func (r *FirestoreJobRepository) Get(ctx context.Context, id string) (*models.Job, error) {
+	doc, err := r.client.Collection(jobsCollection).Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	var job models.Job
+	if err := doc.DataTo(&job); err != nil {
+		return nil, err
+	}
+	job.ID = doc.Ref.ID
+	return &job, nil
+}
+
+func (r *FirestoreJobRepository) Save(ctx context.Context, job *models.Job) error {
+	_, err := r.client.Collection(jobsCollection).Doc(job.ID).Set(ctx, job)
+	return err
+}
+
+func (r *FirestoreJobRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.Collection(jobsCollection).Doc(id).Delete(ctx, firestore.Exists)
+	return translateError(err)
+}
+
+// List needs composite indexes on type, status, payload fields + createdAt
+// for the filters used
+func (r *FirestoreJobRepository) List(ctx context.Context, filter JobFilter, limit int) ([]models.Job, error) {
+	query := r.client.Collection(jobsCollection).Query
+	if filter.Type != "" {
+		query = query.Where("type", "==", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status", "==", filter.Status)
+	}
+	for key, value := range filter.Payload {
+		query = query.WherePath(firestore.FieldPath{"payload", key}, "==", value)
+	}
+	query = query.OrderBy("createdAt", firestore.Asc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]models.Job, 0, len(docs))
+	for _, doc := range docs {
+		var job models.Job
+		if err := doc.DataTo(&job); err != nil {
+			continue
+		}
+		job.ID = doc.Ref.ID
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// MemoryJobRepository keeps the job queue in process memory
+type MemoryJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]models.Job
+}
+
+// NewMemoryJobRepository creates an empty in-memory job repository
+func NewMemoryJobRepository() *MemoryJobRepository {
+	return &MemoryJobRepository{jobs: make(map[string]models.Job)}
+}
+
+func (r *MemoryJobRepository) Enqueue(ctx context.Context, job *models.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if job.ID == "" {
+		job.ID = newID()
+	}
+	r.jobs[job.ID] = cloneJob(*job)
+	return nil
+}
+
+func (r *MemoryJobRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []models.Job
+	for _, job := range r.jobs {
+		if (job.Status == models.JobPending || job.Status == models.JobRunning) && !job.RunAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].RunAt.Before(due[j].RunAt) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].Status = models.JobRunning
+		due[i].Attempts++
+		due[i].RunAt = now.Add(lease)
+		due[i].UpdatedAt = now
+		r.jobs[due[i].ID] = cloneJob(due[i])
+	}
+	return due, nil
+}
+
+func (r *MemoryJobRepository) ExtendLease(ctx context.Context, id string, attempt int, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, err := r.claimed(id, attempt)
+	if err != nil {
+		return err
+	}
+	job.RunAt = until
+	job.UpdatedAt = time.Now()
+	r.jobs[id] = job
+	return nil
+}
+
+func (r *MemoryJobRepository) SaveClaimed(ctx context.Context, job *models.Job, attempt int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.claimed(job.ID, attempt); err != nil {
+		return err
+	}
+	r.jobs[job.ID] = cloneJob(*job)
+	return nil
+}
+
+func (r *MemoryJobRepository) DeleteClaimed(ctx context.Context, id string, attempt int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.claimed(id, attempt); err != nil {
+		return err
+	}
+	delete(r.jobs, id)
+	return nil
+}
+
+func (r *MemoryJobRepository) DeleteUnlessRunning(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if job.Status == models.JobRunning {
+		return ErrConflict
+	}
+	delete(r.jobs, id)
+	return nil
+}
+
+// claimed returns a job that is still running under the claim made for
+// attempt. The caller must hold r.mu.
+func (r *MemoryJobRepository) claimed(id string, attempt int) (models.Job, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return models.Job{}, ErrNotFound
+	}
+	if job.Status != models.JobRunning || job.Attempts != attempt {
+		return models.Job{}, ErrConflict
+	}
+	return job, nil
+}
+
+func (r *MemoryJobRepository) Get(ctx context.Context, id string) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	clone := cloneJob(job)
+	return &clone, nil
+}
+
+func (r *MemoryJobRepository) Save(ctx context.Context, job *models.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.ID] = cloneJob(*job)
+	return nil
+}
+
+func (r *MemoryJobRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.jobs, id)
+	return nil
+}
+
+func (r *MemoryJobRepository) List(ctx context.Context, filter JobFilter, limit int) ([]models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := []models.Job{}
+	for _, job := range r.jobs {
+		if filter.matches(job) {
+			jobs = append(jobs, cloneJob(job))
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+// matches reports whether job passes the filter
+func (f JobFilter) matches(job models.Job) bool {
+	if (f.Type != "" && job.Type != f.Type) || (f.Status != "" && job.Status != f.Status) {
+		return false
+	}
+	for key, value := range f.Payload {
+		if job.Payload[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// cloneJob copies a job's payload so callers cannot mutate stored state
+func cloneJob(job models.Job) models.Job {
+	if job.Payload != nil {
+		payload := make(map[string]string, len(job.Payload))
+		for key, value := range job.Payload {
+			payload[key] = value
+		}
+		job.Payload = payload
+	}
+	return job
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// seedJobs stores jobs in a fresh in-memory repository
+func seedJobs(t *testing.T, jobs ...models.Job) *MemoryJobRepository {
+	t.Helper()
+	repo := NewMemoryJobRepository()
+	for i := range jobs {
+		if err := repo.Enqueue(context.Background(), &jobs[i]); err != nil {
+			t.Fatalf("Enqueue(%s): %v", jobs[i].ID, err)
+		}
+	}
+	return repo
+}
+
+func jobIDs(jobs []models.Job) []string {
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestMemoryJobClaimDue(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	lease := time.Minute
+
+	tests := []struct {
+		name  string
+		job   models.Job
+		claim bool
+	}{
+		{"pending and due", models.Job{Status: models.JobPending, RunAt: now.Add(-time.Second)}, true},
+		{"pending, due exactly now", models.Job{Status: models.JobPending, RunAt: now}, true},
+		{"pending in the future", models.Job{Status: models.JobPending, RunAt: now.Add(time.Second)}, false},
+		{"running with an expired lease", models.Job{Status: models.JobRunning, Attempts: 1, RunAt: now.Add(-time.Second)}, true},
+		{"running with a live lease", models.Job{Status: models.JobRunning, Attempts: 1, RunAt: now.Add(time.Second)}, false},
+		{"dead", models.Job{Status: models.JobDead, RunAt: now.Add(-time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.job.ID = "job"
+			repo := seedJobs(t, tt.job)
+
+			claimed, err := repo.ClaimDue(context.Background(), now, 10, lease)
+			if err != nil {
+				t.Fatalf("ClaimDue: %v", err)
+			}
+			if !tt.claim {
+				if len(claimed) != 0 {
+					t.Fatalf("claimed %v, want nothing", jobIDs(claimed))
+				}
+				return
+			}
+			if len(claimed) != 1 {
+				t.Fatalf("claimed %d jobs, want 1", len(claimed))
+			}
+			stored, err := repo.Get(context.Background(), "job")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			for _, job := range []models.Job{claimed[0], *stored} {
+				if job.Status != models.JobRunning || job.Attempts != tt.job.Attempts+1 || !job.RunAt.Equal(now.Add(lease)) {
+					t.Errorf("job is %s with %d attempts until %v, want running with %d attempts until %v",
+						job.Status, job.Attempts, job.RunAt, tt.job.Attempts+1, now.Add(lease))
+				}
+			}
+
+			// A second worker cannot claim the job while its lease lasts
+			again, err := repo.ClaimDue(context.Background(), now.Add(lease/2), 10, lease)
+			if err != nil {
+				t.Fatalf("ClaimDue: %v", err)
+			}
+			if len(again) != 0 {
+				t.Errorf("job was claimed twice within its lease")
+			}
+		})
+	}
+}
+
+func TestMemoryJobClaimDueLimit(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := seedJobs(t,
+		models.Job{ID: "third", Status: models.JobPending, RunAt: now.Add(-time.Minute)},
+		models.Job{ID: "first", Status: models.JobPending, RunAt: now.Add(-time.Hour)},
+		models.Job{ID: "second", Status: models.JobPending, RunAt: now.Add(-30 * time.Minute)},
+	)
+
+	claimed, err := repo.ClaimDue(context.Background(), now, 2, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+	if got := jobIDs(claimed); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Errorf("claimed %v, want the two most overdue jobs", got)
+	}
+}
+
+func TestMemoryJobClaimedWrites(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     models.Job
+		id      string
+		attempt int
+		wantErr error
+	}{
+		{"current claim", models.Job{Status: models.JobRunning, Attempts: 2}, "job", 2, nil},
+		{"claimed again since", models.Job{Status: models.JobRunning, Attempts: 3}, "job", 2, ErrConflict},
+		{"finished and retrying", models.Job{Status: models.JobPending, Attempts: 2}, "job", 2, ErrConflict},
+		{"dead", models.Job{Status: models.JobDead, Attempts: 2}, "job", 2, ErrConflict},
+		{"deleted", models.Job{Status: models.JobRunning, Attempts: 2}, "missing", 2, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tt.job.ID = "job"
+
+			repo := seedJobs(t, tt.job)
+			update := models.Job{ID: tt.id, Status: models.JobPending, Attempts: tt.attempt, LastError: "failed"}
+			if err := repo.SaveClaimed(ctx, &update, tt.attempt); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SaveClaimed = %v, want %v", err, tt.wantErr)
+			}
+			stored, err := repo.Get(ctx, "job")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if saved := stored.LastError == "failed"; saved != (tt.wantErr == nil) {
+				t.Errorf("saved = %v, want %v", saved, tt.wantErr == nil)
+			}
+
+			repo = seedJobs(t, tt.job)
+			if err := repo.DeleteClaimed(ctx, tt.id, tt.attempt); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteClaimed = %v, want %v", err, tt.wantErr)
+			}
+			_, err = repo.Get(ctx, "job")
+			if deleted := errors.Is(err, ErrNotFound); deleted != (tt.wantErr == nil) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestMemoryJobExtendLease(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		job     models.Job
+		id      string
+		attempt int
+		wantErr error
+	}{
+		{"current claim", models.Job{Status: models.JobRunning, Attempts: 2}, "job", 2, nil},
+		{"claimed again since", models.Job{Status: models.JobRunning, Attempts: 3}, "job", 2, ErrConflict},
+		{"finished and retrying", models.Job{Status: models.JobPending, Attempts: 2}, "job", 2, ErrConflict},
+		{"dead", models.Job{Status: models.JobDead, Attempts: 2}, "job", 2, ErrConflict},
+		{"deleted", models.Job{Status: models.JobRunning, Attempts: 2}, "missing", 2, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.job.ID = "job"
+			tt.job.RunAt = now
+			repo := seedJobs(t, tt.job)
+
+			if err := repo.ExtendLease(context.Background(), tt.id, tt.attempt, until); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExtendLease = %v, want %v", err, tt.wantErr)
+			}
+			stored, err := repo.Get(context.Background(), "job")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			want := now
+			if tt.wantErr == nil {
+				want = until
+			}
+			if !stored.RunAt.Equal(want) {
+				t.Errorf("runAt = %v, want %v", stored.RunAt, want)
+			}
+		})
+	}
+}
+
+func TestMemoryJobList(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := seedJobs(t,
+		models.Job{ID: "a", Type: "blob.delete", Status: models.JobPending, Payload: map[string]string{"path": "images/a.jpg"}, CreatedAt: created},
+		models.Job{ID: "b", Type: "blob.delete", Status: models.JobRunning, Payload: map[string]string{"path": "images/b.jpg"}, CreatedAt: created.Add(time.Minute)},
+		models.Job{ID: "c", Type: "site.publish", Status: models.JobPending, CreatedAt: created.Add(2 * time.Minute)},
+		models.Job{ID: "d", Type: "blob.delete", Status: models.JobDead, Payload: map[string]string{"path": "images/a.jpg"}, CreatedAt: created.Add(3 * time.Minute)},
+	)
+
+	tests := []struct {
+		name   string
+		filter JobFilter
+		limit  int
+		want   []string
+	}{
+		{"everything, oldest first", JobFilter{}, 0, []string{"a", "b", "c", "d"}},
+		{"limit keeps the oldest", JobFilter{}, 2, []string{"a", "b"}},
+		{"type", JobFilter{Type: "blob.delete"}, 0, []string{"a", "b", "d"}},
+		{"status", JobFilter{Status: models.JobPending}, 0, []string{"a", "c"}},
+		{"type and status", JobFilter{Type: "blob.delete", Status: models.JobPending}, 0, []string{"a"}},
+		{"payload", JobFilter{Payload: map[string]string{"path": "images/a.jpg"}}, 0, []string{"a", "d"}},
+		{"payload field missing", JobFilter{Payload: map[string]string{"path": "images/c.jpg"}}, 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs, err := repo.List(context.Background(), tt.filter, tt.limit)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			got := make([]string, 0, len(jobs))
+			for _, job := range jobs {
+				got = append(got, job.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryJobDeleteUnlessRunning(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     models.Job
+		id      string
+		wantErr error
+	}{
+		{"pending", models.Job{Status: models.JobPending}, "job", nil},
+		{"dead", models.Job{Status: models.JobDead}, "job", nil},
+		{"running", models.Job{Status: models.JobRunning}, "job", ErrConflict},
+		{"missing", models.Job{Status: models.JobPending}, "missing", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tt.job.ID = "job"
+			repo := seedJobs(t, tt.job)
+
+			if err := repo.DeleteUnlessRunning(ctx, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteUnlessRunning = %v, want %v", err, tt.wantErr)
+			}
+			_, err := repo.Get(ctx, "job")
+			if deleted := errors.Is(err, ErrNotFound); deleted != (tt.wantErr == nil) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantErr == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+// or was issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Sort fields supported by ProjectRepository.List
+const (
+	SortCreatedAt     = "createdAt"
+	SortUpdatedAt     = "updatedAt"
+	SortCompletedDate = "completedDate"
+	SortTitle         = "title"
+)
+
+// ListOptions narrows down and orders the projects returned by ProjectRepository.List.
+// The zero value lists every project, newest first.
+type ListOptions struct {
+	Status        string   // Only return projects with this exact status when set
+	Category      string   // Exact category match
+	Tags          []string // Projects carrying any (or all, see MatchAllTags) of these tags
+	MatchAllTags  bool
+	Featured      *bool
+	Location      string // Exact location match
+	CompletedFrom string // Inclusive lower bound on completedDate (YYYY-MM-DD)
+	CompletedTo   string // Inclusive upper bound on completedDate (YYYY-MM-DD)
+	Trashed       bool   // List only projects in the trash; otherwise they are left out
+
+	SortBy    string // One of the Sort* constants, defaults to SortCreatedAt
+	Ascending bool
+
+	Limit  int    // Page size, 0 returns everything
+	Cursor string // NextCursor from the previous page
+}
+
+// sortField returns the effective sort field
+func (o ListOptions) sortField() string {
+	if o.SortBy == "" {
+		return SortCreatedAt
+	}
+	return o.SortBy
+}
+
+// ValidSortField reports whether field can be used as ListOptions.SortBy
+func ValidSortField(field string) bool {
+	switch field {
+	case SortCreatedAt, SortUpdatedAt, SortCompletedDate, SortTitle:
+		return true
+	}
+	return false
+}
+
+// Matches reports whether p passes every filter in o
+func (o ListOptions) Matches(p models.Project) bool {
+	if (p.DeletedAt != nil) != o.Trashed {
+		return false
+	}
+	if o.Status != "" && models.NormalizeStatus(p.Status) != models.NormalizeStatus(o.Status) {
+		return false
+	}
+	if o.Category != "" && p.Category != o.Category {
+		return false
+	}
+	if o.Featured != nil && p.Featured != *o.Featured {
+		return false
+	}
+	if o.Location != "" && p.Location != o.Location {
+		return false
+	}
+	if o.CompletedFrom != "" && (p.CompletedDate == "" || p.CompletedDate < o.CompletedFrom) {
+		return false
+	}
+	// Compare only the date part so "2024-05-01" includes "2024-05-01T10:00"
+	if o.CompletedTo != "" && (p.CompletedDate == "" || truncateDate(p.CompletedDate) > o.CompletedTo) {
+		return false
+	}
+	if len(o.Tags) > 0 {
+		projectTags := make(map[string]bool, len(p.Tags))
+		for _, t := range p.Tags {
+			projectTags[t] = true
+		}
+		matched := 0
+		for _, t := range o.Tags {
+			if projectTags[t] {
+				matched++
+			}
+		}
+		if o.MatchAllTags && matched < len(o.Tags) {
+			return false
+		}
+		if !o.MatchAllTags && matched == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func truncateDate(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
+}
+
+// cursor marks the last item of a page: its sort value and ID
+type cursor struct {
+	SortBy    string    `json:"s"`
+	Ascending bool      `json:"a,omitempty"`
+	Time      time.Time `json:"t,omitempty"`
+	Text      string    `json:"x,omitempty"`
+	ID        string    `json:"id"`
+}
+
+// cursorFor builds the cursor pointing just past p
+func (o ListOptions) cursorFor(p models.Project) string {
+	cur := cursor{SortBy: o.sortField(), Ascending: o.Ascending, ID: p.ID}
+	switch cur.SortBy {
+	case SortCreatedAt:
+		cur.Time = p.CreatedAt
+	case SortUpdatedAt:
+		cur.Time = p.UpdatedAt
+	case SortCompletedDate:
+		cur.Text = p.CompletedDate
+	case SortTitle:
+		cur.Text = p.Title
+	}
+
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor parses o.Cursor, returning nil when no cursor was given
+func (o ListOptions) decodeCursor() (*cursor, error) {
+	if o.Cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(o.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cur cursor
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if cur.SortBy != o.sortField() || cur.Ascending != o.Ascending {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
+
+// sortValue returns the cursor's value in the type Firestore orders by
+func (cur *cursor) sortValue() interface{} {
+	if cur.SortBy == SortCreatedAt || cur.SortBy == SortUpdatedAt {
+		return cur.Time
+	}
+	return cur.Text
+}
+
+// compare orders two projects by the sort field then ID, honouring direction.
+// It returns a negative number when a comes first.
+func (o ListOptions) compare(a, b models.Project) int {
+	result := 0
+	switch o.sortField() {
+	case SortCreatedAt:
+		result = a.CreatedAt.Compare(b.CreatedAt)
+	case SortUpdatedAt:
+		result = a.UpdatedAt.Compare(b.UpdatedAt)
+	case SortCompletedDate:
+		result = strings.Compare(a.CompletedDate, b.CompletedDate)
+	case SortTitle:
+		result = strings.Compare(a.Title, b.Title)
+	}
+	if result == 0 {
+		result = strings.Compare(a.ID, b.ID)
+	}
+	if !o.Ascending {
+		result = -result
+	}
+	return result
+}
+
+// afterCursor reports whether p sorts strictly after the cursor position
+func (o ListOptions) afterCursor(p models.Project, cur *cursor) bool {
+	marker := models.Project{ID: cur.ID, CreatedAt: cur.Time, UpdatedAt: cur.Time, CompletedDate: cur.Text, Title: cur.Text}
+	return o.compare(p, marker) > 0
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// projectIDs returns the IDs of projects, sorted so results can be compared
+// regardless of the list order
+func projectIDs(projects []models.Project) []string {
+	ids := make([]string, 0, len(projects))
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestMemoryProjectListFilters(t *testing.T) {
+	trashedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := seedProjects(t,
+		models.Project{ID: "patio", Status: "active", Category: "hardscape", Tags: []string{"stone", "paving"}, Featured: true, Location: "Leeds", CompletedDate: "2024-03-10"},
+		models.Project{ID: "pond", Status: "active", Category: "water", Tags: []string{"stone"}, Location: "York", CompletedDate: "2024-05-01T14:00"},
+		models.Project{ID: "lawn", Status: "draft", Category: "planting", Tags: []string{"turf"}, Location: "Leeds"},
+		models.Project{ID: "wall", Status: "archived", Category: "hardscape", Tags: []string{"stone", "brick"}, CompletedDate: "2023-11-20"},
+		models.Project{ID: "shed", Status: "active", Category: "hardscape", DeletedAt: &trashedAt},
+	)
+	featured, notFeatured := true, false
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"everything but the trash", ListOptions{}, []string{"lawn", "patio", "pond", "wall"}},
+		{"status", ListOptions{Status: "active"}, []string{"patio", "pond"}},
+		{"category", ListOptions{Category: "hardscape"}, []string{"patio", "wall"}},
+		{"any tag", ListOptions{Tags: []string{"paving", "turf"}}, []string{"lawn", "patio"}},
+		{"all tags", ListOptions{Tags: []string{"stone", "brick"}, MatchAllTags: true}, []string{"wall"}},
+		{"featured", ListOptions{Featured: &featured}, []string{"patio"}},
+		{"not featured", ListOptions{Featured: &notFeatured}, []string{"lawn", "pond", "wall"}},
+		{"location", ListOptions{Location: "Leeds"}, []string{"lawn", "patio"}},
+		{"completed from", ListOptions{CompletedFrom: "2024-01-01"}, []string{"patio", "pond"}},
+		{"completed to includes the whole day", ListOptions{CompletedTo: "2024-05-01"}, []string{"patio", "pond", "wall"}},
+		{"completed range", ListOptions{CompletedFrom: "2024-01-01", CompletedTo: "2024-04-30"}, []string{"patio"}},
+		{"combined", ListOptions{Status: "active", Tags: []string{"stone"}, Location: "York"}, []string{"pond"}},
+		{"trash only", ListOptions{Trashed: true}, []string{"shed"}},
+		{"no match", ListOptions{Category: "lighting"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, next, err := repo.List(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if next != "" {
+				t.Errorf("next cursor = %q, want none without a limit", next)
+			}
+			if got := projectIDs(projects); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryProjectListCursor(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var projects []models.Project
+	for i, title := range []string{"Edging", "Arbour", "Decking", "Border", "Cobbles"} {
+		projects = append(projects, models.Project{
+			ID:        fmt.Sprintf("p%d", i),
+			Title:     title,
+			CreatedAt: created.Add(time.Duration(i/2) * time.Hour), // Pairs share a timestamp
+		})
+	}
+	repo := seedProjects(t, projects...)
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"newest first, ties by ID", ListOptions{}, []string{"p4", "p3", "p2", "p1", "p0"}},
+		{"oldest first", ListOptions{Ascending: true}, []string{"p0", "p1", "p2", "p3", "p4"}},
+		{"title", ListOptions{SortBy: SortTitle, Ascending: true}, []string{"p1", "p3", "p4", "p2", "p0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Limit = 2
+			var got []string
+			for page := 0; ; page++ {
+				if page > len(projects) {
+					t.Fatal("pagination did not end")
+				}
+				items, next, err := repo.List(context.Background(), opts)
+				if err != nil {
+					t.Fatalf("List page %d: %v", page, err)
+				}
+				if len(items) > opts.Limit {
+					t.Fatalf("page %d has %d items, limit is %d", page, len(items), opts.Limit)
+				}
+				for _, p := range items {
+					got = append(got, p.ID)
+				}
+				if next == "" {
+					break
+				}
+				opts.Cursor = next
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryProjectListInvalidCursor(t *testing.T) {
+	repo := seedProjects(t, models.Project{ID: "a"}, models.Project{ID: "b"})
+	_, next, err := repo.List(context.Background(), ListOptions{Limit: 1})
+	if err != nil || next == "" {
+		t.Fatalf("List = %q, %v, want a next cursor", next, err)
+	}
+
+	tests := []struct {
+		name string
+		opts ListOptions
+	}{
+		{"garbage", ListOptions{Cursor: "not a cursor!"}},
+		{"other sort field", ListOptions{Cursor: next, SortBy: SortTitle}},
+		{"other direction", ListOptions{Cursor: next, Ascending: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := repo.List(context.Background(), tt.opts); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestMemoryProjectListLegacyStatus(t *testing.T) {
+	repo := seedProjects(t,
+		models.Project{ID: "patio", Status: models.StatusActive},
+		models.Project{ID: "gate", Status: "Active"},
+		models.Project{ID: "fence", Status: "inactive"},
+		models.Project{ID: "arch"},
+	)
+
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{models.StatusActive, []string{"gate", "patio"}},
+		{models.StatusDraft, []string{"arch", "fence"}},
+	}
+	for _, tt := range tests {
+		projects, _, err := repo.List(context.Background(), ListOptions{Status: tt.status})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if got := projectIDs(projects); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("status %q: got %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
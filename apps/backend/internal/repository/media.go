@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const mediaCollection = "media"
+
+// FirestoreMediaRepository stores the media index in the "media" collection,
+// one document per content hash
+type FirestoreMediaRepository struct {
+	client *firestore.Client
+}
+
+// NewFirestoreMediaRepository creates a Firestore backed media repository
+func NewFirestoreMediaRepository(client *firestore.Client) *FirestoreMediaRepository {
+	return &FirestoreMediaRepository{client: client}
+}
+
+func (r *FirestoreMediaRepository) Get(ctx context.Context, hash string) (*models.MediaAsset, error) {
+	doc, err := r.client.Collection(mediaCollection).Doc(hash).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	var asset models.MediaAsset
+	if err := doc.DataTo(&asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *FirestoreMediaRepository) Create(ctx context.Context, asset *models.MediaAsset) error {
+	_, err := r.client.Collection(mediaCollection).Doc(asset.Hash).Create(ctx, asset)
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *FirestoreMediaRepository) AddReference(ctx context.Context, asset *models.MediaAsset, ref string) error {
+	docRef := r.client.Collection(mediaCollection).Doc(asset.Hash)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		_, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			created := *asset
+			created.References = []string{ref}
+			created.UpdatedAt = time.Now()
+			return tx.Create(docRef, &created)
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Update(docRef, []firestore.Update{
+			{Path: "references", Value: firestore.ArrayUnion(ref)},
+			{Path: "updatedAt", Value: time.Now()},
+		})
+	})
+}
+
+func (r *FirestoreMediaRepository) RemoveReference(ctx context.Context, hash, ref string) (*models.MediaAsset, error) {
+	docRef := r.client.Collection(mediaCollection).Doc(hash)
+	var remaining *models.MediaAsset
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return translateError(err)
+		}
+		var asset models.MediaAsset
+		if err := doc.DataTo(&asset); err != nil {
+			return err
+		}
+		asset.References = withoutRef(asset.References, ref)
+		asset.UpdatedAt = time.Now()
+		remaining = &asset
+
+		if len(asset.References) == 0 {
+			return tx.Delete(docRef)
+		}
+		return tx.Update(docRef, []firestore.Update{
+			{Path: "references", Value: asset.References},
+			{Path: "updatedAt", Value: asset.UpdatedAt},
+		})
+	})
+	return remaining, err
+}
+
+func (r *FirestoreMediaRepository) Delete(ctx context.Context, hash string) error {
+	_, err := r.client.Collection(mediaCollection).Doc(hash).Delete(ctx, firestore.Exists)
+	return translateError(err)
+}
+
+func (r *FirestoreMediaRepository) List(ctx context.Context) ([]models.MediaAsset, error) {
+	docs, err := r.client.Collection(mediaCollection).OrderBy("createdAt", firestore.Desc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]models.MediaAsset, 0, len(docs))
+	for _, doc := range docs {
+		var asset models.MediaAsset
+		if err := doc.DataTo(&asset); err != nil {
+			continue
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+func (r *FirestoreMediaRepository) UpdateDetails(ctx context.Context, hash, caption, alt string, tags []string) (*models.MediaAsset, error) {
+	docRef := r.client.Collection(mediaCollection).Doc(hash)
+	_, err := docRef.Update(ctx, []firestore.Update{
+		{Path: "caption", Value: caption},
+		{Path: "alt", Value: alt},
+		{Path: "tags", Value: tags},
+		{Path: "updatedAt", Value: time.Now()},
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return r.Get(ctx, hash)
+}
+
+// MemoryMediaRepository keeps the media index in process memory
+type MemoryMediaRepository struct {
+	mu     sync.Mutex
+	assets map[string]models.MediaAsset
+}
+
+// NewMemoryMediaRepository creates an empty in-memory media repository
+func NewMemoryMediaRepository() *MemoryMediaRepository {
+	return &MemoryMediaRepository{assets: make(map[string]models.MediaAsset)}
+}
+
+func (r *MemoryMediaRepository) Get(ctx context.Context, hash string) (*models.MediaAsset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	asset, ok := r.assets[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	clone := cloneMediaAsset(asset)
+	return &clone, nil
+}
+
+func (r *MemoryMediaRepository) Create(ctx context.Context, asset *models.MediaAsset) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.assets[asset.Hash]; ok {
+		return ErrConflict
+	}
+	r.assets[asset.Hash] = cloneMediaAsset(*asset)
+	return nil
+}
+
+func (r *MemoryMediaRepository) AddReference(ctx context.Context, asset *models.MediaAsset, ref string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.assets[asset.Hash]
+	if !ok {
+		stored = cloneMediaAsset(*asset)
+		stored.References = nil
+	}
+	for _, existing := range stored.References {
+		if existing == ref {
+			return nil
+		}
+	}
+	stored.References = append(stored.References, ref)
+	stored.UpdatedAt = time.Now()
+	r.assets[asset.Hash] = stored
+	return nil
+}
+
+func (r *MemoryMediaRepository) RemoveReference(ctx context.Context, hash, ref string) (*models.MediaAsset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	asset, ok := r.assets[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	asset.References = withoutRef(asset.References, ref)
+	asset.UpdatedAt = time.Now()
+	if len(asset.References) == 0 {
+		delete(r.assets, hash)
+	} else {
+		r.assets[hash] = asset
+	}
+	clone := cloneMediaAsset(asset)
+	return &clone, nil
+}
+
+func (r *MemoryMediaRepository) Delete(ctx context.Context, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.assets[hash]; !ok {
+		return ErrNotFound
+	}
+	delete(r.assets, hash)
+	return nil
+}
+
+func (r *MemoryMediaRepository) List(ctx context.Context) ([]models.MediaAsset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	assets := make([]models.MediaAsset, 0, len(r.assets))
+	for _, asset := range r.assets {
+		assets = append(assets, cloneMediaAsset(asset))
+	}
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].CreatedAt.After(assets[j].CreatedAt)
+	})
+	return assets, nil
+}
+
+func (r *MemoryMediaRepository) UpdateDetails(ctx context.Context, hash, caption, alt string, tags []string) (*models.MediaAsset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	asset, ok := r.assets[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	asset.Caption = caption
+	asset.Alt = alt
+	asset.Tags = append([]string(nil), tags...)
+	asset.UpdatedAt = time.Now()
+	r.assets[hash] = asset
+	clone := cloneMediaAsset(asset)
+	return &clone, nil
+}
+
+// withoutRef returns refs without ref, as a new slice
+func withoutRef(refs []string, ref string) []string {
+	out := make([]string, 0, len(refs))
+	for _, existing := range refs {
+		if existing != ref {
+			out = append(out, existing)
+		}
+	}
+	return out
+}
+
+func cloneMediaAsset(asset models.MediaAsset) models.MediaAsset {
+	if asset.References != nil {
+		asset.References = append([]string(nil), asset.References...)
+	}
+	if asset.Tags != nil {
+		asset.Tags = append([]string(nil), asset.Tags...)
+	}
+	if asset.Image.Renditions != nil {
+		asset.Image.Renditions = append([]models.ImageRendition(nil), asset.Image.Renditions...)
+	}
+	return asset
+}
@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"sort"
+	"sync"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// MemoryProjectRepository keeps projects in process memory.
+// Useful for tests and local demos without cloud credentials.
+type MemoryProjectRepository struct {
+	mu       sync.RWMutex
+	projects map[string]models.Project
+}
+
+// NewMemoryProjectRepository creates an empty in-memory project repository
+func NewMemoryProjectRepository() *MemoryProjectRepository {
+	return &MemoryProjectRepository{projects: make(map[string]models.Project)}
+}
+
+func (r *MemoryProjectRepository) List(ctx context.Context, opts ListOptions) ([]models.Project, string, error) {
+	cur, err := opts.decodeCursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var projects []models.Project
+	for _, p := range r.projects {
+		if !opts.Matches(p) {
+			continue
+		}
+		if cur != nil && !opts.afterCursor(p, cur) {
+			continue
+		}
+		projects = append(projects, cloneProject(p))
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return opts.compare(projects[i], projects[j]) < 0
+	})
+
+	nextCursor := ""
+	if opts.Limit > 0 && len(projects) > opts.Limit {
+		projects = projects[:opts.Limit]
+		nextCursor = opts.cursorFor(projects[len(projects)-1])
+	}
+	return projects, nextCursor, nil
+}
+
+func (r *MemoryProjectRepository) Get(ctx context.Context, id string) (*models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.projects[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	clone := cloneProject(p)
+	return &clone, nil
+}
+
+func (r *MemoryProjectRepository) FindBySlug(ctx context.Context, slug string) (*models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Current slugs take precedence over previous ones
+	for _, p := range r.projects {
+		if p.Slug == slug {
+			clone := cloneProject(p)
+			return &clone, nil
+		}
+	}
+	for _, p := range r.projects {
+		for _, previous := range p.PreviousSlugs {
+			if previous == slug {
+				clone := cloneProject(p)
+				return &clone, nil
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryProjectRepository) Create(ctx context.Context, p *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p.ID == "" {
+		p.ID = newID()
+	} else if _, exists := r.projects[p.ID]; exists {
+		return ErrConflict
+	}
+	p.Version = 1
+	r.projects[p.ID] = cloneProject(*p)
+	return nil
+}
+
+func (r *MemoryProjectRepository) Update(ctx context.Context, p *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.projects[p.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if current.Version != p.Version {
+		return ErrConflict
+	}
+	p.Version++
+	r.projects[p.ID] = cloneProject(*p)
+	return nil
+}
+
+func (r *MemoryProjectRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.projects, id)
+	return nil
+}
+
+// MemorySettingsRepository keeps settings documents in process memory
+type MemorySettingsRepository struct {
+	mu   sync.RWMutex
+	docs map[string]map[string]interface{}
+}
+
+// NewMemorySettingsRepository creates an empty in-memory settings repository
+func NewMemorySettingsRepository() *MemorySettingsRepository {
+	return &MemorySettingsRepository{docs: make(map[string]map[string]interface{})}
+}
+
+func (r *MemorySettingsRepository) Get(ctx context.Context, doc string) (map[string]interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings, ok := r.docs[doc]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneValue(settings).(map[string]interface{}), nil
+}
+
+func (r *MemorySettingsRepository) Merge(ctx context.Context, doc string, data map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings, ok := r.docs[doc]
+	if !ok {
+		settings = make(map[string]interface{})
+		r.docs[doc] = settings
+	}
+	mergeMaps(settings, data)
+	return nil
+}
+
+func (r *MemorySettingsRepository) MergeVersioned(ctx context.Context, doc string, data map[string]interface{}, expectedVersion int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings, ok := r.docs[doc]
+	if !ok {
+		settings = make(map[string]interface{})
+	}
+	current := SettingsVersion(settings)
+	if expectedVersion >= 0 && current != expectedVersion {
+		return 0, ErrConflict
+	}
+
+	r.docs[doc] = settings
+	mergeMaps(settings, data)
+	settings[VersionField] = current + 1
+	return current + 1, nil
+}
+
+// mergeMaps mirrors Firestore's MergeAll: nested maps are merged field by field,
+// every other value replaces what was there before.
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		value = cloneValue(value)
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// cloneValue deep-copies the map and slice types handlers store in settings,
+// normalising them to the generic shapes Firestore hands back on reads.
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = cloneValue(item)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = item
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = cloneValue(item)
+		}
+		return out
+	case []string:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = item
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// cloneProject deep-copies a project so callers cannot mutate stored state
+func cloneProject(p models.Project) models.Project {
+	if p.PreviousSlugs != nil {
+		p.PreviousSlugs = append([]string(nil), p.PreviousSlugs...)
+	}
+	if p.Tags != nil {
+		p.Tags = append([]string(nil), p.Tags...)
+	}
+	if p.Images != nil {
+		images := make([]models.ProjectImage, len(p.Images))
+		for i, img := range p.Images {
+			if img.Renditions != nil {
+				img.Renditions = append([]models.ImageRendition(nil), img.Renditions...)
+			}
+			images[i] = img
+		}
+		p.Images = images
+	}
+	if p.ImageGroups != nil {
+		groups := make([]models.ImageGroup, len(p.ImageGroups))
+		for i, g := range p.ImageGroups {
+			if g.Images != nil {
+				g.Images = append([]string(nil), g.Images...)
+			}
+			groups[i] = g
+		}
+		p.ImageGroups = groups
+	}
+	if p.StatusHistory != nil {
+		p.StatusHistory = append([]models.StatusChange(nil), p.StatusHistory...)
+	}
+	if p.PublishedAt != nil {
+		publishedAt := *p.PublishedAt
+		p.PublishedAt = &publishedAt
+	}
+	if p.DeletedAt != nil {
+		deletedAt := *p.DeletedAt
+		p.DeletedAt = &deletedAt
+	}
+	if p.HasTestimonial != nil {
+		hasTestimonial := *p.HasTestimonial
+		p.HasTestimonial = &hasTestimonial
+	}
+	if p.Testimonial != nil {
+		testimonial := *p.Testimonial
+		p.Testimonial = &testimonial
+	}
+	return p
+}
+
+const idAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// newID generates a 20 character ID in the same shape as Firestore auto IDs
+func newID() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	for i := range b {
+		b[i] = idAlphabet[int(b[i])%len(idAlphabet)]
+	}
+	return string(b)
+}
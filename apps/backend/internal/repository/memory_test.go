@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// seedProjects stores projects in a fresh in-memory repository
+func seedProjects(t *testing.T, projects ...models.Project) *MemoryProjectRepository {
+	t.Helper()
+	repo := NewMemoryProjectRepository()
+	for i := range projects {
+		if err := repo.Create(context.Background(), &projects[i]); err != nil {
+			t.Fatalf("Create(%s): %v", projects[i].ID, err)
+		}
+	}
+	return repo
+}
+
+func TestMemoryProjectCreate(t *testing.T) {
+	repo := NewMemoryProjectRepository()
+	ctx := context.Background()
+
+	generated := models.Project{Title: "No ID"}
+	if err := repo.Create(ctx, &generated); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if generated.ID == "" || generated.Version != 1 {
+		t.Errorf("created project has ID %q and version %d, want an ID and version 1", generated.ID, generated.Version)
+	}
+
+	first := models.Project{ID: "garden", Title: "First"}
+	if err := repo.Create(ctx, &first); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	second := models.Project{ID: "garden", Title: "Second"}
+	if err := repo.Create(ctx, &second); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Create with a taken ID = %v, want ErrConflict", err)
+	}
+	stored, err := repo.Get(ctx, "garden")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stored.Title != "First" {
+		t.Errorf("title = %q, the existing project was overwritten", stored.Title)
+	}
+}
+
+func TestMemoryProjectGetAndDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := seedProjects(t, models.Project{ID: "garden", Title: "Garden"})
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{"existing project", "garden", nil},
+		{"missing project", "missing", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := repo.Get(ctx, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := repo.Delete(ctx, "garden"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.Get(ctx, "garden"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, "garden"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryProjectUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		id          string
+		version     int64
+		wantErr     error
+		wantVersion int64
+	}{
+		{"current version", "garden", 1, nil, 2},
+		{"stale version", "garden", 0, ErrConflict, 1},
+		{"newer version", "garden", 5, ErrConflict, 1},
+		{"missing project", "missing", 1, ErrNotFound, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := seedProjects(t, models.Project{ID: "garden", Title: "Before"})
+			update := models.Project{ID: tt.id, Title: "After", Version: tt.version}
+			if err := repo.Update(ctx, &update); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update = %v, want %v", err, tt.wantErr)
+			}
+			stored, err := repo.Get(ctx, "garden")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if stored.Version != tt.wantVersion {
+				t.Errorf("stored version = %d, want %d", stored.Version, tt.wantVersion)
+			}
+			if tt.wantErr == nil && (stored.Title != "After" || update.Version != tt.wantVersion) {
+				t.Errorf("stored %q at version %d, caller sees version %d", stored.Title, stored.Version, update.Version)
+			}
+			if tt.wantErr != nil && stored.Title != "Before" {
+				t.Errorf("title = %q, a rejected update was saved", stored.Title)
+			}
+		})
+	}
+}
+
+func TestMemoryProjectIsolation(t *testing.T) {
+	ctx := context.Background()
+	repo := seedProjects(t, models.Project{ID: "garden", Tags: []string{"stone"}})
+
+	p, err := repo.Get(ctx, "garden")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	p.Tags[0] = "changed"
+
+	stored, _ := repo.Get(ctx, "garden")
+	if stored.Tags[0] != "stone" {
+		t.Errorf("tags = %v, callers can change stored projects", stored.Tags)
+	}
+}
+
+func TestMemorySettingsMergeVersioned(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    int64
+		wantErr     error
+		wantVersion int64
+	}{
+		{"current version", 2, nil, 3},
+		{"any version", -1, nil, 3},
+		{"stale version", 1, ErrConflict, 2},
+		{"newer version", 3, ErrConflict, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewMemorySettingsRepository()
+			for i := 0; i < 2; i++ {
+				if _, err := repo.MergeVersioned(ctx, WebsiteDocument, map[string]interface{}{"title": "Before"}, -1); err != nil {
+					t.Fatalf("MergeVersioned: %v", err)
+				}
+			}
+
+			version, err := repo.MergeVersioned(ctx, WebsiteDocument, map[string]interface{}{"title": "After"}, tt.expected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MergeVersioned = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && version != tt.wantVersion {
+				t.Errorf("returned version %d, want %d", version, tt.wantVersion)
+			}
+			settings, err := repo.Get(ctx, WebsiteDocument)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if got := SettingsVersion(settings); got != tt.wantVersion {
+				t.Errorf("stored version %d, want %d", got, tt.wantVersion)
+			}
+			wantTitle := "After"
+			if tt.wantErr != nil {
+				wantTitle = "Before"
+			}
+			if settings["title"] != wantTitle {
+				t.Errorf("title = %v, want %s", settings["title"], wantTitle)
+			}
+		})
+	}
+}
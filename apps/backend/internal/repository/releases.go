@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const releasesCollection = "releases"
+
+// FirestoreReleaseRepository stores the website release history in the
+// "releases" collection, keyed by release ID
+type FirestoreReleaseRepository struct {
+	client *firestore.Client
+}
+
+// NewFirestoreReleaseRepository creates a Firestore backed release repository
+func NewFirestoreReleaseRepository(client *firestore.Client) *FirestoreReleaseRepository {
+	return &FirestoreReleaseRepository{client: client}
+}
+
+func (r *FirestoreReleaseRepository) Create(ctx context.Context, release *models.Release) error {
+	_, err := r.client.Collection(releasesCollection).Doc(release.ID).Create(ctx, release)
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *FirestoreReleaseRepository) Get(ctx context.Context, id string) (*models.Release, error) {
+	doc, err := r.client.Collection(releasesCollection).Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	var release models.Release
+	if err := doc.DataTo(&release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (r *FirestoreReleaseRepository) List(ctx context.Context, before string, limit int) ([]models.Release, error) {
+	query := r.client.Collection(releasesCollection).OrderBy("id", firestore.Desc)
+	if before != "" {
+		query = query.Where("id", "<", before)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]models.Release, 0, len(docs))
+	for _, doc := range docs {
+		var release models.Release
+		if err := doc.DataTo(&release); err != nil {
+			continue
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+func (r *FirestoreReleaseRepository) Save(ctx context.Context, release *models.Release) error {
+	_, err := r.client.Collection(releasesCollection).Doc(release.ID).Set(ctx, release)
+	return err
+}
+
+// MemoryReleaseRepository keeps releases in process memory
+type MemoryReleaseRepository struct {
+	mu       sync.RWMutex
+	releases map[string]models.Release
+}
+
+// NewMemoryReleaseRepository creates an empty in-memory release repository
+func NewMemoryReleaseRepository() *MemoryReleaseRepository {
+	return &MemoryReleaseRepository{releases: make(map[string]models.Release)}
+}
+
+func (r *MemoryReleaseRepository) Create(ctx context.Context, release *models.Release) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.releases[release.ID]; exists {
+		return ErrConflict
+	}
+	r.releases[release.ID] = cloneRelease(*release)
+	return nil
+}
+
+func (r *MemoryReleaseRepository) Get(ctx context.Context, id string) (*models.Release, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	release, ok := r.releases[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	clone := cloneRelease(release)
+	return &clone, nil
+}
+
+func (r *MemoryReleaseRepository) List(ctx context.Context, before string, limit int) ([]models.Release, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	releases := []models.Release{}
+	for id, release := range r.releases {
+		if before == "" || id < before {
+			releases = append(releases, cloneRelease(release))
+		}
+	}
+	sort.Slice(releases, func(i, j int) bool { return releases[i].ID > releases[j].ID })
+	if limit > 0 && len(releases) > limit {
+		releases = releases[:limit]
+	}
+	return releases, nil
+}
+
+func (r *MemoryReleaseRepository) Save(ctx context.Context, release *models.Release) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.releases[release.ID] = cloneRelease(*release)
+	return nil
+}
+
+// cloneRelease copies a release, including its file map
+func cloneRelease(release models.Release) models.Release {
+	if release.Files != nil {
+		files := make(map[string]string, len(release.Files))
+		for name, objectPath := range release.Files {
+			files[name] = objectPath
+		}
+		release.Files = files
+	}
+	if release.RestoredAt != nil {
+		at := *release.RestoredAt
+		release.RestoredAt = &at
+	}
+	return release
+}
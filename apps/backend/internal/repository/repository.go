@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// ErrNotFound is returned when a requested document does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned when a document changed since the caller read it
+var ErrConflict = errors.New("version conflict")
+
+// VersionField is the settings document field holding its version number
+const VersionField = "version"
+
+// Settings document names within the settings collection
+const (
+	WebsiteDocument  = "website"
+	ProjectsDocument = "projects"
+)
+
+// ProjectRepository abstracts how projects are persisted
+type ProjectRepository interface {
+	// List returns the projects matching opts in the requested order, plus a
+	// cursor for the next page when opts.Limit cut the results short
+	List(ctx context.Context, opts ListOptions) ([]models.Project, string, error)
+	// Get returns a single project or ErrNotFound
+	Get(ctx context.Context, id string) (*models.Project, error)
+	// FindBySlug returns the project whose current or previous slug matches, or ErrNotFound
+	FindBySlug(ctx context.Context, slug string) (*models.Project, error)
+	// Create stores a new project at version 1, assigning an ID when p.ID is
+	// empty, or returns ErrConflict if a project (trashed or not) has the ID
+	Create(ctx context.Context, p *models.Project) error
+	// Update replaces an existing project as long as its stored version still
+	// equals p.Version, then bumps p.Version. Returns ErrNotFound or ErrConflict.
+	Update(ctx context.Context, p *models.Project) error
+	// Delete removes a project or returns ErrNotFound
+	Delete(ctx context.Context, id string) error
+}
+
+// RevisionRepository stores the revision history of projects
+type RevisionRepository interface {
+	// Add stores a revision; revisions are never modified afterwards
+	Add(ctx context.Context, rev *models.Revision) error
+	// List returns up to limit revisions of a project, newest first,
+	// optionally only those numbered below before (0 means from the newest)
+	List(ctx context.Context, projectID string, before int64, limit int) ([]models.Revision, error)
+	// Get returns a single revision or ErrNotFound
+	Get(ctx context.Context, projectID string, number int64) (*models.Revision, error)
+	// DeleteAll removes every revision of a project, used when it is purged
+	DeleteAll(ctx context.Context, projectID string) error
+}
+
+// JobFilter narrows a job listing; empty fields match every job
+type JobFilter struct {
+	Type    string
+	Status  string
+	Payload map[string]string // Payload fields the job must have, e.g. {"path": ...}
+}
+
+// JobRepository persists the background job queue
+type JobRepository interface {
+	// Enqueue stores a new job, assigning an ID when job.ID is empty
+	Enqueue(ctx context.Context, job *models.Job) error
+	// ClaimDue atomically marks up to limit pending or expired running jobs
+	// whose RunAt has passed as running until now+lease, counting an attempt
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.Job, error)
+	// ExtendLease moves the lease of a running job to until, as long as it is
+	// still the claim made for attempt. Returns ErrNotFound or ErrConflict.
+	ExtendLease(ctx context.Context, id string, attempt int, until time.Time) error
+	// Get returns a single job or ErrNotFound
+	Get(ctx context.Context, id string) (*models.Job, error)
+	// Save replaces a job
+	Save(ctx context.Context, job *models.Job) error
+	// SaveClaimed replaces a job as long as it is still the claim made for
+	// attempt. Returns ErrNotFound or ErrConflict.
+	SaveClaimed(ctx context.Context, job *models.Job, attempt int) error
+	// Delete removes a job or returns ErrNotFound
+	Delete(ctx context.Context, id string) error
+	// DeleteClaimed removes a job as long as it is still the claim made for
+	// attempt. Returns ErrNotFound or ErrConflict.
+	DeleteClaimed(ctx context.Context, id string, attempt int) error
+	// DeleteUnlessRunning removes a job no worker has claimed. Returns
+	// ErrNotFound, or ErrConflict when the job is running.
+	DeleteUnlessRunning(ctx context.Context, id string) error
+	// List returns up to limit jobs matching filter, oldest first
+	List(ctx context.Context, filter JobFilter, limit int) ([]models.Job, error)
+}
+
+// MediaRepository stores the media index of deduplicated uploads
+type MediaRepository interface {
+	// Get returns the asset with the given content hash or ErrNotFound
+	Get(ctx context.Context, hash string) (*models.MediaAsset, error)
+	// Create stores a new asset, or returns ErrConflict if the hash is known
+	Create(ctx context.Context, asset *models.MediaAsset) error
+	// AddReference records that ref uses the asset, storing asset first when
+	// the hash is unknown. Adding a reference twice has no effect.
+	AddReference(ctx context.Context, asset *models.MediaAsset, ref string) error
+	// RemoveReference drops ref from the asset and returns what is left. The
+	// asset is deleted when no references remain. Returns ErrNotFound.
+	RemoveReference(ctx context.Context, hash, ref string) (*models.MediaAsset, error)
+	// Delete removes an asset or returns ErrNotFound
+	Delete(ctx context.Context, hash string) error
+	// List returns every asset, newest first
+	List(ctx context.Context) ([]models.MediaAsset, error)
+	// UpdateDetails replaces the caption, alt text and tags of an asset and
+	// returns it, or returns ErrNotFound
+	UpdateDetails(ctx context.Context, hash, caption, alt string, tags []string) (*models.MediaAsset, error)
+}
+
+// ReleaseRepository stores the history of website releases
+type ReleaseRepository interface {
+	// Create stores a new release, or returns ErrConflict if the ID is taken
+	Create(ctx context.Context, release *models.Release) error
+	// Get returns a single release or ErrNotFound
+	Get(ctx context.Context, id string) (*models.Release, error)
+	// List returns up to limit releases, newest first, optionally only those
+	// with an ID below before ("" means from the newest)
+	List(ctx context.Context, before string, limit int) ([]models.Release, error)
+	// Save replaces a release
+	Save(ctx context.Context, release *models.Release) error
+}
+
+// SettingsRepository abstracts how the settings documents are persisted
+type SettingsRepository interface {
+	// Get returns the named settings document or ErrNotFound
+	Get(ctx context.Context, doc string) (map[string]interface{}, error)
+	// Merge deep-merges data into the named document, creating it if needed.
+	// It does not change the document version; use it for bookkeeping fields.
+	Merge(ctx context.Context, doc string, data map[string]interface{}) error
+	// MergeVersioned merges data only if the document is still at expectedVersion
+	// (a missing document is version 0, a negative expectedVersion skips the
+	// check) and returns the new version, or ErrConflict.
+	MergeVersioned(ctx context.Context, doc string, data map[string]interface{}, expectedVersion int64) (int64, error)
+}
+
+// SettingsVersion reads the version number of a settings document
+func SettingsVersion(settings map[string]interface{}) int64 {
+	switch v := settings[VersionField].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+	"google.golang.org/api/iterator"
+)
+
+const revisionsCollection = "revisions"
+
+// FirestoreRevisionRepository stores revisions in a "revisions" subcollection
+// of each project document, keyed by revision number
+type FirestoreRevisionRepository struct {
+	client *firestore.Client
+}
+
+// NewFirestoreRevisionRepository creates a Firestore backed revision repository
+func NewFirestoreRevisionRepository(client *firestore.Client) *FirestoreRevisionRepository {
+	return &FirestoreRevisionRepository{client: client}
+}
+
+func (r *FirestoreRevisionRepository) revisions(projectID string) *firestore.CollectionRef {
+	return r.client.Collection(projectsCollection).Doc(projectID).Collection(revisionsCollection)
+}
+
+func (r *FirestoreRevisionRepository) Add(ctx context.Context, rev *models.Revision) error {
+	_, err := r.revisions(rev.ProjectID).Doc(strconv.FormatInt(rev.Number, 10)).Create(ctx, rev)
+	return err
+}
+
+func (r *FirestoreRevisionRepository) List(ctx context.Context, projectID string, before int64, limit int) ([]models.Revision, error) {
+	query := r.revisions(projectID).OrderBy("number", firestore.Desc)
+	if before > 0 {
+		query = query.Where("number", "<", before)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]models.Revision, 0, len(docs))
+	for _, doc := range docs {
+		var rev models.Revision
+		if err := doc.DataTo(&rev); err != nil {
+			continue
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+func (r *FirestoreRevisionRepository) Get(ctx context.Context, projectID string, number int64) (*models.Revision, error) {
+	doc, err := r.revisions(projectID).Doc(strconv.FormatInt(number, 10)).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	var rev models.Revision
+	if err := doc.DataTo(&rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func (r *FirestoreRevisionRepository) DeleteAll(ctx context.Context, projectID string) error {
+	bulk := r.client.BulkWriter(ctx)
+	iter := r.revisions(projectID).DocumentRefs(ctx)
+	for {
+		ref, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			bulk.End()
+			return err
+		}
+		if _, err := bulk.Delete(ref); err != nil {
+			bulk.End()
+			return err
+		}
+	}
+	bulk.End()
+	return nil
+}
+
+// MemoryRevisionRepository keeps revisions in process memory
+type MemoryRevisionRepository struct {
+	mu        sync.RWMutex
+	revisions map[string][]models.Revision // Per project, oldest first
+}
+
+// NewMemoryRevisionRepository creates an empty in-memory revision repository
+func NewMemoryRevisionRepository() *MemoryRevisionRepository {
+	return &MemoryRevisionRepository{revisions: make(map[string][]models.Revision)}
+}
+
+func (r *MemoryRevisionRepository) Add(ctx context.Context, rev *models.Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := cloneRevision(*rev)
+	revisions := append(r.revisions[rev.ProjectID], stored)
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Number < revisions[j].Number })
+	r.revisions[rev.ProjectID] = revisions
+	return nil
+}
+
+func (r *MemoryRevisionRepository) List(ctx context.Context, projectID string, before int64, limit int) ([]models.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.revisions[projectID]
+	revisions := []models.Revision{}
+	for i := len(stored) - 1; i >= 0; i-- {
+		if before > 0 && stored[i].Number >= before {
+			continue
+		}
+		revisions = append(revisions, cloneRevision(stored[i]))
+		if limit > 0 && len(revisions) == limit {
+			break
+		}
+	}
+	return revisions, nil
+}
+
+func (r *MemoryRevisionRepository) Get(ctx context.Context, projectID string, number int64) (*models.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rev := range r.revisions[projectID] {
+		if rev.Number == number {
+			clone := cloneRevision(rev)
+			return &clone, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryRevisionRepository) DeleteAll(ctx context.Context, projectID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.revisions, projectID)
+	return nil
+}
+
+// cloneRevision deep-copies a revision, including its snapshot
+func cloneRevision(rev models.Revision) models.Revision {
+	if rev.Changes != nil {
+		rev.Changes = append([]string(nil), rev.Changes...)
+	}
+	if rev.Snapshot != nil {
+		snapshot := cloneProject(*rev.Snapshot)
+		rev.Snapshot = &snapshot
+	}
+	return rev
+}
@@ -0,0 +1,71 @@
+package revision
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// Fields that change on every save and would only add noise to a diff
+var ignoredFields = map[string]bool{
+	"version":   true,
+	"updatedAt": true,
+}
+
+// Change is a single field that differs between two versions of a project.
+// Before or After is nil when the field was added or removed.
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Diff compares two versions of a project field by field, using the JSON
+// field names the API exposes, in alphabetical order
+func Diff(before, after models.Project) []Change {
+	beforeFields := fields(before)
+	afterFields := fields(after)
+
+	names := make(map[string]bool)
+	for name := range beforeFields {
+		names[name] = true
+	}
+	for name := range afterFields {
+		names[name] = true
+	}
+
+	changes := []Change{}
+	for name := range names {
+		if ignoredFields[name] || reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			continue
+		}
+		changes = append(changes, Change{Field: name, Before: beforeFields[name], After: afterFields[name]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// Summary lists just the names of the changed fields
+func Summary(changes []Change) []string {
+	names := make([]string, len(changes))
+	for i, change := range changes {
+		names[i] = change.Field
+	}
+	return names
+}
+
+// fields flattens a project into its JSON representation, so fields are
+// named and shaped the way API clients see them
+func fields(p models.Project) map[string]interface{} {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package revision
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	base := models.Project{ID: "patio", Title: "Patio", Category: "hardscape", Tags: []string{"stone"}, Version: 1}
+
+	tests := []struct {
+		name   string
+		change func(p *models.Project)
+		want   []Change
+	}{
+		{"unchanged", func(p *models.Project) {}, []Change{}},
+		{"version and updatedAt are ignored", func(p *models.Project) {
+			p.Version, p.UpdatedAt = 2, time.Now()
+		}, []Change{}},
+		{"changed fields in order", func(p *models.Project) {
+			p.Title, p.Category = "Big patio", "paving"
+		}, []Change{
+			{Field: "category", Before: "hardscape", After: "paving"},
+			{Field: "title", Before: "Patio", After: "Big patio"},
+		}},
+		{"added field", func(p *models.Project) { p.DeletedBy = "uid-1" }, []Change{{Field: "deletedBy", Before: nil, After: "uid-1"}}},
+		{"removed field", func(p *models.Project) { p.Tags = nil }, []Change{
+			{Field: "tags", Before: []interface{}{"stone"}, After: nil},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := base
+			tt.change(&after)
+			if got := Diff(base, after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	changes := []Change{{Field: "category"}, {Field: "title"}}
+	if got := Summary(changes); !reflect.DeepEqual(got, []string{"category", "title"}) {
+		t.Errorf("Summary = %v", got)
+	}
+	if got := Summary(nil); len(got) != 0 {
+		t.Errorf("Summary(nil) = %v, want no fields", got)
+	}
+}
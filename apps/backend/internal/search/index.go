@@ -0,0 +1,260 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// Field weights: a match in the title counts three times a description match
+const (
+	weightTitle       = 3.0
+	weightTags        = 2.0
+	weightLocation    = 1.5
+	weightCategory    = 1.5
+	weightDescription = 1.0
+	weightCaption     = 0.8
+	weightTestimonial = 0.6
+)
+
+// Match weights for expanded query terms
+const (
+	weightExact  = 1.0
+	weightPrefix = 0.6
+	weightTypo1  = 0.5
+	weightTypo2  = 0.3
+)
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Options narrows down a search
+type Options struct {
+	Status string // Only return projects with this status when set, legacy values included
+	Limit  int    // Maximum number of results, 0 means no limit
+}
+
+// Result is a single search hit
+type Result struct {
+	Project models.Project
+	Score   float64
+}
+
+type document struct {
+	project models.Project
+	status  string             // Normalized project status, legacy values mapped onto the workflow
+	terms   map[string]float64 // stem -> field-weighted term frequency
+	length  float64
+}
+
+// Index is an in-process inverted index over projects.
+// It is safe for concurrent use.
+type Index struct {
+	mu          sync.RWMutex
+	docs        map[string]*document
+	postings    map[string]map[string]float64 // stem -> project ID -> weighted tf
+	totalLength float64
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// Rebuild replaces the whole index with the given projects
+func (idx *Index) Rebuild(projects []models.Project) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = make(map[string]*document, len(projects))
+	idx.postings = make(map[string]map[string]float64)
+	idx.totalLength = 0
+	for _, p := range projects {
+		idx.put(p)
+	}
+}
+
+// Put adds a project to the index, replacing any previous version
+func (idx *Index) Put(p models.Project) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(p.ID)
+	idx.put(p)
+}
+
+// Remove drops a project from the index
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+func (idx *Index) put(p models.Project) {
+	doc := &document{project: p, status: models.NormalizeStatus(p.Status), terms: make(map[string]float64)}
+	addField := func(text string, weight float64) {
+		for _, token := range tokenize(text) {
+			for _, stem := range stems(token) {
+				doc.terms[stem] += weight
+			}
+			doc.length += weight
+		}
+	}
+
+	addField(p.Title, weightTitle)
+	addField(strings.Join(p.Tags, " "), weightTags)
+	addField(p.Location, weightLocation)
+	addField(p.Category, weightCategory)
+	addField(p.Description, weightDescription)
+	for _, img := range p.Images {
+		addField(img.Caption, weightCaption)
+		addField(img.Alt, weightCaption)
+	}
+	if p.Testimonial != nil {
+		addField(p.Testimonial.Text, weightTestimonial)
+	}
+
+	idx.docs[p.ID] = doc
+	idx.totalLength += doc.length
+	for term, tf := range doc.terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]float64)
+		}
+		idx.postings[term][p.ID] = tf
+	}
+}
+
+func (idx *Index) remove(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= doc.length
+	delete(idx.docs, id)
+}
+
+// Search ranks projects against a free text query using BM25 over
+// field-weighted terms. Query words are stemmed for English and Spanish,
+// expanded to indexed terms within a small edit distance (typos) or sharing
+// a prefix (search as you type), and projects matching more of the query
+// words rank higher.
+func (idx *Index) Search(query string, opts Options) []Result {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	tokens := tokenize(query)
+	if len(tokens) == 0 || len(idx.docs) == 0 {
+		return nil
+	}
+
+	status := ""
+	if opts.Status != "" {
+		status = models.NormalizeStatus(opts.Status)
+	}
+	n := float64(len(idx.docs))
+	avgLength := idx.totalLength / n
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+
+	for _, token := range tokens {
+		// Best score this query word earns per project, across its expansions
+		best := make(map[string]float64)
+		for term, matchWeight := range idx.expand(token) {
+			postings := idx.postings[term]
+			df := float64(len(postings))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+			for id, tf := range postings {
+				doc := idx.docs[id]
+				if status != "" && doc.status != status {
+					continue
+				}
+				norm := tf * (k1 + 1) / (tf + k1*(1-b+b*doc.length/avgLength))
+				if score := idf * norm * matchWeight; score > best[id] {
+					best[id] = score
+				}
+			}
+		}
+		for id, score := range best {
+			scores[id] += score
+			matched[id]++
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		coverage := float64(matched[id]) / float64(len(tokens))
+		results = append(results, Result{Project: idx.docs[id].project, Score: score * coverage})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Project.CreatedAt.After(results[j].Project.CreatedAt)
+	})
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
+
+// expand maps a query word to the indexed terms it should match, with the
+// weight each match carries. Exact stems win over prefixes and typos.
+func (idx *Index) expand(token string) map[string]float64 {
+	expansions := make(map[string]float64)
+	consider := func(term string, weight float64) {
+		if weight > expansions[term] {
+			expansions[term] = weight
+		}
+	}
+
+	variants := stems(token)
+	exact := false
+	for _, v := range variants {
+		if _, ok := idx.postings[v]; ok {
+			consider(v, weightExact)
+			exact = true
+		}
+	}
+
+	for term := range idx.postings {
+		for _, v := range append(variants, token) {
+			if len(v) >= 3 && len(term) > len(v) && strings.HasPrefix(term, v) {
+				consider(term, weightPrefix)
+			}
+			// Only fall back to fuzzy matching when the word is not a known term
+			if exact {
+				continue
+			}
+			allowed := maxEdits(len(v))
+			if allowed == 0 {
+				continue
+			}
+			switch d := editDistance(v, term, allowed); {
+			case d == 1:
+				consider(term, weightTypo1)
+			case d == 2 && allowed >= 2:
+				consider(term, weightTypo2)
+			}
+		}
+	}
+	return expansions
+}
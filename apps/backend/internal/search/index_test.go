@@ -0,0 +1,120 @@
+package search
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+func testIndex() *Index {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	idx := NewIndex()
+	idx.Rebuild([]models.Project{
+		{ID: "roses", Title: "Rose garden", Description: "Climbing roses along a brick wall", Location: "Leeds", Category: "planting", Status: models.StatusActive, CreatedAt: created},
+		{ID: "patio", Title: "Sandstone patio", Description: "A patio with raised beds and herb planting", Tags: []string{"paving"}, Location: "York", Status: models.StatusActive, CreatedAt: created.Add(time.Hour)},
+		{ID: "jardin", Title: "Jardín mediterráneo", Description: "Olivos y lavanda en macetas de terracota", Location: "Mallorca", Status: models.StatusActive, CreatedAt: created.Add(2 * time.Hour)},
+		{ID: "pond", Title: "Wildlife pond", Description: "Gardening for frogs", Images: []models.ProjectImage{{Caption: "Marsh marigolds at the edge"}}, Status: models.StatusDraft, CreatedAt: created.Add(3 * time.Hour)},
+		{ID: "hedge", Title: "Beech hedge", Testimonial: &models.Testimonial{Text: "Our privacy is back"}, Status: models.StatusActive, CreatedAt: created.Add(4 * time.Hour)},
+	})
+	return idx
+}
+
+func resultIDs(results []Result) []string {
+	ids := []string{}
+	for _, r := range results {
+		ids = append(ids, r.Project.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	idx := testIndex()
+
+	tests := []struct {
+		name  string
+		query string
+		opts  Options
+		want  []string
+	}{
+		{"single word", "patio", Options{}, []string{"patio"}},
+		{"plural and case", "ROSE", Options{}, []string{"roses"}},
+		{"verb forms meet", "gardens", Options{}, []string{"roses", "pond"}},
+		{"title outranks description", "planting", Options{}, []string{"roses", "patio"}},
+		{"accents are ignored", "jardin mediterraneo", Options{}, []string{"jardin"}},
+		{"spanish plural", "olivo", Options{}, []string{"jardin"}},
+		{"one typo", "hegde", Options{}, []string{"hedge"}},
+		{"two typos in a long word", "sandstoen", Options{}, []string{"patio"}},
+		{"prefix while typing", "sands", Options{}, []string{"patio"}},
+		{"tags", "paving", Options{}, []string{"patio"}},
+		{"image captions", "marigold", Options{}, []string{"pond"}},
+		{"testimonials", "privacy", Options{}, []string{"hedge"}},
+		{"more query words matched ranks first", "brick patio", Options{}, []string{"patio", "roses"}},
+		{"status filter", "garden", Options{Status: models.StatusActive}, []string{"roses"}},
+		{"limit", "garden", Options{Limit: 1}, []string{"roses"}},
+		{"stopwords only", "the and of", Options{}, []string{}},
+		{"no match", "swimming pool", Options{}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultIDs(idx.Search(tt.query, tt.opts)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchScoresDescend(t *testing.T) {
+	results := testIndex().Search("garden planting", Options{})
+	if len(results) < 2 {
+		t.Fatalf("got %d results, want several", len(results))
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("result %d scores %f, above the previous %f", i, results[i].Score, results[i-1].Score)
+		}
+	}
+}
+
+func TestIndexPutAndRemove(t *testing.T) {
+	idx := testIndex()
+
+	idx.Put(models.Project{ID: "patio", Title: "Limestone terrace", Status: models.StatusActive})
+	if got := resultIDs(idx.Search("sandstone", Options{})); len(got) != 0 {
+		t.Errorf("old text still matches %v after Put", got)
+	}
+	if got := resultIDs(idx.Search("limestone", Options{})); !reflect.DeepEqual(got, []string{"patio"}) {
+		t.Errorf("new text matches %v, want [patio]", got)
+	}
+
+	idx.Remove("patio")
+	idx.Remove("missing")
+	if got := resultIDs(idx.Search("limestone", Options{})); len(got) != 0 {
+		t.Errorf("removed project still matches: %v", got)
+	}
+}
+
+func TestSearchLegacyStatus(t *testing.T) {
+	idx := NewIndex()
+	idx.Put(models.Project{ID: "gate", Title: "Oak gate", Status: "Active"})
+	idx.Put(models.Project{ID: "fence", Title: "Oak fence", Status: "inactive"})
+	idx.Put(models.Project{ID: "arch", Title: "Oak arch"})
+
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{models.StatusActive, []string{"gate"}},
+		{"ACTIVE", []string{"gate"}},
+		{models.StatusDraft, []string{"arch", "fence"}},
+	}
+	for _, tt := range tests {
+		got := resultIDs(idx.Search("oak", Options{Status: tt.status}))
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search with status %q = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// stopwords are dropped before indexing; English and Spanish are mixed
+// freely in project descriptions so both lists always apply
+var stopwords = map[string]bool{
+	// English
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "has": true, "have": true, "in": true, "is": true,
+	"it": true, "its": true, "of": true, "on": true, "or": true, "our": true, "that": true,
+	"the": true, "this": true, "to": true, "was": true, "were": true, "with": true,
+	// Spanish
+	"al": true, "como": true, "con": true, "de": true, "del": true, "el": true, "en": true,
+	"es": true, "la": true, "las": true, "lo": true, "los": true, "mas": true, "o": true,
+	"para": true, "por": true, "se": true, "su": true, "sus": true, "un": true, "una": true,
+	"y": true,
+}
+
+// tokenize lowercases text, strips accents and splits it into words
+func tokenize(text string) []string {
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	plain, _, err := transform.String(stripAccents, strings.ToLower(text))
+	if err != nil {
+		plain = strings.ToLower(text)
+	}
+
+	words := strings.FieldsFunc(plain, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, w := range words {
+		if len(w) < 2 || stopwords[w] {
+			continue
+		}
+		tokens = append(tokens, w)
+	}
+	return tokens
+}
+
+// stems returns the distinct English and Spanish stems of a token.
+// We don't know which language a project was written in, so both are indexed.
+func stems(token string) []string {
+	en := stemEnglish(token)
+	es := stemSpanish(token)
+	if en == es {
+		return []string{en}
+	}
+	return []string{en, es}
+}
+
+// stemEnglish is a light suffix stripper in the spirit of Porter step 1:
+// consistent conflation ("gardens", "gardening" -> "garden") matters more
+// than linguistic accuracy here.
+func stemEnglish(w string) string {
+	if len(w) <= 3 {
+		return w
+	}
+
+	// Plurals
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		w = w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		w = w[:len(w)-1]
+	}
+
+	// Verb endings, undoubling "planning" -> "plann" -> "plan"
+	for _, suffix := range []string{"ing", "ed"} {
+		stem := strings.TrimSuffix(w, suffix)
+		if stem == w || len(stem) < 3 || !strings.ContainsAny(stem, "aeiouy") {
+			continue
+		}
+		w = stem
+		if n := len(w); n > 2 && w[n-1] == w[n-2] && !strings.ContainsRune("lsz", rune(w[n-1])) {
+			w = w[:n-1]
+		}
+		break
+	}
+
+	// Derivational suffixes
+	for _, rule := range [][2]string{
+		{"ational", "ate"}, {"ization", "ize"}, {"fulness", "ful"}, {"ousness", "ous"},
+		{"iveness", "ive"}, {"ness", ""}, {"ment", ""}, {"ly", ""},
+	} {
+		if stem := strings.TrimSuffix(w, rule[0]); stem != w && len(stem) >= 3 {
+			w = stem + rule[1]
+			break
+		}
+	}
+
+	// "landscape" and "landscaped" should meet at "landscap"
+	if len(w) > 4 && strings.HasSuffix(w, "e") {
+		w = w[:len(w)-1]
+	}
+	return w
+}
+
+// stemSpanish is a light stemmer for accent-stripped Spanish words: it removes
+// common derivational suffixes, plurals and the final gender vowel
+// ("jardines" -> "jardin", "plantas" -> "plant").
+func stemSpanish(w string) string {
+	if len(w) <= 4 {
+		return w
+	}
+
+	for _, suffix := range []string{
+		"amientos", "imientos", "amiento", "imiento", "aciones", "iciones", "acion", "icion",
+		"mente", "idades", "idad", "ismos", "ismo", "istas", "ista", "ables", "able",
+		"ibles", "ible", "osos", "osas", "oso", "osa",
+	} {
+		if stem := strings.TrimSuffix(w, suffix); stem != w && len(stem) >= 3 {
+			return stem
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(w, "ces"):
+		w = w[:len(w)-3] + "z"
+	case strings.HasSuffix(w, "es") && len(w) > 5:
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+
+	if len(w) > 4 && strings.ContainsRune("aoe", rune(w[len(w)-1])) {
+		w = w[:len(w)-1]
+	}
+	return w
+}
+
+// editDistance returns the optimal string alignment distance between a and b,
+// giving up early (returning max+1) once the distance must exceed max
+func editDistance(a, b string, max int) int {
+	if abs(len(a)-len(b)) > max {
+		return max + 1
+	}
+
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			// Transposition: "gardne" -> "garden"
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(b)]
+}
+
+// maxEdits is the number of typos tolerated for a term of the given length
+func maxEdits(length int) int {
+	switch {
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
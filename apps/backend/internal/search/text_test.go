@@ -0,0 +1,88 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The Rose Garden", []string{"rose", "garden"}},
+		{"Jardín, de LAVANDA!", []string{"jardin", "lavanda"}},
+		{"a 2 b 20m", []string{"20m"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		got := tokenize(tt.text)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	tests := map[string]string{
+		"gardens":     "garden",
+		"gardening":   "garden",
+		"planning":    "plan",
+		"landscaped":  "landscap",
+		"landscape":   "landscap",
+		"berries":     "berry",
+		"grasses":     "grass",
+		"cactus":      "cactus",
+		"treatment":   "treat",
+		"quickly":     "quick",
+		"tree":        "tree",
+		"sing":        "sing",
+		"stylization": "styliz",
+	}
+	for word, want := range tests {
+		if got := stemEnglish(word); got != want {
+			t.Errorf("stemEnglish(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestStemSpanish(t *testing.T) {
+	tests := map[string]string{
+		"jardines":     "jardin",
+		"plantas":      "plant",
+		"luces":        "luz",
+		"naturalmente": "natural",
+		"olivo":        "oliv",
+		"riego":        "rieg",
+		"sol":          "sol",
+	}
+	for word, want := range tests {
+		if got := stemSpanish(word); got != want {
+			t.Errorf("stemSpanish(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"garden", "garden", 2, 0},
+		{"garden", "gardne", 2, 1},
+		{"garden", "gardens", 2, 1},
+		{"garden", "harden", 2, 1},
+		{"garden", "grdn", 2, 2},
+		{"garden", "pond", 2, 3},
+		{"garden", "gardeners", 2, 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxLength keeps URLs readable; longer slugs are cut at a word boundary
+const maxLength = 80
+
+// Make turns free text such as a project title into a URL-safe slug,
+// e.g. "Jardín Tropical, Mallorca" becomes "jardin-tropical-mallorca".
+func Make(text string) string {
+	// Decompose accented characters and drop the combining marks
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	plain, _, err := transform.String(stripAccents, text)
+	if err != nil {
+		plain = text
+	}
+
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(plain) {
+		switch {
+		case r == 'ß':
+			r = 's'
+		case r == 'ø':
+			r = 'o'
+		case r == 'æ':
+			r = 'a'
+		}
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingDash = true
+	}
+
+	s := b.String()
+	if len(s) > maxLength {
+		s = s[:maxLength]
+		if i := strings.LastIndexByte(s, '-'); i > 0 {
+			s = s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	long := strings.Repeat("garden ", 20)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Rose Garden", "rose-garden"},
+		{"Jardín Tropical, Mallorca", "jardin-tropical-mallorca"},
+		{"  --Straße & Søndergård--  ", "strase-sondergard"},
+		{"Phase 2: the pond", "phase-2-the-pond"},
+		{"!!!", ""},
+		{long, strings.TrimSuffix(strings.Repeat("garden-", 11), "-")},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.text); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// Error codes reported in FieldError.Code
+const (
+	CodeRequired     = "required"
+	CodeTooLong      = "too_long"
+	CodeInvalid      = "invalid"
+	CodeUnknown      = "unknown"
+	CodeDuplicate    = "duplicate"
+	CodeNotFound     = "not_found"
+	CodeInconsistent = "inconsistent"
+)
+
+// Limits on free text fields
+const (
+	MaxTitleLength       = 200
+	MaxDescriptionLength = 10000
+)
+
+// ImageGroup types the website knows how to render
+var groupTypes = map[string]bool{"gallery": true, "slider": true}
+
+// Statuses a project may be saved with
+var statuses = map[string]bool{
+	models.StatusDraft:    true,
+	models.StatusReview:   true,
+	models.StatusActive:   true,
+	models.StatusArchived: true,
+}
+
+// FieldError describes one invalid field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors collects every problem found in a request
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *Errors) add(field, code, format string, args ...interface{}) {
+	*e = append(*e, FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// Rules holds the vocabularies configured in the settings/projects document.
+// An empty list means nothing is configured yet and any value is accepted.
+type Rules struct {
+	Categories []string
+	Tags       []string
+}
+
+// RulesFromSettings reads the categories and tags of a settings/projects document
+func RulesFromSettings(settings map[string]interface{}) Rules {
+	return Rules{
+		Categories: stringList(settings["categories"]),
+		Tags:       stringList(settings["tags"]),
+	}
+}
+
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// checkCategory reports whether the category of a save is checked: on
+// create, when the save changes it and when the save puts the project live.
+// Older projects saved without a category can still be edited otherwise.
+func checkCategory(req *models.CreateProjectRequest, previous *models.Project) bool {
+	return previous == nil || req.Category != previous.Category ||
+		(req.Status == models.StatusActive && models.NormalizeStatus(previous.Status) != models.StatusActive)
+}
+
+// keptTag reports whether tag was already on the stored project. Tags
+// retired from the vocabulary stay valid on projects that already had them.
+func keptTag(previous *models.Project, tag string) bool {
+	return previous != nil && contains(previous.Tags, tag)
+}
+
+// Publishable checks that a project about to go live through a workflow
+// transition has what the public website needs
+func Publishable(p models.Project) Errors {
+	var errs Errors
+	if p.Category == "" {
+		errs.add("category", CodeRequired, "Category is required before the project goes live")
+	}
+	return errs
+}
+
+// Project checks a create/update request against the project invariants and
+// returns every violation found, or nil when the request is valid. previous
+// is the stored project for updates and nil for creates; see checkCategory
+// and keptTag.
+func Project(req *models.CreateProjectRequest, rules Rules, previous *models.Project) Errors {
+	var errs Errors
+
+	// Text fields
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		errs.add("title", CodeRequired, "Title is required")
+	} else if len(title) > MaxTitleLength {
+		errs.add("title", CodeTooLong, "Title must be at most %d characters", MaxTitleLength)
+	}
+	if len(req.Description) > MaxDescriptionLength {
+		errs.add("description", CodeTooLong, "Description must be at most %d characters", MaxDescriptionLength)
+	}
+	if !statuses[req.Status] {
+		errs.add("status", CodeInvalid, "Unknown status %q", req.Status)
+	}
+
+	// Category and tags must come from the configured vocabularies
+	if checkCategory(req, previous) {
+		if req.Category == "" {
+			errs.add("category", CodeRequired, "Category is required")
+		} else if len(rules.Categories) > 0 && !contains(rules.Categories, req.Category) {
+			errs.add("category", CodeUnknown, "Unknown category %q", req.Category)
+		}
+	}
+	seenTags := make(map[string]bool)
+	for i, tag := range req.Tags {
+		field := fmt.Sprintf("tags[%d]", i)
+		switch {
+		case seenTags[tag]:
+			errs.add(field, CodeDuplicate, "Tag %q is listed more than once", tag)
+		case len(rules.Tags) > 0 && !contains(rules.Tags, tag) && !keptTag(previous, tag):
+			errs.add(field, CodeUnknown, "Unknown tag %q", tag)
+		}
+		seenTags[tag] = true
+	}
+
+	// Images need unique IDs and URLs, since groups reference IDs and cleanup compares URLs
+	imageIDs := make(map[string]bool)
+	imageURLs := make(map[string]bool)
+	for i, img := range req.Images {
+		field := fmt.Sprintf("images[%d]", i)
+		if img.ID == "" {
+			errs.add(field+".id", CodeRequired, "Image ID is required")
+		} else if imageIDs[img.ID] {
+			errs.add(field+".id", CodeDuplicate, "Image ID %q is used more than once", img.ID)
+		}
+		if img.URL == "" {
+			errs.add(field+".url", CodeRequired, "Image URL is required")
+		} else if imageURLs[img.URL] {
+			errs.add(field+".url", CodeDuplicate, "Image URL is used more than once")
+		}
+		imageIDs[img.ID] = true
+		imageURLs[img.URL] = true
+	}
+	if req.CoverImage != "" && !imageURLs[req.CoverImage] {
+		errs.add("coverImage", CodeNotFound, "Cover image must be one of the project images")
+	}
+
+	// Image groups are identified by name and may only reference the project's images
+	groupNames := make(map[string]bool)
+	for i, group := range req.ImageGroups {
+		field := fmt.Sprintf("imageGroups[%d]", i)
+		if group.Name == "" {
+			errs.add(field+".name", CodeRequired, "Group name is required")
+		} else if groupNames[group.Name] {
+			errs.add(field+".name", CodeDuplicate, "Group name %q is used more than once", group.Name)
+		}
+		groupNames[group.Name] = true
+
+		if group.GroupType != "" && !groupTypes[group.GroupType] {
+			errs.add(field+".type", CodeInvalid, "Unknown group type %q", group.GroupType)
+		}
+		if group.Order < 0 {
+			errs.add(field+".order", CodeInvalid, "Order must not be negative")
+		}
+
+		inGroup := make(map[string]bool)
+		for j, imageID := range group.Images {
+			imageField := fmt.Sprintf("%s.images[%d]", field, j)
+			switch {
+			case !imageIDs[imageID]:
+				errs.add(imageField, CodeNotFound, "Image %q is not part of the project", imageID)
+			case inGroup[imageID]:
+				errs.add(imageField, CodeDuplicate, "Image %q is in the group more than once", imageID)
+			}
+			inGroup[imageID] = true
+		}
+	}
+
+	// The testimonial must agree with the hasTestimonial flag
+	hasTestimonial := req.HasTestimonial != nil && *req.HasTestimonial
+	switch {
+	case hasTestimonial && req.Testimonial == nil:
+		errs.add("testimonial", CodeRequired, "Testimonial is required when hasTestimonial is true")
+	case hasTestimonial:
+		if strings.TrimSpace(req.Testimonial.Name) == "" {
+			errs.add("testimonial.name", CodeRequired, "Testimonial name is required")
+		}
+		if strings.TrimSpace(req.Testimonial.Text) == "" {
+			errs.add("testimonial.text", CodeRequired, "Testimonial text is required")
+		}
+	case req.Testimonial != nil:
+		errs.add("testimonial", CodeInconsistent, "Testimonial must be omitted when hasTestimonial is false")
+	}
+
+	return errs
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
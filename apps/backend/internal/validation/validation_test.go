@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+// validRequest returns a request that passes every check, for tests to break
+func validRequest() *models.CreateProjectRequest {
+	return &models.CreateProjectRequest{
+		ID:         "patio",
+		Title:      "Sunny patio",
+		Category:   "hardscape",
+		Tags:       []string{"stone", "patio"},
+		Status:     models.StatusDraft,
+		CoverImage: "https://example.com/a.jpg",
+		Images: []models.ProjectImage{
+			{ID: "a", URL: "https://example.com/a.jpg"},
+			{ID: "b", URL: "https://example.com/b.jpg"},
+		},
+		ImageGroups: []models.ImageGroup{{Name: "Before", GroupType: "gallery", Images: []string{"a", "b"}}},
+	}
+}
+
+// codes flattens errs into "field:code" strings
+func codes(errs Errors) []string {
+	var out []string
+	for _, fe := range errs {
+		out = append(out, fe.Field+":"+fe.Code)
+	}
+	return out
+}
+
+func TestProject(t *testing.T) {
+	yes, no := true, false
+	rules := Rules{Categories: []string{"hardscape", "planting"}, Tags: []string{"stone", "patio", "pond"}}
+
+	tests := []struct {
+		name     string
+		change   func(req *models.CreateProjectRequest)
+		rules    Rules
+		previous *models.Project
+		want     []string
+	}{
+		{"valid", func(req *models.CreateProjectRequest) {}, rules, nil, nil},
+		{"no vocabularies configured", func(req *models.CreateProjectRequest) {
+			req.Category, req.Tags = "anything", []string{"whatever"}
+		}, Rules{}, nil, nil},
+		{"blank title", func(req *models.CreateProjectRequest) { req.Title = "  " }, rules, nil, []string{"title:required"}},
+		{"long title", func(req *models.CreateProjectRequest) { req.Title = strings.Repeat("a", MaxTitleLength+1) }, rules, nil, []string{"title:too_long"}},
+		{"long description", func(req *models.CreateProjectRequest) {
+			req.Description = strings.Repeat("a", MaxDescriptionLength+1)
+		}, rules, nil, []string{"description:too_long"}},
+		{"unknown status", func(req *models.CreateProjectRequest) { req.Status = "live" }, rules, nil, []string{"status:invalid"}},
+		{"missing category", func(req *models.CreateProjectRequest) { req.Category = "" }, rules, nil, []string{"category:required"}},
+		{"unknown category", func(req *models.CreateProjectRequest) { req.Category = "lawns" }, rules, nil, []string{"category:unknown"}},
+		{"unknown and duplicate tags", func(req *models.CreateProjectRequest) {
+			req.Tags = []string{"stone", "gravel", "stone"}
+		}, rules, nil, []string{"tags[1]:unknown", "tags[2]:duplicate"}},
+		{"image without ID or URL", func(req *models.CreateProjectRequest) {
+			req.Images = append(req.Images, models.ProjectImage{})
+		}, rules, nil, []string{"images[2].id:required", "images[2].url:required"}},
+		{"duplicate images", func(req *models.CreateProjectRequest) {
+			req.Images = append(req.Images, models.ProjectImage{ID: "a", URL: "https://example.com/b.jpg"})
+		}, rules, nil, []string{"images[2].id:duplicate", "images[2].url:duplicate"}},
+		{"cover image not in the project", func(req *models.CreateProjectRequest) {
+			req.CoverImage = "https://example.com/other.jpg"
+		}, rules, nil, []string{"coverImage:not_found"}},
+		{"broken image group", func(req *models.CreateProjectRequest) {
+			req.ImageGroups = append(req.ImageGroups, models.ImageGroup{Name: "Before", GroupType: "carousel", Order: -1, Images: []string{"a", "a", "z"}})
+		}, rules, nil, []string{
+			"imageGroups[1].name:duplicate", "imageGroups[1].type:invalid", "imageGroups[1].order:invalid",
+			"imageGroups[1].images[1]:duplicate", "imageGroups[1].images[2]:not_found",
+		}},
+		{"unnamed image group", func(req *models.CreateProjectRequest) {
+			req.ImageGroups = append(req.ImageGroups, models.ImageGroup{})
+		}, rules, nil, []string{"imageGroups[1].name:required"}},
+		{"testimonial", func(req *models.CreateProjectRequest) {
+			req.HasTestimonial, req.Testimonial = &yes, &models.Testimonial{Name: "Ann", Text: "Lovely"}
+		}, rules, nil, nil},
+		{"missing testimonial", func(req *models.CreateProjectRequest) { req.HasTestimonial = &yes }, rules, nil, []string{"testimonial:required"}},
+		{"empty testimonial", func(req *models.CreateProjectRequest) {
+			req.HasTestimonial, req.Testimonial = &yes, &models.Testimonial{}
+		}, rules, nil, []string{"testimonial.name:required", "testimonial.text:required"}},
+		{"unexpected testimonial", func(req *models.CreateProjectRequest) {
+			req.HasTestimonial, req.Testimonial = &no, &models.Testimonial{Name: "Ann", Text: "Lovely"}
+		}, rules, nil, []string{"testimonial:inconsistent"}},
+		{"legacy project without a category", func(req *models.CreateProjectRequest) {
+			req.Category = ""
+		}, rules, &models.Project{Status: models.StatusDraft}, nil},
+		{"legacy project keeps a retired category", func(req *models.CreateProjectRequest) {
+			req.Category = "topiary"
+		}, rules, &models.Project{Category: "topiary", Status: models.StatusDraft}, nil},
+		{"legacy project going live", func(req *models.CreateProjectRequest) {
+			req.Category, req.Status = "", models.StatusActive
+		}, rules, &models.Project{Status: models.StatusDraft}, []string{"category:required"}},
+		{"retired tag kept on an update", func(req *models.CreateProjectRequest) {
+			req.Tags = []string{"stone", "decking"}
+		}, rules, &models.Project{Category: "hardscape", Tags: []string{"decking"}, Status: models.StatusDraft}, nil},
+		{"retired tag added on an update", func(req *models.CreateProjectRequest) {
+			req.Tags = []string{"stone", "decking"}
+		}, rules, &models.Project{Category: "hardscape", Tags: []string{"stone"}, Status: models.StatusDraft}, []string{"tags[1]:unknown"}},
+		{"legacy active project without a category stays live", func(req *models.CreateProjectRequest) {
+			req.Category, req.Status = "", models.StatusActive
+		}, rules, &models.Project{Status: "Active"}, nil},
+		{"clearing the category", func(req *models.CreateProjectRequest) {
+			req.Category = ""
+		}, rules, &models.Project{Category: "hardscape", Status: models.StatusDraft}, []string{"category:required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.change(req)
+			if got := codes(Project(req, tt.rules, tt.previous)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Project = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishable(t *testing.T) {
+	if errs := Publishable(models.Project{Category: "hardscape"}); errs != nil {
+		t.Errorf("Publishable = %v, want no errors", errs)
+	}
+	if got := codes(Publishable(models.Project{})); !reflect.DeepEqual(got, []string{"category:required"}) {
+		t.Errorf("Publishable without a category = %v", got)
+	}
+}
+
+func TestRulesFromSettings(t *testing.T) {
+	rules := RulesFromSettings(map[string]interface{}{
+		"categories": []interface{}{"hardscape", 3, "planting"},
+		"tags":       []string{"stone"},
+	})
+	want := Rules{Categories: []string{"hardscape", "planting"}, Tags: []string{"stone"}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("RulesFromSettings = %+v, want %+v", rules, want)
+	}
+	if rules := RulesFromSettings(nil); rules.Categories != nil || rules.Tags != nil {
+		t.Errorf("RulesFromSettings(nil) = %+v, want empty rules", rules)
+	}
+}
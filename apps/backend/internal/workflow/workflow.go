@@ -0,0 +1,147 @@
+package workflow
+
+import (
+	"errors"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+var (
+	// ErrUnknownAction is returned for an action that is not part of the workflow
+	ErrUnknownAction = errors.New("unknown workflow action")
+	// ErrInvalidTransition is returned when the project's status does not allow the action
+	ErrInvalidTransition = errors.New("transition not allowed from the current status")
+	// ErrForbidden is returned when the user's role may not perform the action
+	ErrForbidden = errors.New("role may not perform this transition")
+)
+
+// Transition is an action moving a project from one of From to To
+type Transition struct {
+	Action string
+	From   []string
+	To     string
+	Roles  []string
+}
+
+// Transitions is the project lifecycle:
+//
+//	draft → review → active → archived
+//
+// Editors submit drafts for review, admins approve or reject them. Admins may
+// also publish drafts directly and take active projects back to draft.
+var Transitions = []Transition{
+	{Action: "submit", From: []string{models.StatusDraft}, To: models.StatusReview, Roles: []string{models.RoleEditor, models.RoleAdmin}},
+	{Action: "approve", From: []string{models.StatusReview}, To: models.StatusActive, Roles: []string{models.RoleAdmin}},
+	{Action: "reject", From: []string{models.StatusReview}, To: models.StatusDraft, Roles: []string{models.RoleAdmin}},
+	{Action: "publish", From: []string{models.StatusDraft}, To: models.StatusActive, Roles: []string{models.RoleAdmin}},
+	{Action: "unpublish", From: []string{models.StatusActive}, To: models.StatusDraft, Roles: []string{models.RoleAdmin}},
+	{Action: "archive", From: []string{models.StatusDraft, models.StatusActive}, To: models.StatusArchived, Roles: []string{models.RoleAdmin}},
+	{Action: "reopen", From: []string{models.StatusArchived}, To: models.StatusDraft, Roles: []string{models.RoleEditor, models.RoleAdmin}},
+}
+
+// Statuses lists every valid project status
+var Statuses = []string{models.StatusDraft, models.StatusReview, models.StatusActive, models.StatusArchived}
+
+// Find returns the transition for an action
+func Find(action string) (Transition, bool) {
+	for _, t := range Transitions {
+		if t.Action == action {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
+
+// Between returns the transition that moves a project from one status to another
+func Between(from, to string) (Transition, bool) {
+	from, to = models.NormalizeStatus(from), models.NormalizeStatus(to)
+	for _, t := range Transitions {
+		if t.To == to && contains(t.From, from) {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
+
+// Available lists the actions role may perform on a project in status
+func Available(status, role string) []string {
+	status = models.NormalizeStatus(status)
+	actions := []string{}
+	for _, t := range Transitions {
+		if contains(t.From, status) && contains(t.Roles, role) {
+			actions = append(actions, t.Action)
+		}
+	}
+	return actions
+}
+
+// CanCreate reports whether role may create a project directly in status.
+// Anyone may start a draft or submit straight to review; only admins may
+// create a project that is already active.
+func CanCreate(status, role string) bool {
+	switch models.NormalizeStatus(status) {
+	case models.StatusDraft, models.StatusReview:
+		return true
+	case models.StatusActive:
+		return role == models.RoleAdmin
+	default:
+		return false
+	}
+}
+
+// Apply performs action on p on behalf of the user uid with the given role,
+// updating its status and recording the transition in its history
+func Apply(p *models.Project, action, role, uid, note string, now time.Time) error {
+	t, ok := Find(action)
+	if !ok {
+		return ErrUnknownAction
+	}
+	from := models.NormalizeStatus(p.Status)
+	if !contains(t.From, from) {
+		return ErrInvalidTransition
+	}
+	if !contains(t.Roles, role) {
+		return ErrForbidden
+	}
+
+	p.Status = t.To
+	if t.To == models.StatusActive {
+		p.PublishedAt = &now
+	}
+	p.StatusHistory = append(p.StatusHistory, models.StatusChange{
+		Action: action,
+		From:   from,
+		To:     t.To,
+		By:     uid,
+		Note:   note,
+		At:     now,
+	})
+	return nil
+}
+
+// Record notes the initial status of a newly created project in its history
+func Record(p *models.Project, uid string, now time.Time) {
+	p.Status = models.NormalizeStatus(p.Status)
+	if p.Status == models.StatusActive {
+		p.PublishedAt = &now
+	}
+	p.StatusHistory = append(p.StatusHistory, models.StatusChange{Action: "create", To: p.Status, By: uid, At: now})
+}
+
+// AffectsPublicSite reports whether a change to a project that moved from one
+// status to another alters what the public website shows. Pass an empty
+// status for a project that did not exist before or no longer exists.
+func AffectsPublicSite(from, to string) bool {
+	return (from != "" && models.NormalizeStatus(from) == models.StatusActive) ||
+		(to != "" && models.NormalizeStatus(to) == models.StatusActive)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package workflow
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/networkcaretaker/garden_app/backend/internal/models"
+)
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		status  string
+		action  string
+		role    string
+		want    string
+		wantErr error
+	}{
+		{"editor submits", models.StatusDraft, "submit", models.RoleEditor, models.StatusReview, nil},
+		{"legacy inactive counts as draft", "Inactive", "submit", models.RoleEditor, models.StatusReview, nil},
+		{"missing status counts as draft", "", "publish", models.RoleAdmin, models.StatusActive, nil},
+		{"admin approves", models.StatusReview, "approve", models.RoleAdmin, models.StatusActive, nil},
+		{"admin rejects", models.StatusReview, "reject", models.RoleAdmin, models.StatusDraft, nil},
+		{"unpublish", "ACTIVE", "unpublish", models.RoleAdmin, models.StatusDraft, nil},
+		{"archive an active project", models.StatusActive, "archive", models.RoleAdmin, models.StatusArchived, nil},
+		{"editor reopens", models.StatusArchived, "reopen", models.RoleEditor, models.StatusDraft, nil},
+		{"editor cannot approve", models.StatusReview, "approve", models.RoleEditor, models.StatusReview, ErrForbidden},
+		{"no role", models.StatusDraft, "submit", "", models.StatusDraft, ErrForbidden},
+		{"approve a draft", models.StatusDraft, "approve", models.RoleAdmin, models.StatusDraft, ErrInvalidTransition},
+		{"archive from review", models.StatusReview, "archive", models.RoleAdmin, models.StatusReview, ErrInvalidTransition},
+		{"unknown action", models.StatusDraft, "delete", models.RoleAdmin, models.StatusDraft, ErrUnknownAction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := models.Project{Status: tt.status}
+			err := Apply(&p, tt.action, tt.role, "uid-1", "note", now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if p.Status != tt.status || len(p.StatusHistory) != 0 {
+					t.Errorf("a refused transition changed the project: %+v", p)
+				}
+				return
+			}
+			if p.Status != tt.want {
+				t.Errorf("status = %q, want %q", p.Status, tt.want)
+			}
+			want := models.StatusChange{Action: tt.action, From: models.NormalizeStatus(tt.status), To: tt.want, By: "uid-1", Note: "note", At: now}
+			if len(p.StatusHistory) != 1 || p.StatusHistory[0] != want {
+				t.Errorf("history = %+v, want %+v", p.StatusHistory, want)
+			}
+			if published := p.PublishedAt != nil; published != (tt.want == models.StatusActive) {
+				t.Errorf("publishedAt = %v after moving to %s", p.PublishedAt, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	tests := []struct {
+		status string
+		role   string
+		want   []string
+	}{
+		{models.StatusDraft, models.RoleAdmin, []string{"submit", "publish", "archive"}},
+		{models.StatusDraft, models.RoleEditor, []string{"submit"}},
+		{models.StatusReview, models.RoleAdmin, []string{"approve", "reject"}},
+		{models.StatusReview, models.RoleEditor, []string{}},
+		{models.StatusActive, models.RoleAdmin, []string{"unpublish", "archive"}},
+		{models.StatusArchived, models.RoleEditor, []string{"reopen"}},
+		{"inactive", models.RoleEditor, []string{"submit"}},
+		{models.StatusDraft, "", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Available(tt.status, tt.role); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Available(%q, %q) = %v, want %v", tt.status, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestCanCreate(t *testing.T) {
+	tests := []struct {
+		status string
+		role   string
+		want   bool
+	}{
+		{"", models.RoleEditor, true},
+		{models.StatusDraft, models.RoleEditor, true},
+		{models.StatusReview, models.RoleEditor, true},
+		{models.StatusActive, models.RoleEditor, false},
+		{models.StatusActive, models.RoleAdmin, true},
+		{models.StatusArchived, models.RoleAdmin, false},
+	}
+	for _, tt := range tests {
+		if got := CanCreate(tt.status, tt.role); got != tt.want {
+			t.Errorf("CanCreate(%q, %q) = %v, want %v", tt.status, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{models.StatusDraft, models.StatusReview, "submit"},
+		{"Inactive", models.StatusActive, "publish"},
+		{models.StatusReview, models.StatusActive, "approve"},
+		{models.StatusActive, models.StatusArchived, "archive"},
+		{models.StatusReview, models.StatusArchived, ""},
+	}
+	for _, tt := range tests {
+		tr, ok := Between(tt.from, tt.to)
+		if tr.Action != tt.want || ok != (tt.want != "") {
+			t.Errorf("Between(%q, %q) = %q, %v, want %q", tt.from, tt.to, tr.Action, ok, tt.want)
+		}
+	}
+}
+
+func TestAffectsPublicSite(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"", models.StatusDraft, false},
+		{"", models.StatusActive, true},
+		{models.StatusDraft, models.StatusReview, false},
+		{models.StatusReview, models.StatusActive, true},
+		{"Active", models.StatusDraft, true},
+		{models.StatusActive, "", true},
+		{models.StatusArchived, "", false},
+	}
+	for _, tt := range tests {
+		if got := AffectsPublicSite(tt.from, tt.to); got != tt.want {
+			t.Errorf("AffectsPublicSite(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
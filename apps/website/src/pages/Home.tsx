@@ -6,6 +6,7 @@ import { Footer } from '../components/Footer';
 import { WhatsAppButton } from '../components/ui/WhatsApp';
 import { getWebsiteConfig, DEFAULT_WEBSITE_DATA } from '../services/configService';
 import type { WebsiteSettings, Project } from '@garden/shared';
+import { getPublishedFileURL } from '../services/releaseService';
 
 const PROJECTS_URL = import.meta.env.VITE_PROJECTS_URL;
 
@@ -24,7 +25,7 @@ export default function Home() {
 
     const fetchProjects = async () => {
       try {
-        const response = await fetch(PROJECTS_URL);
+        const response = await fetch(await getPublishedFileURL('projects.json', PROJECTS_URL));
         if (response.ok) {
           const data = await response.json();
           setProjects(data);
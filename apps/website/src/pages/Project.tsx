@@ -5,6 +5,7 @@ import type { Project } from '@garden/shared';
 import { BeforeAfterSlider } from '../components/ImageSlider'; // Import the BeforeAfterSlider component
 import { Header } from '../components/Header';
 import { Footer } from '../components/Footer';
+import { getPublishedFileURL } from '../services/releaseService';
 
 const PROJECTS_URL = import.meta.env.VITE_PROJECTS_URL;
 
@@ -24,7 +25,7 @@ export default function ProjectPage() {
   useEffect(() => {
     const fetchProject = async () => {
       try {
-        const response = await fetch(PROJECTS_URL);
+        const response = await fetch(await getPublishedFileURL('projects.json', PROJECTS_URL));
         if (!response.ok) {
           throw new Error('Failed to fetch project data');
         }
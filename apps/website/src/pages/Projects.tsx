@@ -3,6 +3,7 @@ import { Link } from 'react-router-dom';
 import type { Project } from '@garden/shared';
 import { Header } from '../components/Header';
 import { Footer } from '../components/Footer';
+import { getPublishedFileURL } from '../services/releaseService';
 
 const PROJECTS_URL = import.meta.env.VITE_PROJECTS_URL;
 
@@ -14,7 +15,7 @@ export default function ProjectsPage() {
   useEffect(() => {
     const fetchProjects = async () => {
       try {
-        const response = await fetch(PROJECTS_URL);
+        const response = await fetch(await getPublishedFileURL('projects.json', PROJECTS_URL));
         if (!response.ok) {
           throw new Error(`Failed to fetch projects: ${response.statusText}`);
         }
@@ -1,4 +1,5 @@
 import type { WebsiteSettings } from '@garden/shared';
+import { getPublishedFileURL } from './releaseService';
 
 const VITE_WEBSITE_CONFIG_URL = import.meta.env.VITE_WEBSITE_CONFIG_URL;
 
@@ -103,7 +104,8 @@ export const getWebsiteConfig = (): Promise<WebsiteSettings> => {
     return Promise.resolve(DEFAULT_WEBSITE_DATA);
   }
 
-  configPromise = fetch(VITE_WEBSITE_CONFIG_URL)
+  configPromise = getPublishedFileURL('websiteConfig.json', VITE_WEBSITE_CONFIG_URL)
+    .then((url) => fetch(url))
     .then(async (response) => {
       if (!response.ok) {
         throw new Error('Failed to fetch website config');
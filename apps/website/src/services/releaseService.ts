@@ -0,0 +1,35 @@
+const VITE_WEBSITE_MANIFEST_URL = import.meta.env.VITE_WEBSITE_MANIFEST_URL;
+
+interface ReleaseManifest {
+  releaseId: string;
+  publishedAt: string;
+  files: Record<string, string>; // File name to URL
+}
+
+// The manifest points at the live release, so projects and settings always
+// come from the same publish. Fetched once per page load.
+let manifestPromise: Promise<ReleaseManifest | null> | null = null;
+
+const getManifest = (): Promise<ReleaseManifest | null> => {
+  if (manifestPromise) {
+    return manifestPromise;
+  }
+  if (!VITE_WEBSITE_MANIFEST_URL) {
+    return Promise.resolve(null);
+  }
+
+  manifestPromise = fetch(VITE_WEBSITE_MANIFEST_URL, { cache: 'no-cache' })
+    .then((response) => (response.ok ? response.json() : null))
+    .catch((error) => {
+      console.error('Error loading release manifest:', error);
+      return null;
+    });
+  return manifestPromise;
+};
+
+// Returns the URL of a published file in the live release, falling back to
+// its fixed URL when there is no manifest
+export const getPublishedFileURL = async (name: string, fallbackURL: string): Promise<string> => {
+  const manifest = await getManifest();
+  return manifest?.files[name] || fallbackURL;
+};
@@ -2,4 +2,4 @@ export * from './types/project';
 export * from './types/plant';
 export * from './types/user';
 export * from './types/api';
-export * from './types/settings';
\ No newline at end of file
+export * from './types/settings';export * from './types/media';
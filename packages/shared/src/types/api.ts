@@ -10,4 +10,9 @@ export interface PaginatedResponse<T> {
   page: number;
   pageSize: number;
   hasMore: boolean;
-}
\ No newline at end of file
+}
+// Envelope returned by the backend's cursor-paginated list endpoints
+export interface ListResponse<T> {
+  items: T[];
+  nextCursor?: string;
+}
@@ -0,0 +1,37 @@
+import type { ProjectImage } from './project';
+
+// An uploaded image stored once, keyed by the SHA-256 of its bytes
+export interface MediaAsset {
+  hash: string;
+  image: ProjectImage; // Stored files, dimensions and placeholders
+  size: number; // Bytes uploaded
+  caption: string;
+  alt: string;
+  tags: string[];
+  references: string[]; // "library", "website" or "project/<projectId>/<imageId>"
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface ProjectMediaUsage {
+  projectId: string;
+  title: string;
+  slug: string;
+  status: string;
+  trashed?: boolean;
+  imageId: string;
+}
+
+// Everything referencing a media asset
+export interface MediaUsage {
+  hash: string;
+  library: boolean;
+  projects: ProjectMediaUsage[];
+  website: string[]; // e.g. "content.benefits.cards[0].image"
+}
+
+export interface MediaDetailsInput {
+  caption: string;
+  alt: string;
+  tags: string[];
+}
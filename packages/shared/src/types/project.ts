@@ -1,20 +1,47 @@
 export type ProjectCategory = string;
 
+// Workflow: draft → review → active → archived. Only active projects are public.
+export type ProjectStatus = 'draft' | 'review' | 'active' | 'archived';
+
+export interface StatusChange {
+  action: string;
+  from?: ProjectStatus;
+  to: ProjectStatus;
+  by?: string;
+  note?: string;
+  at: string;
+}
+
 export interface AIGeneratedContent {
   description: boolean;
   plantIdentifications: string[];
 }
 
+// A resized copy generated by the upload pipeline
+export interface ImageRendition {
+  width: number;
+  height: number;
+  url: string;
+  storagePath: string;
+}
+
 export interface ProjectImage {
   id: string;
   url: string;
   // New field: store the internal path for deletion
   storagePath: string; 
   thumbnail?: string;
+  thumbnailPath?: string;
+  originalPath?: string;
+  renditions?: ImageRendition[]; // Smallest first
   caption?: string;
   alt?: string;
   width?: number;
   height?: number;
+  blurHash?: string;
+  lqip?: string; // Tiny JPEG data URI shown while the image loads
+  dominantColor?: string; // "#rrggbb"
+  hash?: string; // SHA-256 of the upload, identical uploads share their stored files
 }
 export interface ImageGroup {
   name: string; // This should be unique (no dupliactes). The name will be used as an ID
@@ -34,6 +61,8 @@ export interface Testimonial {
 export interface Project {
   id: string;
   title: string;
+  slug?: string; // Server-generated from the title, unique and editable
+  previousSlugs?: string[]; // Old slugs that redirect to the current one
   description: string;
   location: string;
   completedDate: string; // NOT USED can remove
@@ -50,7 +79,10 @@ export interface Project {
   published: boolean; // NOT USED can remove
   createdAt: string;
   updatedAt: string;
-  status: 'active' | 'inactive';
+  version?: number; // Incremented on every save, echoed back in If-Match
+  status: ProjectStatus;
+  statusHistory?: StatusChange[];
+  publishedAt?: string;
 }
 
 export interface ProjectCreateInput {
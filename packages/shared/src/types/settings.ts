@@ -1,4 +1,5 @@
 import type { Timestamp } from 'firebase/firestore';
+import type { ImageRendition } from './project';
 
 export type buttonVariants = 'solid' | 'outline' | 'projects' | 'none';
 
@@ -20,10 +21,18 @@ export interface WebsiteImage {
   id: string;
   url: string;
   storagePath: string; // store the internal path for deletion
+  thumbnail?: string;
+  thumbnailPath?: string;
+  originalPath?: string;
+  renditions?: ImageRendition[]; // Smallest first, published with a srcset
   caption?: string;
   alt?: string;
   width?: number;
   height?: number;
+  blurHash?: string;
+  lqip?: string; // Tiny JPEG data URI shown while the image loads
+  dominantColor?: string; // "#rrggbb"
+  hash?: string; // SHA-256 of the upload, identical uploads share their stored files
 }
 
 export interface ContentCard {
@@ -111,6 +120,12 @@ export interface WebsiteContent {
   footer: FootorContent;
 }
 
+// How robots.txt is written on publish
+export interface RobotsSettings {
+  noIndex: boolean; // Keep search engines off the whole website
+  disallow: string[]; // Paths crawlers should skip, e.g. "/projects"
+}
+
 export interface WebsiteSettings {
   title: string;
   websiteURL: string;
@@ -121,8 +136,10 @@ export interface WebsiteSettings {
   content: WebsiteContent;
   social: SocialLinks;
   seo: string[];
+  robots?: RobotsSettings;
   updatedAt: Timestamp;
   publishedAt?: Timestamp;
+  liveRelease?: string; // ID of the release the website shows
   projectUpdatedAt?: Timestamp;
 }
 
@@ -130,4 +147,62 @@ export interface ProjectSettings {
   categories: string[];
   tags: string[];
   updatedAt: Timestamp;
-}
\ No newline at end of file
+}
+// One publish of the public website, kept so it can go live again
+export interface WebsiteRelease {
+  id: string;
+  files: Record<string, string>; // File name to storage path
+  projects: number;
+  publishedBy?: string;
+  publishedAt: string;
+  restoredBy?: string;
+  restoredAt?: string;
+  trigger: 'manual' | 'scheduled' | 'auto';
+  error?: string; // Set on failed publishes, which have no files
+  live: boolean;
+}
+
+export interface PublishProjectRef {
+  id: string;
+  title: string;
+  slug?: string;
+}
+
+// What a publish would change, returned by publish?dryRun=true
+export interface PublishDiff {
+  liveRelease?: string;
+  unchanged: boolean;
+  projects: {
+    added: PublishProjectRef[];
+    removed: PublishProjectRef[];
+    changed: (PublishProjectRef & { fields: string[] })[];
+  };
+  sections: string[]; // e.g. "title" or "content.hero"
+  images: {
+    added: string[];
+    removed: string[];
+  };
+}
+
+// Whether the live website lags behind, from GET /admin/settings/website/status
+export interface PublishStatus {
+  stale: boolean;
+  changes: number; // Projects plus settings sections to publish
+  liveRelease?: string;
+  publishedAt?: string;
+  projects: (PublishProjectRef & {
+    change: 'added' | 'removed' | 'changed';
+    fields?: string[];
+    updatedAt?: string;
+    updatedBy: string[];
+  })[];
+  sections: {
+    section: string;
+    updatedAt?: string;
+    updatedBy?: string;
+  }[];
+  images: {
+    added: number;
+    removed: number;
+  };
+}